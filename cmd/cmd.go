@@ -4,8 +4,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/thiagokokada/gitk-go/internal/buildinfo"
+	"github.com/thiagokokada/gitk-go/internal/config"
 	"github.com/thiagokokada/gitk-go/internal/git"
 	"github.com/thiagokokada/gitk-go/internal/gui"
 )
@@ -15,7 +18,16 @@ func Run() error {
 }
 
 func run(args []string) error {
+	if len(args) > 0 && args[0] == "export" {
+		return runExport(args[1:], os.Stdout)
+	}
 	fs := flag.NewFlagSet("gitk-go", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gitk-go [options] [revisions] [-- paths]")
+		fmt.Fprintln(fs.Output(), "       gitk-go export [options] [revisions] [-- paths]")
+		fs.PrintDefaults()
+	}
+	repoDir := fs.String("C", "", "path to the repository (defaults to the current directory)")
 	limit := fs.Uint(
 		"limit",
 		uint(git.DefaultBatch),
@@ -27,12 +39,14 @@ func run(args []string) error {
 		"max number of graph columns to render (lower uses less CPU/memory)",
 	)
 	textGraph := fs.Bool("text-graph", false, "render commit graph as text (disables canvas graph)")
+	backendName := fs.String("backend", git.BackendCLI.String(), "repository backend: cli (git executable) or native (pure Go, read-only)")
 	mode := fs.String("mode", gui.ThemeAuto.String(), "color mode: auto, light, or dark")
 	noWatch := fs.Bool("nowatch", false, "disable automatic reload when repository changes")
 	noSyntax := fs.Bool("nosyntax", false, "disable syntax highlighting in the diff viewer")
 	verbose := fs.Bool("verbose", false, "enable verbose logging")
 	showVersion := fs.Bool("version", false, "print version information and exit")
-	if err := fs.Parse(args); err != nil {
+	flagArgs, revisions, paths := splitArgs(fs, args)
+	if err := fs.Parse(flagArgs); err != nil {
 		if err == flag.ErrHelp {
 			return nil
 		}
@@ -50,6 +64,17 @@ func run(args []string) error {
 		}
 		return nil
 	}
+	repoPath := *repoDir
+	if repoPath == "" {
+		repoPath, revisions = legacyRepoPath(revisions)
+	}
+	if err := applyConfig(fs, repoPath); err != nil {
+		return err
+	}
+	backend, err := git.BackendKindFromString(*backendName)
+	if err != nil {
+		return err
+	}
 	limitU := *limit
 	if limitU == 0 {
 		limitU = git.DefaultBatch
@@ -58,13 +83,11 @@ func run(args []string) error {
 	if graphColsU == 0 {
 		graphColsU = git.DefaultGraphMaxColumns
 	}
-	repoPath := "."
-	remaining := fs.Args()
-	if len(remaining) > 0 {
-		repoPath = remaining[len(remaining)-1]
-	}
 	return gui.Run(gui.RunConfig{
 		RepoPath:        repoPath,
+		Revisions:       revisions,
+		Paths:           paths,
+		Backend:         backend,
 		Batch:           limitU,
 		GraphMaxColumns: graphColsU,
 		GraphCanvas:     !*textGraph,
@@ -74,3 +97,117 @@ func run(args []string) error {
 		Verbose:         *verbose,
 	})
 }
+
+// applyConfig sets the flags not given on the command line from the settings
+// file, so flags take precedence over the repository overrides, which take
+// precedence over the user defaults.
+func applyConfig(fs *flag.FlagSet, repoPath string) error {
+	path, err := config.Path()
+	if err != nil {
+		// Without a config directory (e.g. $HOME unset) only flags apply.
+		return nil
+	}
+	file, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	for name, value := range file.Resolve(repoPath).FlagValues() {
+		if explicit[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+	}
+	return nil
+}
+
+// splitArgs separates gitk-go's own flags from gitk-style revision arguments
+// and pathspecs. Unknown flags (e.g. "--all") are treated as revision
+// arguments, and everything after "--" is a pathspec.
+func splitArgs(fs *flag.FlagSet, args []string) (flagArgs, revisions, paths []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			paths = append(paths, args[i+1:]...)
+			break
+		}
+		name, hasValue := flagName(arg)
+		if name == "" {
+			revisions = append(revisions, arg)
+			continue
+		}
+		if name == "h" || name == "help" {
+			flagArgs = append(flagArgs, arg)
+			continue
+		}
+		f := fs.Lookup(name)
+		if f == nil {
+			revisions = append(revisions, arg)
+			continue
+		}
+		flagArgs = append(flagArgs, arg)
+		if hasValue || isBoolFlag(f) {
+			continue
+		}
+		if i+1 < len(args) {
+			i++
+			flagArgs = append(flagArgs, args[i])
+		}
+	}
+	return flagArgs, revisions, paths
+}
+
+func flagName(arg string) (name string, hasValue bool) {
+	if len(arg) < 2 || arg[0] != '-' {
+		return "", false
+	}
+	name = strings.TrimPrefix(arg[1:], "-")
+	if name == "" || name[0] == '-' || name[0] == '=' {
+		return "", false
+	}
+	if idx := strings.IndexByte(name, '='); idx >= 0 {
+		return name[:idx], true
+	}
+	return name, false
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// legacyRepoPath keeps supporting "gitk-go /path/to/repo": the last revision
+// argument is used as the repository path when it is an existing directory
+// and either the only argument or the root of a repository (a work tree or a
+// git directory). Other directories, as in "gitk-go HEAD internal", are left
+// for git to read as paths.
+func legacyRepoPath(revisions []string) (string, []string) {
+	if len(revisions) == 0 {
+		return ".", revisions
+	}
+	last := revisions[len(revisions)-1]
+	if info, err := os.Stat(last); err != nil || !info.IsDir() {
+		return ".", revisions
+	}
+	if len(revisions) > 1 && !isRepoRoot(last) {
+		return ".", revisions
+	}
+	return last, revisions[:len(revisions)-1]
+}
+
+// isRepoRoot reports whether dir is the top of a work tree, which has a .git
+// directory or file, or a git directory such as a bare repository.
+func isRepoRoot(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
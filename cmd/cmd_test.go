@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/config"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		flags     []string
+		revisions []string
+		paths     []string
+	}{
+		{name: "empty"},
+		{
+			name:      "flags and revisions",
+			args:      []string{"-limit", "10", "--all", "-nowatch", "main..feature"},
+			flags:     []string{"-limit", "10", "-nowatch"},
+			revisions: []string{"--all", "main..feature"},
+		},
+		{
+			name:      "flag with inline value",
+			args:      []string{"--mode=dark", "HEAD~3"},
+			flags:     []string{"--mode=dark"},
+			revisions: []string{"HEAD~3"},
+		},
+		{
+			name:      "paths after separator",
+			args:      []string{"main", "--", "src/", "-limit"},
+			revisions: []string{"main"},
+			paths:     []string{"src/", "-limit"},
+		},
+		{
+			name:  "help",
+			args:  []string{"-h"},
+			flags: []string{"-h"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.Uint("limit", 0, "")
+			fs.String("mode", "", "")
+			fs.Bool("nowatch", false, "")
+			flags, revisions, paths := splitArgs(fs, tt.args)
+			if !slices.Equal(flags, tt.flags) {
+				t.Fatalf("flags = %q, want %q", flags, tt.flags)
+			}
+			if !slices.Equal(revisions, tt.revisions) {
+				t.Fatalf("revisions = %q, want %q", revisions, tt.revisions)
+			}
+			if !slices.Equal(paths, tt.paths) {
+				t.Fatalf("paths = %q, want %q", paths, tt.paths)
+			}
+		})
+	}
+}
+
+func TestLegacyRepoPath(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	subdir := filepath.Join(repo, "sub")
+	if err := os.Mkdir(subdir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	bare := t.TempDir()
+	for _, name := range []string{"objects", "refs"} {
+		if err := os.Mkdir(filepath.Join(bare, name), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(bare, "HEAD"), []byte("ref: refs/heads/main\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	file := filepath.Join(repo, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		path      string
+		revisions []string
+	}{
+		{name: "only argument", args: []string{subdir}, path: subdir},
+		{name: "work tree root", args: []string{"--all", repo}, path: repo, revisions: []string{"--all"}},
+		{name: "bare repository", args: []string{"main", bare}, path: bare, revisions: []string{"main"}},
+		{name: "path limit", args: []string{"HEAD", subdir}, path: ".", revisions: []string{"HEAD", subdir}},
+		{name: "revision", args: []string{"main"}, path: ".", revisions: []string{"main"}},
+		{name: "file", args: []string{file}, path: ".", revisions: []string{file}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, revisions := legacyRepoPath(tt.args)
+			if path != tt.path || !slices.Equal(revisions, tt.revisions) {
+				t.Fatalf("legacyRepoPath(%q) = %q, %q, want %q, %q", tt.args, path, revisions, tt.path, tt.revisions)
+			}
+		})
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	path, err := config.Path()
+	if err != nil {
+		t.Fatalf("config.Path: %v", err)
+	}
+	userLimit, repoLimit, cols, dark := uint(10), uint(20), uint(30), "dark"
+	file := &config.File{Settings: config.Settings{Limit: &userLimit, GraphCols: &cols, Mode: &dark}}
+	file.SetRepository(repo, config.Settings{Limit: &repoLimit, GraphCols: &repoLimit})
+	if err := file.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	limit := fs.Uint("limit", 1, "")
+	graphCols := fs.Uint("graph-cols", 2, "")
+	mode := fs.String("mode", "auto", "")
+	noWatch := fs.Bool("nowatch", false, "")
+	if err := fs.Parse([]string{"-graph-cols", "40"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if err := applyConfig(fs, repo); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if *limit != 20 || *graphCols != 40 || *mode != "dark" || *noWatch {
+		t.Fatalf("limit=%d graph-cols=%d mode=%q nowatch=%v", *limit, *graphCols, *mode, *noWatch)
+	}
+}
+
+func TestRunExportRejectsUnknownFormat(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var out bytes.Buffer
+	if err := runExport([]string{"-format", "xml", "--all"}, &out); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no output, got %q", out.String())
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+// runExport implements "gitk-go export", which prints the commit list and
+// graph without starting the GUI.
+func runExport(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gitk-go export", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gitk-go export [options] [revisions] [-- paths]")
+		fs.PrintDefaults()
+	}
+	repoDir := fs.String("C", ".", "path to the repository")
+	format := fs.String("format", git.ExportText.String(), "output format: text (like git log --graph --oneline) or json (one object per line)")
+	maxCount := fs.Uint("n", 0, "number of commits to export (0 exports the whole history)")
+	graphCols := fs.Uint(
+		"graph-cols",
+		uint(git.DefaultGraphMaxColumns),
+		"max number of graph columns to render (lower uses less CPU/memory)",
+	)
+	backendName := fs.String("backend", git.BackendCLI.String(), "repository backend: cli (git executable) or native (pure Go, read-only)")
+	flagArgs, revisions, paths := splitArgs(fs, args)
+	if err := fs.Parse(flagArgs); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := applyConfig(fs, *repoDir); err != nil {
+		return err
+	}
+	exportFormat, err := git.ExportFormatFromString(*format)
+	if err != nil {
+		return err
+	}
+	backend, err := git.BackendKindFromString(*backendName)
+	if err != nil {
+		return err
+	}
+	svc, err := git.OpenBackend(*repoDir, backend)
+	if err != nil {
+		return err
+	}
+	svc.SetGraphMaxColumns(int(*graphCols))
+	spec := git.LogSpec{Revisions: revisions, Paths: paths}
+	return svc.Export(stdout, spec, exportFormat, *maxCount)
+}
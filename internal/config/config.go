@@ -0,0 +1,234 @@
+// Package config loads and saves gitk-go's settings file.
+//
+// The file lives at $XDG_CONFIG_HOME/gitk-go/config.json (see
+// os.UserConfigDir for other platforms). Its top-level keys are defaults for
+// every repository and "repositories" maps repository roots to overrides:
+//
+//	{
+//	  "limit": 5000,
+//	  "mode": "dark",
+//	  "repositories": {
+//	    "/home/alice/src/linux": {"graph-cols": 50, "nowatch": true}
+//	  }
+//	}
+//
+// Keys are named after the command-line flags, which take precedence.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Settings holds optional values for the command-line options. Nil fields
+// are unset and fall back to the next layer.
+type Settings struct {
+	Limit     *uint   `json:"limit,omitempty"`
+	GraphCols *uint   `json:"graph-cols,omitempty"`
+	TextGraph *bool   `json:"text-graph,omitempty"`
+	Backend   *string `json:"backend,omitempty"`
+	Mode      *string `json:"mode,omitempty"`
+	NoWatch   *bool   `json:"nowatch,omitempty"`
+	NoSyntax  *bool   `json:"nosyntax,omitempty"`
+	Verbose   *bool   `json:"verbose,omitempty"`
+}
+
+// File is the contents of the settings file.
+type File struct {
+	Settings
+	Repositories map[string]Settings `json:"repositories,omitempty"`
+}
+
+// Path returns the location of the settings file.
+func Path() (string, error) {
+	return configFile("config.json")
+}
+
+func configFile(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gitk-go", name), nil
+}
+
+// Load reads the settings file at path. A missing file is not an error.
+func Load(path string) (*File, error) {
+	f := &File{}
+	if err := readJSON(path, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// readJSON decodes path into v, leaving v untouched when path is missing.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// Save writes f to path, creating its directory if needed.
+func (f *File) Save(path string) error {
+	return writeJSON(path, f)
+}
+
+// writeJSON atomically replaces path with the indented JSON encoding of v.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Resolve returns the user settings overridden by the ones of the repository
+// containing repoPath.
+func (f *File) Resolve(repoPath string) Settings {
+	return f.Settings.Merge(f.Repositories[RepoKey(repoPath)])
+}
+
+// SetRepository replaces the overrides of the repository containing
+// repoPath, dropping the entry when s is empty.
+func (f *File) SetRepository(repoPath string, s Settings) {
+	key := RepoKey(repoPath)
+	if s == (Settings{}) {
+		delete(f.Repositories, key)
+		return
+	}
+	if f.Repositories == nil {
+		f.Repositories = map[string]Settings{}
+	}
+	f.Repositories[key] = s
+}
+
+// Merge returns s with the fields set in o replaced.
+func (s Settings) Merge(o Settings) Settings {
+	if o.Limit != nil {
+		s.Limit = o.Limit
+	}
+	if o.GraphCols != nil {
+		s.GraphCols = o.GraphCols
+	}
+	if o.TextGraph != nil {
+		s.TextGraph = o.TextGraph
+	}
+	if o.Backend != nil {
+		s.Backend = o.Backend
+	}
+	if o.Mode != nil {
+		s.Mode = o.Mode
+	}
+	if o.NoWatch != nil {
+		s.NoWatch = o.NoWatch
+	}
+	if o.NoSyntax != nil {
+		s.NoSyntax = o.NoSyntax
+	}
+	if o.Verbose != nil {
+		s.Verbose = o.Verbose
+	}
+	return s
+}
+
+// Diff returns the fields of s whose values differ from the ones in base.
+func (s Settings) Diff(base Settings) Settings {
+	return Settings{
+		Limit:     diffValue(s.Limit, base.Limit),
+		GraphCols: diffValue(s.GraphCols, base.GraphCols),
+		TextGraph: diffValue(s.TextGraph, base.TextGraph),
+		Backend:   diffValue(s.Backend, base.Backend),
+		Mode:      diffValue(s.Mode, base.Mode),
+		NoWatch:   diffValue(s.NoWatch, base.NoWatch),
+		NoSyntax:  diffValue(s.NoSyntax, base.NoSyntax),
+		Verbose:   diffValue(s.Verbose, base.Verbose),
+	}
+}
+
+func diffValue[T comparable](v, base *T) *T {
+	if v == nil || (base != nil && *v == *base) {
+		return nil
+	}
+	return v
+}
+
+// FlagValues returns the set fields keyed by flag name, formatted for
+// flag.FlagSet.Set.
+func (s Settings) FlagValues() map[string]string {
+	values := map[string]string{}
+	setUint := func(name string, v *uint) {
+		if v != nil {
+			values[name] = strconv.FormatUint(uint64(*v), 10)
+		}
+	}
+	setBool := func(name string, v *bool) {
+		if v != nil {
+			values[name] = strconv.FormatBool(*v)
+		}
+	}
+	setString := func(name string, v *string) {
+		if v != nil {
+			values[name] = *v
+		}
+	}
+	setUint("limit", s.Limit)
+	setUint("graph-cols", s.GraphCols)
+	setBool("text-graph", s.TextGraph)
+	setString("backend", s.Backend)
+	setString("mode", s.Mode)
+	setBool("nowatch", s.NoWatch)
+	setBool("nosyntax", s.NoSyntax)
+	setBool("verbose", s.Verbose)
+	return values
+}
+
+// RepoKey normalizes a path inside a repository to the key used in
+// File.Repositories: the absolute path of the enclosing worktree root.
+func RepoKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	path = filepath.Clean(path)
+	for dir := path; ; {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return path
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	f, err := Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if f.Settings != (Settings{}) || len(f.Repositories) != 0 {
+		t.Fatalf("expected empty settings, got %+v", f)
+	}
+}
+
+func TestSaveLoadResolve(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	sub := filepath.Join(repo, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	limit, cols, dark, noWatch := uint(5000), uint(50), "dark", true
+	f := &File{Settings: Settings{Limit: &limit, Mode: &dark}}
+	f.SetRepository(sub, Settings{GraphCols: &cols, NoWatch: &noWatch})
+
+	path := filepath.Join(t.TempDir(), "gitk-go", "config.json")
+	if err := f.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got := loaded.Resolve(repo).FlagValues()
+	want := map[string]string{"limit": "5000", "mode": "dark", "graph-cols": "50", "nowatch": "true"}
+	if !maps.Equal(got, want) {
+		t.Fatalf("Resolve(repo) = %v, want %v", got, want)
+	}
+	got = loaded.Resolve(t.TempDir()).FlagValues()
+	want = map[string]string{"limit": "5000", "mode": "dark"}
+	if !maps.Equal(got, want) {
+		t.Fatalf("Resolve(other) = %v, want %v", got, want)
+	}
+
+	loaded.SetRepository(repo, Settings{})
+	if len(loaded.Repositories) != 0 {
+		t.Fatalf("expected empty overrides to be dropped, got %v", loaded.Repositories)
+	}
+}
+
+func TestSettingsDiff(t *testing.T) {
+	a, b, light := uint(1), uint(2), "light"
+	s := Settings{Limit: &a, GraphCols: &a, Mode: &light}
+	d := s.Diff(Settings{Limit: &a, GraphCols: &b})
+	want := map[string]string{"graph-cols": "1", "mode": "light"}
+	if got := d.FlagValues(); !maps.Equal(got, want) {
+		t.Fatalf("Diff = %v, want %v", got, want)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"limit": "many"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestLayoutSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "layout.json")
+	empty, err := LoadLayout(path)
+	if err != nil {
+		t.Fatalf("LoadLayout(missing): %v", err)
+	}
+	if empty.Geometry != "" || len(empty.Columns) != 0 {
+		t.Fatalf("expected empty layout, got %+v", empty)
+	}
+	want := &Layout{
+		Geometry: "1600x1000+10+20",
+		MainSash: 300,
+		DiffSash: 1200,
+		Columns:  []ColumnLayout{{ID: "commit", Width: 500}, {ID: "graph", Width: 100}},
+	}
+	if err := want.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := LoadLayout(path)
+	if err != nil {
+		t.Fatalf("LoadLayout: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
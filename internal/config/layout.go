@@ -0,0 +1,44 @@
+package config
+
+// Layout is the window layout saved when gitk-go exits and restored on the
+// next start. Zero values mean "use the built-in layout".
+type Layout struct {
+	// Geometry is the main window geometry in Tk's "WxH+X+Y" form.
+	Geometry string `json:"geometry,omitempty"`
+	// MainSash is the height of the commit list above the diff.
+	MainSash int `json:"main-sash,omitempty"`
+	// DiffSash is the width of the diff left of the file list.
+	DiffSash int `json:"diff-sash,omitempty"`
+	// Columns lists the commit list columns in display order.
+	Columns []ColumnLayout `json:"columns,omitempty"`
+	// RefsSash is the width of the refs sidebar.
+	RefsSash int `json:"refs-sash,omitempty"`
+	// HideRefs hides the refs sidebar.
+	HideRefs bool `json:"hide-refs,omitempty"`
+}
+
+// ColumnLayout is the saved state of one commit list column.
+type ColumnLayout struct {
+	ID    string `json:"id"`
+	Width int    `json:"width"`
+}
+
+// LayoutPath returns the location of the layout file, kept apart from the
+// settings file since it is rewritten on every exit.
+func LayoutPath() (string, error) {
+	return configFile("layout.json")
+}
+
+// LoadLayout reads the layout file at path. A missing file is not an error.
+func LoadLayout(path string) (*Layout, error) {
+	l := &Layout{}
+	if err := readJSON(path, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Save writes l to path, creating its directory if needed.
+func (l *Layout) Save(path string) error {
+	return writeJSON(path, l)
+}
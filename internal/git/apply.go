@@ -0,0 +1,53 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CherryPick applies the changes of commit on top of HEAD, picking merges
+// against their parent number mainline. When it stops on conflicts, the
+// error is a *ConflictError and the repository is left mid-operation.
+func (s *Service) CherryPick(commit string, mainline int) error {
+	return s.moveHead(commit, func(commit string) error {
+		return s.backend.CherryPick(commit, mainline)
+	})
+}
+
+// Revert commits the reverse of the changes of commit, like CherryPick.
+func (s *Service) Revert(commit string, mainline int) error {
+	return s.moveHead(commit, func(commit string) error {
+		return s.backend.Revert(commit, mainline)
+	})
+}
+
+// Reset moves the current branch to commit. A hard reset discards the local
+// changes.
+func (s *Service) Reset(commit string, mode ResetMode) error {
+	return s.moveHead(commit, func(commit string) error {
+		return s.backend.Reset(commit, mode)
+	})
+}
+
+// moveHead runs an operation that moves HEAD to a new commit, dropping the
+// commit scan that no longer matches it.
+func (s *Service) moveHead(commit string, op func(commit string) error) error {
+	commit = strings.TrimSpace(commit)
+	if commit == "" {
+		return fmt.Errorf("commit not specified")
+	}
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return fmt.Errorf("repository root not set")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := op(commit); err != nil {
+		return err
+	}
+	if s.scan != nil {
+		s.scan.close()
+		s.scan = nil
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyOperations_DelegateAndClearScan(t *testing.T) {
+	var calls []string
+	backend := &fakeBackend{
+		repoPath: "repo",
+		cherryPickFunc: func(commitHash string, mainline int) error {
+			calls = append(calls, "cherry-pick "+commitHash)
+			if mainline != 1 {
+				t.Fatalf("mainline = %d, want 1", mainline)
+			}
+			return nil
+		},
+		revertFunc: func(commitHash string, mainline int) error {
+			calls = append(calls, "revert "+commitHash)
+			return nil
+		},
+		resetFunc: func(commitHash string, mode ResetMode) error {
+			calls = append(calls, "reset --"+mode.String()+" "+commitHash)
+			return nil
+		},
+	}
+	svc := NewWithBackend(backend)
+	for _, op := range []func() error{
+		func() error { return svc.CherryPick(" abc ", 1) },
+		func() error { return svc.Revert("def", 0) },
+		func() error { return svc.Reset("HEAD~1", ResetHard) },
+	} {
+		svc.scan = &scanSession{}
+		if err := op(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if svc.scan != nil {
+			t.Fatalf("expected scan session to be cleared")
+		}
+	}
+	want := []string{"cherry-pick abc", "revert def", "reset --hard HEAD~1"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("calls = %v, want %v", calls, want)
+		}
+	}
+}
+
+func TestCherryPick_ConflictKeepsScan(t *testing.T) {
+	conflict := &ConflictError{Op: "cherry-pick", Files: []string{"a.txt"}}
+	svc := NewWithBackend(&fakeBackend{
+		repoPath: "repo",
+		cherryPickFunc: func(string, int) error {
+			return conflict
+		},
+	})
+	svc.scan = &scanSession{}
+	err := svc.CherryPick("abc", 0)
+	var got *ConflictError
+	if !errors.As(err, &got) || got != conflict {
+		t.Fatalf("CherryPick error = %v, want the conflict", err)
+	}
+	if svc.scan == nil {
+		t.Fatalf("expected scan session to be kept when HEAD did not move")
+	}
+}
+
+func TestReset_RequiresCommit(t *testing.T) {
+	svc := NewWithBackend(&fakeBackend{repoPath: "repo"})
+	if err := svc.Reset("  ", ResetSoft); err == nil {
+		t.Fatalf("expected an error without a commit")
+	}
+}
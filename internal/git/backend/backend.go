@@ -1,23 +1,85 @@
 package backend
 
+import "context"
+
 // Backend abstracts access to repository data.
 //
 // The default implementation shells out to the git executable, but the interface
 // allows alternative implementations (e.g. pure-Go) without changing callers.
 type Backend interface {
 	RepoPath() string
-	StartLogStream(fromHash string) (LogStream, error)
+	StartLogStream(spec LogSpec) (LogStream, error)
+	// SearchCommits returns the hashes of the commits selected by spec that
+	// match query, in log order. It gives up with ctx's error once ctx is done.
+	SearchCommits(ctx context.Context, spec LogSpec, query SearchQuery) ([]string, error)
 
 	HeadState() (hash string, headName string, ok bool, err error)
 	ListRefs() ([]Ref, error)
+	// ListStashes returns the stash entries, newest first.
+	ListStashes() ([]Stash, error)
 	SwitchBranch(branch string) error
+	// SwitchDetached checks out the commit named by rev, detaching HEAD.
+	SwitchDetached(rev string) error
+	// CreateTrackingBranch creates the local branch name tracking the
+	// remote-tracking branch remoteBranch, such as "origin/main", and
+	// switches to it.
+	CreateTrackingBranch(name string, remoteBranch string) error
+	// CreateBranch creates a local branch at commitHash, switching to it when
+	// checkout is set.
+	CreateBranch(name string, commitHash string, checkout bool) error
+	// DeleteBranch deletes a local branch. Unless force is set, git refuses
+	// to delete a branch that is not merged.
+	DeleteBranch(name string, force bool) error
+	RenameBranch(oldName string, newName string) error
+	// CreateTag tags commitHash. A non-empty message makes an annotated tag,
+	// otherwise the tag is lightweight.
+	CreateTag(name string, commitHash string, message string) error
+	DeleteTag(name string) error
+	// CherryPick applies the changes of commitHash on top of HEAD. Merge
+	// commits are picked against their parent number mainline, which is
+	// ignored for other commits. On conflicts it returns a *ConflictError.
+	CherryPick(commitHash string, mainline int) error
+	// Revert commits the reverse of the changes of commitHash, like
+	// CherryPick.
+	Revert(commitHash string, mainline int) error
+	// Reset moves the current branch to commitHash.
+	Reset(commitHash string, mode ResetMode) error
+	// ResolveRevision returns the hash of the commit named by a revision
+	// expression such as "abc1234", "main", "HEAD~40" or "v1.2^2".
+	ResolveRevision(rev string) (string, error)
+	// LogContains reports whether the walk selected by spec reaches
+	// commitHash, without listing the commits before it.
+	LogContains(spec LogSpec, commitHash string) (bool, error)
+	// CountCommits counts the commits reachable from revisions, like git
+	// rev-list --count.
+	CountCommits(revisions []string) (int, error)
 
-	CommitDiffText(commitHash string, parentHash string) (string, error)
-	WorktreeDiffText(staged bool) (string, error)
+	CommitDiffText(commitHash string, parentHash string, opts DiffOptions) (string, error)
+	// CombinedDiffText returns the dense combined diff of a merge commit
+	// against all its parents, as shown by "git show --cc".
+	CombinedDiffText(commitHash string, opts DiffOptions) (string, error)
+	WorktreeDiffText(staged bool, opts DiffOptions) (string, error)
 	LocalChangesStatus() (LocalChanges, error)
+	// ApplyPatch applies patch, a unified diff of local changes, to the index
+	// or the working tree as opts select.
+	ApplyPatch(patch string, opts ApplyOptions) error
+
+	// ListTree returns every entry of the tree of commitHash with directories
+	// before their contents, like "git ls-tree -r -t".
+	ListTree(commitHash string) ([]TreeEntry, error)
+	// ReadBlob returns the contents of the file at path in commitHash.
+	ReadBlob(commitHash string, path string) ([]byte, error)
+	// StartBlame streams the commits that last changed each line of the file
+	// at path in commitHash, in the order git finds them.
+	StartBlame(commitHash string, path string) (BlameStream, error)
 }
 
 type LogStream interface {
 	Next() (*Commit, error)
 	Close() error
 }
+
+type BlameStream interface {
+	Next() (*BlameChunk, error)
+	Close() error
+}
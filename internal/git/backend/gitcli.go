@@ -2,8 +2,10 @@ package backend
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -11,6 +13,9 @@ import (
 
 type gitCLI struct {
 	path string
+	// bare is set for repositories without a working tree, whose path is the
+	// git directory.
+	bare bool
 }
 
 func OpenCLI(repoPath string) (Backend, error) {
@@ -24,6 +29,9 @@ func OpenCLI(repoPath string) (Backend, error) {
 	tmp := &gitCLI{path: abs}
 	root, err := tmp.runGitCommand([]string{"rev-parse", "--show-toplevel"}, false, "git rev-parse")
 	if err != nil {
+		if gitDir, ok := tmp.bareGitDir(); ok {
+			return &gitCLI{path: gitDir, bare: true}, nil
+		}
 		return nil, fmt.Errorf("open repository: %w", err)
 	}
 	root = strings.TrimSpace(root)
@@ -33,6 +41,20 @@ func OpenCLI(repoPath string) (Backend, error) {
 	return &gitCLI{path: root}, nil
 }
 
+// bareGitDir returns the git directory when g.path is in a bare repository,
+// which has no top-level directory to show.
+func (g *gitCLI) bareGitDir() (string, bool) {
+	out, err := g.runGitCommand([]string{"rev-parse", "--is-bare-repository", "--absolute-git-dir"}, false, "git rev-parse")
+	if err != nil {
+		return "", false
+	}
+	bare, gitDir, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	if bare != "true" || gitDir == "" {
+		return "", false
+	}
+	return gitDir, true
+}
+
 func (g *gitCLI) RepoPath() string {
 	if g == nil {
 		return ""
@@ -41,24 +63,38 @@ func (g *gitCLI) RepoPath() string {
 }
 
 func (g *gitCLI) runGitCommand(args []string, allowExit1 bool, context string) (string, error) {
+	return g.runGitCommandInput(args, nil, allowExit1, context)
+}
+
+// runGitCommandInput is runGitCommand feeding stdin to git.
+func (g *gitCLI) runGitCommandInput(args []string, stdin io.Reader, allowExit1 bool, what string) (string, error) {
+	return g.runGitCommandContext(context.Background(), args, stdin, allowExit1, what)
+}
+
+// runGitCommandContext is runGitCommandInput killing git once ctx is done.
+func (g *gitCLI) runGitCommandContext(ctx context.Context, args []string, stdin io.Reader, allowExit1 bool, what string) (string, error) {
 	if g == nil || g.path == "" {
 		return "", fmt.Errorf("repository root not set")
 	}
 	cmdArgs := append([]string{"-C", g.path}, args...)
-	cmd := exec.Command("git", cmdArgs...)
+	cmd := exec.CommandContext(ctx, "git", cmdArgs...)
+	cmd.Stdin = stdin
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	err := cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return "", fmt.Errorf("%s: %w", what, ctxErr)
+	}
 	if err != nil {
 		var exitErr *exec.ExitError
 		if allowExit1 && errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stderr.Len() == 0 {
 			// treat as success when git diff signals changes via exit code 1
 		} else {
 			if stderr.Len() > 0 {
-				return "", fmt.Errorf("%s: %v: %s", context, err, strings.TrimSpace(stderr.String()))
+				return "", fmt.Errorf("%s: %v: %s", what, err, strings.TrimSpace(stderr.String()))
 			}
-			return "", fmt.Errorf("%s: %w", context, err)
+			return "", fmt.Errorf("%s: %w", what, err)
 		}
 	}
 	return stdout.String(), nil
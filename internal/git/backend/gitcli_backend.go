@@ -2,8 +2,10 @@ package backend
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 )
 
@@ -30,31 +32,46 @@ func (g *gitCLI) HeadState() (hash string, headName string, ok bool, err error)
 	return hash, headName, true, nil
 }
 
-func (g *gitCLI) CommitDiffText(commitHash string, parentHash string) (string, error) {
+func (g *gitCLI) CommitDiffText(commitHash string, parentHash string, opts DiffOptions) (string, error) {
 	commitHash = strings.TrimSpace(commitHash)
 	parentHash = strings.TrimSpace(parentHash)
 	if commitHash == "" {
 		return "", fmt.Errorf("commit not specified")
 	}
 	if parentHash != "" {
+		args := append([]string{"diff", "--no-color"}, opts.args()...)
 		return g.runGitCommand(
-			[]string{"diff", "--no-color", parentHash, commitHash},
+			append(args, parentHash, commitHash),
 			true,
 			"git diff",
 		)
 	}
+	args := append([]string{"show", "--no-color"}, opts.args()...)
 	return g.runGitCommand(
-		[]string{"show", "--no-color", "--pretty=format:", commitHash},
+		append(args, "--pretty=format:", commitHash),
 		false,
 		"git show",
 	)
 }
 
-func (g *gitCLI) WorktreeDiffText(staged bool) (string, error) {
+func (g *gitCLI) CombinedDiffText(commitHash string, opts DiffOptions) (string, error) {
+	commitHash = strings.TrimSpace(commitHash)
+	if commitHash == "" {
+		return "", fmt.Errorf("commit not specified")
+	}
+	args := append([]string{"show", "--no-color", "--cc"}, opts.args()...)
+	return g.runGitCommand(
+		append(args, "--pretty=format:", commitHash),
+		false,
+		"git show",
+	)
+}
+
+func (g *gitCLI) WorktreeDiffText(staged bool, opts DiffOptions) (string, error) {
 	if g == nil || g.path == "" {
 		return "", fmt.Errorf("repository root not set")
 	}
-	args := []string{"diff", "--no-color"}
+	args := append([]string{"diff", "--no-color"}, opts.args()...)
 	if staged {
 		args = append(args, "--cached")
 	}
@@ -66,6 +83,9 @@ func (g *gitCLI) LocalChangesStatus() (LocalChanges, error) {
 	if g == nil || g.path == "" {
 		return res, fmt.Errorf("repository root not set")
 	}
+	if g.bare {
+		return res, fmt.Errorf("local changes of a bare repository: %w", errors.ErrUnsupported)
+	}
 	out, err := g.runGitCommand([]string{"status", "--porcelain=v2"}, false, "git status")
 	if err != nil {
 		return res, err
@@ -77,6 +97,24 @@ func (g *gitCLI) LocalChangesStatus() (LocalChanges, error) {
 	return res, nil
 }
 
+func (g *gitCLI) ApplyPatch(patch string, opts ApplyOptions) error {
+	if strings.TrimSpace(patch) == "" {
+		return fmt.Errorf("empty patch")
+	}
+	args := []string{"apply", "--whitespace=nowarn"}
+	if opts.Cached {
+		args = append(args, "--cached")
+	}
+	if opts.Reverse {
+		args = append(args, "--reverse")
+	}
+	if opts.UnidiffZero {
+		args = append(args, "--unidiff-zero")
+	}
+	_, err := g.runGitCommandInput(append(args, "-"), strings.NewReader(patch), false, "git apply")
+	return err
+}
+
 func parseStatusPorcelainV2(r io.Reader) (LocalChanges, error) {
 	var res LocalChanges
 	scanner := bufio.NewScanner(r)
@@ -98,12 +136,22 @@ func parseStatusPorcelainV2(r io.Reader) (LocalChanges, error) {
 			if worktreeState != '.' && worktreeState != '?' {
 				res.HasWorktree = true
 			}
+			if line[0] != 'u' {
+				continue
+			}
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			fields := strings.SplitN(line, " ", 11)
+			if len(fields) < 11 {
+				continue
+			}
+			path := fields[10]
+			if unquoted, err := strconv.Unquote(path); err == nil {
+				path = unquoted
+			}
+			res.Conflicts = append(res.Conflicts, path)
 		default:
 			// '?' untracked, '!' ignored, etc.
 		}
-		if res.HasWorktree && res.HasStaged {
-			break
-		}
 	}
 	return res, scanner.Err()
 }
@@ -127,6 +175,37 @@ func (g *gitCLI) ListRefs() ([]Ref, error) {
 	return parseRefsFromShowRef(out)
 }
 
+func (g *gitCLI) ListStashes() ([]Stash, error) {
+	if g == nil || g.path == "" {
+		return nil, nil
+	}
+	out, err := g.runGitCommand(
+		[]string{"stash", "list", "--format=%H%x00%gd%x00%gs"},
+		true,
+		"git stash list",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return parseStashList(out)
+}
+
+func parseStashList(out string) ([]Stash, error) {
+	var stashes []Stash
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("unexpected stash list line: %q", line)
+		}
+		stashes = append(stashes, Stash{Hash: parts[0], Name: parts[1], Message: parts[2]})
+	}
+	return stashes, nil
+}
+
 func (g *gitCLI) SwitchBranch(branch string) error {
 	branch = strings.TrimSpace(branch)
 	if branch == "" {
@@ -136,6 +215,247 @@ func (g *gitCLI) SwitchBranch(branch string) error {
 	return err
 }
 
+func (g *gitCLI) SwitchDetached(rev string) error {
+	rev = strings.TrimSpace(rev)
+	if err := checkRefArgs(rev); err != nil {
+		return err
+	}
+	_, err := g.runGitCommand([]string{"switch", "--detach", rev}, false, "git switch")
+	return err
+}
+
+func (g *gitCLI) CreateTrackingBranch(name string, remoteBranch string) error {
+	name, remoteBranch = strings.TrimSpace(name), strings.TrimSpace(remoteBranch)
+	if err := checkRefArgs(name, remoteBranch); err != nil {
+		return err
+	}
+	// The full ref name keeps a local branch called like the remote one from
+	// being picked instead.
+	_, err := g.runGitCommand([]string{"switch", "--track", "-c", name, "refs/remotes/" + remoteBranch}, false, "git switch")
+	return err
+}
+
+func (g *gitCLI) CreateBranch(name string, commitHash string, checkout bool) error {
+	name, commitHash = strings.TrimSpace(name), strings.TrimSpace(commitHash)
+	if err := checkRefArgs(name, commitHash); err != nil {
+		return err
+	}
+	if checkout {
+		// git switch takes no "--" before the start point.
+		_, err := g.runGitCommand([]string{"switch", "-c", name, commitHash}, false, "git switch")
+		return err
+	}
+	_, err := g.runGitCommand([]string{"branch", "--", name, commitHash}, false, "git branch")
+	return err
+}
+
+func (g *gitCLI) DeleteBranch(name string, force bool) error {
+	name = strings.TrimSpace(name)
+	if err := checkRefArgs(name); err != nil {
+		return err
+	}
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	_, err := g.runGitCommand([]string{"branch", flag, "--", name}, false, "git branch")
+	return err
+}
+
+func (g *gitCLI) RenameBranch(oldName string, newName string) error {
+	oldName, newName = strings.TrimSpace(oldName), strings.TrimSpace(newName)
+	if err := checkRefArgs(oldName, newName); err != nil {
+		return err
+	}
+	_, err := g.runGitCommand([]string{"branch", "-m", "--", oldName, newName}, false, "git branch")
+	return err
+}
+
+func (g *gitCLI) CreateTag(name string, commitHash string, message string) error {
+	name, commitHash = strings.TrimSpace(name), strings.TrimSpace(commitHash)
+	if err := checkRefArgs(name, commitHash); err != nil {
+		return err
+	}
+	args := []string{"tag"}
+	if strings.TrimSpace(message) != "" {
+		args = append(args, "-a", "-m", message)
+	}
+	_, err := g.runGitCommand(append(args, "--", name, commitHash), false, "git tag")
+	return err
+}
+
+func (g *gitCLI) DeleteTag(name string) error {
+	name = strings.TrimSpace(name)
+	if err := checkRefArgs(name); err != nil {
+		return err
+	}
+	_, err := g.runGitCommand([]string{"tag", "-d", "--", name}, false, "git tag")
+	return err
+}
+
+func (g *gitCLI) CherryPick(commitHash string, mainline int) error {
+	return g.applyCommit("cherry-pick", nil, commitHash, mainline)
+}
+
+func (g *gitCLI) Revert(commitHash string, mainline int) error {
+	return g.applyCommit("revert", []string{"--no-edit"}, commitHash, mainline)
+}
+
+// applyCommit runs "git op" on commitHash, returning a *ConflictError when it
+// stops on conflicts.
+func (g *gitCLI) applyCommit(op string, args []string, commitHash string, mainline int) error {
+	commitHash = strings.TrimSpace(commitHash)
+	if err := checkRefArgs(commitHash); err != nil {
+		return err
+	}
+	if mainline > 0 {
+		parents, err := g.runGitCommand([]string{"rev-list", "--parents", "-n", "1", commitHash}, false, "git rev-list")
+		if err != nil {
+			return err
+		}
+		// git refuses -m for commits that are not merges.
+		if len(strings.Fields(parents)) > 2 {
+			args = append(args, "-m", strconv.Itoa(mainline))
+		}
+	}
+	_, err := g.runGitCommand(append(append([]string{op}, args...), commitHash), false, "git "+op)
+	if err == nil {
+		return nil
+	}
+	out, diffErr := g.runGitCommand([]string{"diff", "--name-only", "--diff-filter=U", "-z"}, false, "git diff")
+	if diffErr != nil {
+		return err
+	}
+	var files []string
+	for _, path := range strings.Split(out, "\x00") {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	if len(files) == 0 {
+		return err
+	}
+	return &ConflictError{Op: op, Files: files}
+}
+
+func (g *gitCLI) Reset(commitHash string, mode ResetMode) error {
+	commitHash = strings.TrimSpace(commitHash)
+	if err := checkRefArgs(commitHash); err != nil {
+		return err
+	}
+	switch mode {
+	case ResetSoft, ResetMixed, ResetHard:
+	default:
+		return fmt.Errorf("unknown reset mode %v", mode)
+	}
+	// git reset takes no "--" before the commit, which would read it as a path.
+	_, err := g.runGitCommand([]string{"reset", "-q", "--" + mode.String(), commitHash}, false, "git reset")
+	return err
+}
+
+// checkRefArgs rejects empty ref names and commits, and those git would read
+// as options.
+func checkRefArgs(args ...string) error {
+	for _, arg := range args {
+		if arg == "" {
+			return fmt.Errorf("ref name or commit not specified")
+		}
+		if strings.HasPrefix(arg, "-") {
+			return fmt.Errorf("bad ref name or commit %q", arg)
+		}
+	}
+	return nil
+}
+
+func (g *gitCLI) ResolveRevision(rev string) (string, error) {
+	rev = strings.TrimSpace(rev)
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("bad revision %q", rev)
+	}
+	out, err := g.runGitCommand([]string{"rev-parse", "-q", "--verify", rev + "^{commit}"}, true, "git rev-parse")
+	if err != nil {
+		return "", err
+	}
+	hash := strings.TrimSpace(out)
+	if hash == "" {
+		return "", fmt.Errorf("unknown revision %q", rev)
+	}
+	return hash, nil
+}
+
+// LogContains expands the revisions of spec with git rev-parse and checks
+// that commitHash is reachable from the included tips and not from the
+// excluded ones, then that it touches the paths of spec. Other options of the
+// walk such as --first-parent are not taken into account.
+func (g *gitCLI) LogContains(spec LogSpec, commitHash string) (bool, error) {
+	hash, err := g.ResolveRevision(commitHash)
+	if err != nil {
+		return false, err
+	}
+	revisions := spec.Revisions
+	if len(revisions) == 0 {
+		revisions = []string{"HEAD"}
+	}
+	out, err := g.runGitCommand(append([]string{"rev-parse", "--revs-only"}, revisions...), false, "git rev-parse")
+	if err != nil {
+		return false, err
+	}
+	var include, exclude []string
+	for _, rev := range strings.Fields(out) {
+		if tip, ok := strings.CutPrefix(rev, "^"); ok {
+			exclude = append(exclude, tip)
+		} else {
+			include = append(include, rev)
+		}
+	}
+	if ok, err := g.reachedFrom(include, hash); err != nil || !ok {
+		return false, err
+	}
+	if ok, err := g.reachedFrom(exclude, hash); err != nil || ok {
+		return false, err
+	}
+	if len(spec.Paths) == 0 || spec.Follow {
+		return true, nil
+	}
+	// The commit is only listed when it changes the paths, in which case it
+	// is the first commit touching them from itself.
+	out, err = g.runGitCommand(append([]string{"rev-list", "-n", "1", hash, "--"}, spec.Paths...), false, "git rev-list")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == hash, nil
+}
+
+func (g *gitCLI) CountCommits(revisions []string) (int, error) {
+	args := append([]string{"rev-list", "--count"}, revisions...)
+	out, err := g.runGitCommand(append(args, "--"), false, "git rev-list")
+	if err != nil {
+		return 0, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list: unexpected count %q", strings.TrimSpace(out))
+	}
+	return count, nil
+}
+
+// reachedFrom reports whether hash is reachable from any of tips: git
+// rev-list lists nothing for hash once the tips exclude it.
+func (g *gitCLI) reachedFrom(tips []string, hash string) (bool, error) {
+	if len(tips) == 0 {
+		return false, nil
+	}
+	var stdin strings.Builder
+	for _, tip := range tips {
+		stdin.WriteString("^" + tip + "\n")
+	}
+	out, err := g.runGitCommandInput([]string{"rev-list", "-n", "1", "--stdin", hash}, strings.NewReader(stdin.String()), false, "git rev-list")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == "", nil
+}
+
 func parseRefsFromShowRef(out string) ([]Ref, error) {
 	type refEntry struct {
 		hash string
@@ -201,3 +521,63 @@ func parseRefsFromShowRef(out string) ([]Ref, error) {
 	}
 	return refs, nil
 }
+
+func (g *gitCLI) ListTree(commitHash string) ([]TreeEntry, error) {
+	commitHash = strings.TrimSpace(commitHash)
+	if commitHash == "" {
+		return nil, fmt.Errorf("commit not specified")
+	}
+	out, err := g.runGitCommand(
+		[]string{"ls-tree", "-r", "-t", "-z", "--full-tree", commitHash},
+		false,
+		"git ls-tree",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return parseLsTree(out)
+}
+
+// parseLsTree parses the NUL terminated "<mode> <type> <hash>\t<path>"
+// records of git ls-tree -z.
+func parseLsTree(out string) ([]TreeEntry, error) {
+	var entries []TreeEntry
+	for record := range strings.SplitSeq(out, "\x00") {
+		if record == "" {
+			continue
+		}
+		info, path, ok := strings.Cut(record, "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) != 3 || path == "" {
+			return nil, fmt.Errorf("unexpected ls-tree record: %q", record)
+		}
+		entry := TreeEntry{Path: path, Mode: fields[0], Hash: fields[2]}
+		switch fields[1] {
+		case "blob":
+			entry.Kind = TreeEntryBlob
+		case "tree":
+			entry.Kind = TreeEntryTree
+		case "commit":
+			entry.Kind = TreeEntrySubmodule
+		default:
+			return nil, fmt.Errorf("unexpected ls-tree object type: %q", fields[1])
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (g *gitCLI) ReadBlob(commitHash string, path string) ([]byte, error) {
+	commitHash = strings.TrimSpace(commitHash)
+	if commitHash == "" {
+		return nil, fmt.Errorf("commit not specified")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("path not specified")
+	}
+	out, err := g.runGitCommand([]string{"cat-file", "blob", commitHash + ":" + path}, false, "git cat-file")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
@@ -2,6 +2,9 @@ package backend
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -32,8 +35,17 @@ func TestParseStatusPorcelainV2(t *testing.T) {
 		},
 		{
 			name: "unmerged_counts_as_both",
-			in:   "u UU N... 100644 100644 100644 abcdef0 abcdef0 path.txt\n",
-			want: LocalChanges{HasWorktree: true, HasStaged: true},
+			in:   "u UU N... 100644 100644 100644 100644 abcdef0 abcdef0 abcdef0 path.txt\n",
+			want: LocalChanges{HasWorktree: true, HasStaged: true, Conflicts: []string{"path.txt"}},
+		},
+		{
+			name: "unmerged_paths",
+			in: strings.Join([]string{
+				"1 M. N... 100644 100644 100644 abcdef0 abcdef0 a.txt",
+				"u UU N... 100644 100644 100644 100644 abcdef0 abcdef0 abcdef0 with space.txt",
+				`u AA N... 000000 100644 100644 100644 0000000 abcdef0 abcdef0 "caf\303\251.txt"`,
+			}, "\n") + "\n",
+			want: LocalChanges{HasWorktree: true, HasStaged: true, Conflicts: []string{"with space.txt", "café.txt"}},
 		},
 		{
 			name: "untracked_ignored",
@@ -69,7 +81,8 @@ func TestParseStatusPorcelainV2(t *testing.T) {
 			if err != nil {
 				t.Fatalf("parseStatusPorcelainV2() error = %v", err)
 			}
-			if got.HasWorktree != tt.want.HasWorktree || got.HasStaged != tt.want.HasStaged {
+			if got.HasWorktree != tt.want.HasWorktree || got.HasStaged != tt.want.HasStaged ||
+				!slices.Equal(got.Conflicts, tt.want.Conflicts) {
 				t.Fatalf("parseStatusPorcelainV2() = %+v, want %+v", got, tt.want)
 			}
 		})
@@ -144,3 +157,253 @@ func assertHasRef(t *testing.T, refs []Ref, want Ref) {
 	}
 	t.Fatalf("missing ref: %+v (got=%+v)", want, refs)
 }
+
+func TestParseStashList(t *testing.T) {
+	out := "aaa\x00stash@{0}\x00On main: second\nbbb\x00stash@{1}\x00WIP on main: 1234567 first\n"
+	got, err := parseStashList(out)
+	if err != nil {
+		t.Fatalf("parseStashList: %v", err)
+	}
+	want := []Stash{
+		{Hash: "aaa", Name: "stash@{0}", Message: "On main: second"},
+		{Hash: "bbb", Name: "stash@{1}", Message: "WIP on main: 1234567 first"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	if _, err := parseStashList("broken\n"); err == nil {
+		t.Fatalf("expected error for malformed line")
+	}
+}
+
+func TestDiffOptionsArgs(t *testing.T) {
+	tests := []struct {
+		opts DiffOptions
+		want []string
+	}{
+		{DiffOptions{}, nil},
+		{DiffOptions{Whitespace: WhitespaceIgnoreAll, IgnoreBlankLines: true}, []string{"-w", "--ignore-blank-lines"}},
+		{DiffOptions{Whitespace: WhitespaceIgnoreChange}, []string{"-b"}},
+		{DiffOptions{Context: 10}, []string{"-U10"}},
+		{DiffOptions{Context: NoContext}, []string{"-U0"}},
+		{DiffOptions{RenameThreshold: -1}, []string{"--no-renames"}},
+		{DiffOptions{RenameThreshold: 75, CopyThreshold: 120}, []string{"-M75%", "-C100%"}},
+		{DiffOptions{Algorithm: DiffAlgorithmHistogram}, []string{"--diff-algorithm=histogram"}},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.args(); !slices.Equal(got, tt.want) {
+			t.Fatalf("%+v.args() = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestCLIRefOperations(t *testing.T) {
+	dir := createNativeTestRepo(t)
+	cli, err := OpenCLI(dir)
+	if err != nil {
+		t.Fatalf("OpenCLI: %v", err)
+	}
+	head := runGitCmd(t, dir, nil, "rev-parse", "HEAD")
+	first := runGitCmd(t, dir, nil, "rev-list", "--max-parents=0", "HEAD")
+
+	if err := cli.CreateBranch("topic", first, false); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := cli.CreateTag("light-2", head, ""); err != nil {
+		t.Fatalf("CreateTag lightweight: %v", err)
+	}
+	if err := cli.CreateTag("v2.0", first, "second release"); err != nil {
+		t.Fatalf("CreateTag annotated: %v", err)
+	}
+	if got := runGitCmd(t, dir, nil, "cat-file", "-t", "v2.0"); got != "tag" {
+		t.Fatalf("v2.0 is a %s, want an annotated tag", got)
+	}
+	if err := cli.RenameBranch("topic", "topic-renamed"); err != nil {
+		t.Fatalf("RenameBranch: %v", err)
+	}
+	refs, err := cli.ListRefs()
+	if err != nil {
+		t.Fatalf("ListRefs: %v", err)
+	}
+	assertHasRef(t, refs, Ref{Hash: first, Kind: RefKindBranch, Name: "topic-renamed"})
+	assertHasRef(t, refs, Ref{Hash: head, Kind: RefKindTag, Name: "light-2"})
+	assertHasRef(t, refs, Ref{Hash: first, Kind: RefKindTag, Name: "v2.0"})
+
+	// feature was merged into main, a new commit on it is not.
+	runGitCmd(t, dir, nil, "branch", "unmerged", "feature")
+	tip := runGitCmd(t, dir, nil, "commit-tree", "-m", "unmerged work", "-p", "unmerged", "unmerged^{tree}")
+	runGitCmd(t, dir, nil, "update-ref", "refs/heads/unmerged", tip)
+	if err := cli.DeleteBranch("unmerged", false); err == nil {
+		t.Fatalf("expected DeleteBranch to refuse an unmerged branch")
+	}
+	if err := cli.DeleteBranch("unmerged", true); err != nil {
+		t.Fatalf("DeleteBranch force: %v", err)
+	}
+	if err := cli.DeleteBranch("topic-renamed", false); err != nil {
+		t.Fatalf("DeleteBranch: %v", err)
+	}
+	if err := cli.DeleteTag("v2.0"); err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	if out := runGitCmd(t, dir, nil, "for-each-ref", "refs/heads/topic-renamed", "refs/heads/unmerged", "refs/tags/v2.0"); out != "" {
+		t.Fatalf("expected the refs to be deleted, got %q", out)
+	}
+
+	if err := cli.CreateBranch("switched", first, true); err != nil {
+		t.Fatalf("CreateBranch checkout: %v", err)
+	}
+	if got := runGitCmd(t, dir, nil, "symbolic-ref", "--short", "HEAD"); got != "switched" {
+		t.Fatalf("HEAD is %q, want switched", got)
+	}
+	if err := cli.CreateBranch("-f", first, false); err == nil {
+		t.Fatalf("expected an error for a name starting with a dash")
+	}
+}
+
+func TestCLICherryPickRevertReset(t *testing.T) {
+	dir := createNativeTestRepo(t)
+	cli, err := OpenCLI(dir)
+	if err != nil {
+		t.Fatalf("OpenCLI: %v", err)
+	}
+	head := runGitCmd(t, dir, nil, "rev-parse", "HEAD")
+	merge := runGitCmd(t, dir, nil, "rev-parse", "HEAD~1")
+
+	if err := cli.Revert(head, 0); err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if got := runGitCmd(t, dir, nil, "log", "-1", "--format=%s"); !strings.HasPrefix(got, "Revert ") {
+		t.Fatalf("HEAD is %q, want a revert commit", got)
+	}
+	if err := cli.Reset(head, ResetHard); err != nil {
+		t.Fatalf("Reset hard: %v", err)
+	}
+	// Reverting a merge needs a mainline, which is ignored for other commits.
+	if err := cli.Revert(merge, 1); err != nil {
+		t.Fatalf("Revert merge: %v", err)
+	}
+	if got := runGitCmd(t, dir, nil, "rev-parse", "HEAD~1"); got != head {
+		t.Fatalf("HEAD~1 is %s, want %s", got, head)
+	}
+
+	if err := cli.Reset(head, ResetSoft); err != nil {
+		t.Fatalf("Reset soft: %v", err)
+	}
+	if got := runGitCmd(t, dir, nil, "rev-parse", "HEAD"); got != head {
+		t.Fatalf("HEAD is %s, want %s", got, head)
+	}
+	status, err := cli.LocalChangesStatus()
+	if err != nil {
+		t.Fatalf("LocalChangesStatus: %v", err)
+	}
+	if !status.HasStaged {
+		t.Fatalf("expected a soft reset to keep the reverted changes staged")
+	}
+	if err := cli.Reset("HEAD", ResetMixed); err != nil {
+		t.Fatalf("Reset mixed: %v", err)
+	}
+	if status, err = cli.LocalChangesStatus(); err != nil || status.HasStaged || !status.HasWorktree {
+		t.Fatalf("after a mixed reset got %+v, %v, want only worktree changes", status, err)
+	}
+	if err := cli.Reset("HEAD", ResetHard); err != nil {
+		t.Fatalf("Reset hard: %v", err)
+	}
+
+	// "feature edit" changed dir/b.go, which the first commit created.
+	runGitCmd(t, dir, nil, "switch", "-q", "-c", "old", "HEAD~3")
+	if err := os.WriteFile(filepath.Join(dir, "dir", "b.go"), []byte("package b\n\nfunc B() int {\n\treturn 3\n}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	runGitCmd(t, dir, nil, "commit", "-q", "--no-gpg-sign", "-am", "conflicting edit")
+	err = cli.CherryPick("feature", 0)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("CherryPick error = %v, want a ConflictError", err)
+	}
+	if conflict.Op != "cherry-pick" || !slices.Equal(conflict.Files, []string{"dir/b.go"}) {
+		t.Fatalf("unexpected conflict %+v", conflict)
+	}
+	status, err = cli.LocalChangesStatus()
+	if err != nil {
+		t.Fatalf("LocalChangesStatus: %v", err)
+	}
+	if !slices.Equal(status.Conflicts, []string{"dir/b.go"}) {
+		t.Fatalf("status conflicts = %v, want [dir/b.go]", status.Conflicts)
+	}
+
+	if err := cli.Reset("-q", ResetSoft); err == nil {
+		t.Fatalf("expected an error for a commit starting with a dash")
+	}
+}
+
+func TestCLISwitchDetachedAndTracking(t *testing.T) {
+	dir := createNativeTestRepo(t)
+	cli, err := OpenCLI(dir)
+	if err != nil {
+		t.Fatalf("OpenCLI: %v", err)
+	}
+	tag := runGitCmd(t, dir, nil, "rev-parse", "v1.0^{commit}")
+
+	if err := cli.SwitchDetached("v1.0"); err != nil {
+		t.Fatalf("SwitchDetached: %v", err)
+	}
+	hash, headName, ok, err := cli.HeadState()
+	if err != nil || !ok {
+		t.Fatalf("HeadState: %v, %v", ok, err)
+	}
+	if hash != tag || headName != "HEAD" {
+		t.Fatalf("HeadState = %s %q, want %s detached", hash, headName, tag)
+	}
+
+	// Tracking needs the remote to be configured, not only its refs.
+	runGitCmd(t, dir, nil, "remote", "add", "origin", dir)
+	if err := cli.CreateTrackingBranch("from-origin", "origin/main"); err != nil {
+		t.Fatalf("CreateTrackingBranch: %v", err)
+	}
+	if got := runGitCmd(t, dir, nil, "symbolic-ref", "--short", "HEAD"); got != "from-origin" {
+		t.Fatalf("HEAD is %q, want from-origin", got)
+	}
+	if got := runGitCmd(t, dir, nil, "rev-parse", "--abbrev-ref", "from-origin@{upstream}"); got != "origin/main" {
+		t.Fatalf("upstream is %q, want origin/main", got)
+	}
+	if err := cli.SwitchDetached("--orphan"); err == nil {
+		t.Fatalf("expected an error for a revision starting with a dash")
+	}
+}
+
+func TestCLIApplyPatch(t *testing.T) {
+	dir := createNativeTestRepo(t)
+	cli, err := OpenCLI(dir)
+	if err != nil {
+		t.Fatalf("OpenCLI: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "long.txt"), []byte("replaced\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	patch, err := cli.WorktreeDiffText(false, DiffOptions{})
+	if err != nil {
+		t.Fatalf("WorktreeDiffText: %v", err)
+	}
+
+	if err := cli.ApplyPatch(patch, ApplyOptions{Cached: true}); err != nil {
+		t.Fatalf("ApplyPatch cached: %v", err)
+	}
+	if got := runGitCmd(t, dir, nil, "diff", "--cached", "--name-only"); got != "long.txt" {
+		t.Fatalf("staged files = %q, want long.txt", got)
+	}
+	if err := cli.ApplyPatch(patch, ApplyOptions{Cached: true, Reverse: true}); err != nil {
+		t.Fatalf("ApplyPatch cached reverse: %v", err)
+	}
+	if got := runGitCmd(t, dir, nil, "diff", "--cached", "--name-only"); got != "" {
+		t.Fatalf("staged files = %q, want none", got)
+	}
+	if err := cli.ApplyPatch(patch, ApplyOptions{Reverse: true}); err != nil {
+		t.Fatalf("ApplyPatch reverse: %v", err)
+	}
+	if got := runGitCmd(t, dir, nil, "status", "--porcelain"); got != "" {
+		t.Fatalf("expected a clean tree, got %q", got)
+	}
+	if err := cli.ApplyPatch(patch, ApplyOptions{Reverse: true}); err == nil {
+		t.Fatalf("expected an error reverting a patch that is not applied")
+	}
+}
@@ -0,0 +1,194 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type gitBlameStream struct {
+	cancel context.CancelFunc
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+	parser *blameParser
+
+	waitOnce sync.Once
+	waitErr  error
+}
+
+// StartBlame runs git blame --incremental, which reports each run of lines
+// as soon as its origin is found instead of waiting for the whole file.
+func (g *gitCLI) StartBlame(commitHash string, path string) (BlameStream, error) {
+	if g == nil || g.path == "" {
+		return nil, fmt.Errorf("repository root not set")
+	}
+	commitHash = strings.TrimSpace(commitHash)
+	if commitHash == "" || path == "" {
+		return nil, fmt.Errorf("commit or path not specified")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, "git", "--no-pager", "-C", g.path, "blame", "--incremental", commitHash, "--", path)
+	var stream gitBlameStream
+	stream.cancel = cancel
+	stream.cmd = cmd
+	cmd.Stderr = &stream.stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("git blame stdout: %w", err)
+	}
+	stream.stdout = stdout
+	stream.parser = newBlameParser(stdout)
+	if err := cmd.Start(); err != nil {
+		cancel()
+		_ = stdout.Close()
+		if stream.stderr.Len() > 0 {
+			return nil, fmt.Errorf("git blame start: %v: %s", err, strings.TrimSpace(stream.stderr.String()))
+		}
+		return nil, fmt.Errorf("git blame start: %w", err)
+	}
+	return &stream, nil
+}
+
+func (s *gitBlameStream) Next() (*BlameChunk, error) {
+	chunk, err := s.parser.next()
+	if err == io.EOF {
+		if waitErr := s.wait(); waitErr != nil {
+			return nil, waitErr
+		}
+	}
+	return chunk, err
+}
+
+func (s *gitBlameStream) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.stdout != nil {
+		_ = s.stdout.Close()
+	}
+	return s.wait()
+}
+
+func (s *gitBlameStream) wait() error {
+	s.waitOnce.Do(func() {
+		s.waitErr = s.cmd.Wait()
+	})
+	if s.waitErr == nil {
+		return nil
+	}
+	if s.stderr.Len() > 0 {
+		return fmt.Errorf("git blame: %v: %s", s.waitErr, strings.TrimSpace(s.stderr.String()))
+	}
+	return fmt.Errorf("git blame: %w", s.waitErr)
+}
+
+// blameParser reads the output of git blame --incremental. The details of a
+// commit are only printed with its first run of lines, so they are kept for
+// the later ones.
+type blameParser struct {
+	r       *bufio.Reader
+	commits map[string]*BlameChunk
+}
+
+func newBlameParser(r io.Reader) *blameParser {
+	return &blameParser{r: bufio.NewReader(r), commits: map[string]*BlameChunk{}}
+}
+
+func (p *blameParser) next() (*BlameChunk, error) {
+	line, err := p.readLine()
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unexpected blame line: %q", line)
+	}
+	var nums [3]int
+	for i, field := range fields[1:] {
+		if nums[i], err = strconv.Atoi(field); err != nil {
+			return nil, fmt.Errorf("unexpected blame line: %q", line)
+		}
+	}
+	info, ok := p.commits[fields[0]]
+	if !ok {
+		info = &BlameChunk{Hash: fields[0]}
+		p.commits[fields[0]] = info
+	}
+	var authorTime int64
+	var authorZone *time.Location
+	for {
+		line, err = p.readLine()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			info.Author.Name = value
+		case "author-mail":
+			info.Author.Email = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			authorTime, _ = strconv.ParseInt(value, 10, 64)
+		case "author-tz":
+			authorZone = parseBlameZone(value)
+		case "summary":
+			info.Summary = value
+		case "boundary":
+			info.Boundary = true
+		case "filename":
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			if authorTime != 0 {
+				info.Author.When = time.Unix(authorTime, 0)
+				if authorZone != nil {
+					info.Author.When = info.Author.When.In(authorZone)
+				}
+			}
+			chunk := *info
+			chunk.OrigLine, chunk.FinalLine, chunk.Lines = nums[0], nums[1], nums[2]
+			chunk.Path = value
+			return &chunk, nil
+		}
+	}
+}
+
+func (p *blameParser) readLine() (string, error) {
+	line, err := p.r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// parseBlameZone parses a "+0130" style time zone offset.
+func parseBlameZone(tz string) *time.Location {
+	if len(tz) != 5 || (tz[0] != '+' && tz[0] != '-') {
+		return nil
+	}
+	hours, err1 := strconv.Atoi(tz[1:3])
+	minutes, err2 := strconv.Atoi(tz[3:])
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	offset := hours*3600 + minutes*60
+	if tz[0] == '-' {
+		offset = -offset
+	}
+	return time.FixedZone("", offset)
+}
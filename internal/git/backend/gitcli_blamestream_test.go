@@ -0,0 +1,131 @@
+package backend
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlameParser(t *testing.T) {
+	t.Parallel()
+
+	out := strings.Join([]string{
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa 3 3 2",
+		"author Alice",
+		"author-mail <alice@example.com>",
+		"author-time 1700000000",
+		"author-tz +0130",
+		"committer Bob",
+		"committer-mail <bob@example.com>",
+		"committer-time 1700000100",
+		"committer-tz +0000",
+		"summary Edit files",
+		"previous bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb a.txt",
+		"filename a.txt",
+		"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb 1 1 2",
+		"author Carol",
+		"author-mail <carol@example.com>",
+		"author-time 1600000000",
+		"author-tz -0500",
+		"summary Initial",
+		"boundary",
+		"filename \"old\\tname.txt\"",
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa 6 5 1",
+		"filename a.txt",
+		"",
+	}, "\n")
+	p := newBlameParser(strings.NewReader(out))
+
+	first, err := p.next()
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	when := time.Unix(1700000000, 0)
+	if first.Hash[0] != 'a' || first.OrigLine != 3 || first.FinalLine != 3 || first.Lines != 2 ||
+		first.Author.Name != "Alice" || first.Author.Email != "alice@example.com" ||
+		!first.Author.When.Equal(when) || first.Summary != "Edit files" || first.Path != "a.txt" || first.Boundary {
+		t.Fatalf("unexpected first chunk: %+v", first)
+	}
+	if _, offset := first.Author.When.Zone(); offset != 5400 {
+		t.Fatalf("author zone offset = %d, want 5400", offset)
+	}
+
+	second, err := p.next()
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if second.Author.Name != "Carol" || !second.Boundary || second.Path != "old\tname.txt" {
+		t.Fatalf("unexpected second chunk: %+v", second)
+	}
+
+	third, err := p.next()
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if third.Author.Name != "Alice" || third.Summary != "Edit files" || third.OrigLine != 6 || third.FinalLine != 5 || third.Lines != 1 {
+		t.Fatalf("details of a known commit not reused: %+v", third)
+	}
+	if _, err := p.next(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestBlameParser_Truncated(t *testing.T) {
+	t.Parallel()
+
+	p := newBlameParser(strings.NewReader("aaaa 1 1 1\nauthor Alice\n"))
+	if _, err := p.next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected ErrUnexpectedEOF, got %v", err)
+	}
+	p = newBlameParser(strings.NewReader("not a blame line\n"))
+	if _, err := p.next(); err == nil {
+		t.Fatal("expected error for malformed line")
+	}
+}
+
+func TestCLIBlame(t *testing.T) {
+	dir := createNativeTestRepo(t)
+	cli, err := OpenCLI(dir)
+	if err != nil {
+		t.Fatalf("OpenCLI: %v", err)
+	}
+	stream, err := cli.StartBlame("main", "long.txt")
+	if err != nil {
+		t.Fatalf("StartBlame: %v", err)
+	}
+	defer stream.Close()
+	covered := map[int]string{}
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		for line := chunk.FinalLine; line < chunk.FinalLine+chunk.Lines; line++ {
+			covered[line] = chunk.Summary
+		}
+	}
+	// line 3 was rewritten by the second commit; line 30 was removed.
+	if len(covered) != 39 {
+		t.Fatalf("blame covered %d lines, want 39", len(covered))
+	}
+	if covered[4] != "edit files" || covered[1] != "initial" {
+		t.Fatalf("unexpected blame: line 1 %q, line 4 %q", covered[1], covered[4])
+	}
+
+	if _, err := cli.StartBlame("main", ""); err == nil {
+		t.Fatal("expected error without path")
+	}
+	stream, err = cli.StartBlame("main", "missing.txt")
+	if err == nil {
+		_, err = stream.Next()
+		_ = stream.Close()
+	}
+	if err == nil || err == io.EOF {
+		t.Fatalf("expected error blaming a missing file, got %v", err)
+	}
+}
@@ -18,41 +18,23 @@ type gitLogStream struct {
 	stdout io.ReadCloser
 	stderr bytes.Buffer
 	r      *bufio.Reader
+	// follow reads the name of the followed file after each commit.
+	follow bool
 
 	waitOnce sync.Once
 	waitErr  error
 }
 
-func (g *gitCLI) StartLogStream(fromHash string) (LogStream, error) {
+func (g *gitCLI) StartLogStream(spec LogSpec) (LogStream, error) {
 	if g == nil || g.path == "" {
 		return nil, fmt.Errorf("repository root not set")
 	}
-	fromHash = strings.TrimSpace(fromHash)
-	if fromHash == "" {
-		return nil, fmt.Errorf("starting commit not specified")
-	}
-	// NUL-delimited records; commit message cannot contain NUL.
-	const format = "%H%n%P%n%an%n%ae%n%aI%n%cn%n%ce%n%cI%n%B%x00"
-
 	ctx, cancel := context.WithCancel(context.Background())
-	cmd := exec.CommandContext(
-		ctx,
-		"git",
-		"--no-pager",
-		"-C",
-		g.path,
-		"log",
-		"--no-color",
-		"--no-decorate",
-		"--date-order",
-		"--no-patch",
-		// Use tformat to avoid git log adding an extra newline after each record.
-		"--pretty=tformat:"+format,
-		fromHash,
-	)
+	cmd := exec.CommandContext(ctx, "git", logStreamArgs(g.path, spec)...)
 	var stream gitLogStream
 	stream.cancel = cancel
 	stream.cmd = cmd
+	stream.follow = spec.Follow
 	cmd.Stderr = &stream.stderr
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -72,6 +54,114 @@ func (g *gitCLI) StartLogStream(fromHash string) (LogStream, error) {
 	return &stream, nil
 }
 
+func logStreamArgs(repoPath string, spec LogSpec) []string {
+	// NUL-delimited records; commit message cannot contain NUL.
+	const format = "%H%n%P%n%an%n%ae%n%aI%n%cn%n%ce%n%cI%n%B%x00"
+
+	args := []string{
+		"--no-pager",
+		"-C",
+		repoPath,
+		"log",
+		"--no-color",
+		"--no-decorate",
+		"--date-order",
+		// Use tformat to avoid git log adding an extra newline after each record.
+		"--pretty=tformat:" + format,
+	}
+	if len(spec.Paths) > 0 {
+		// Rewrite parents to the nearest commits touching the paths so the graph
+		// stays connected, like gitk does.
+		args = append(args, "--parents")
+	}
+	if spec.Follow {
+		// The status of the followed file comes after each record, NUL
+		// separated so that any file name can be read back.
+		args = append(args, "--follow", "--name-status", "-z")
+	} else {
+		args = append(args, "--no-patch")
+	}
+	revisions := spec.Revisions
+	if len(revisions) == 0 {
+		revisions = []string{"HEAD"}
+	}
+	args = append(args, revisions...)
+	// Always terminate revisions so that a path is never mistaken for a revision.
+	args = append(args, "--")
+	return append(args, spec.Paths...)
+}
+
+func (g *gitCLI) SearchCommits(ctx context.Context, spec LogSpec, query SearchQuery) ([]string, error) {
+	if query.Pattern == "" {
+		return nil, fmt.Errorf("search pattern not specified")
+	}
+	out, err := g.runGitCommandContext(ctx, searchArgs(spec, query), nil, false, "git log")
+	if err != nil {
+		return nil, err
+	}
+	if query.Kind != SearchPaths || len(spec.Paths) == 0 {
+		return strings.Fields(out), nil
+	}
+	// git log takes a single pathspec, so the commits touching the searched
+	// path are filtered by the ones the view lists.
+	view, err := g.runGitCommandContext(ctx, viewHashesArgs(spec), nil, false, "git log")
+	if err != nil {
+		return nil, err
+	}
+	touching := make(map[string]bool)
+	for _, hash := range strings.Fields(out) {
+		touching[hash] = true
+	}
+	var hashes []string
+	for _, hash := range strings.Fields(view) {
+		if touching[hash] {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes, nil
+}
+
+// viewHashesArgs lists the hashes of the commits shown for spec.
+func viewHashesArgs(spec LogSpec) []string {
+	args := []string{"log", "--no-color", "--date-order", "--format=%H"}
+	if spec.Follow {
+		args = append(args, "--follow")
+	}
+	return appendRevisionsAndPaths(args, spec, spec.Paths)
+}
+
+func searchArgs(spec LogSpec, query SearchQuery) []string {
+	args := []string{"log", "--no-color", "--date-order", "--format=%H"}
+	paths := spec.Paths
+	switch query.Kind {
+	case SearchPickaxe:
+		args = append(args, "-S"+query.Pattern)
+	case SearchChangedLines:
+		args = append(args, "-G"+query.Pattern)
+	case SearchPaths:
+		paths = []string{query.Pattern}
+		if len(spec.Paths) > 0 {
+			// Side branches the view shows are simplified away by the
+			// searched path alone, so every commit changing it is listed.
+			args = append(args, "--full-history")
+		}
+	}
+	if spec.Follow && query.Kind != SearchPaths {
+		args = append(args, "--follow")
+	}
+	return appendRevisionsAndPaths(args, spec, paths)
+}
+
+func appendRevisionsAndPaths(args []string, spec LogSpec, paths []string) []string {
+	revisions := spec.Revisions
+	if len(revisions) == 0 {
+		revisions = []string{"HEAD"}
+	}
+	args = append(args, revisions...)
+	args = append(args, "--")
+	return append(args, paths...)
+}
+
 func (s *gitLogStream) Next() (*Commit, error) {
 	rec, err := s.r.ReadBytes(0)
 	if err != nil {
@@ -100,9 +190,51 @@ func (s *gitLogStream) Next() (*Commit, error) {
 	if err != nil {
 		return nil, err
 	}
+	if s.follow {
+		if commit.Path, err = s.readFollowPath(); err != nil {
+			return nil, err
+		}
+	}
 	return commit, nil
 }
 
+// readFollowPath reads the --name-status -z entry git prints after a commit
+// when following a file, returning the name of the file at that commit.
+// Merges have no entry, in which case it returns "".
+func (s *gitLogStream) readFollowPath() (string, error) {
+	// -z ends each record with a NUL, after the one closing the format.
+	if b, err := s.r.Peek(1); err == nil && b[0] == 0 {
+		_, _ = s.r.Discard(1)
+	}
+	if b, err := s.r.Peek(1); err != nil || b[0] != '\n' {
+		return "", nil
+	}
+	_, _ = s.r.Discard(1)
+	field := func() (string, error) {
+		b, err := s.r.ReadBytes(0)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return "", fmt.Errorf("read followed file: %w", err)
+		}
+		return string(b[:len(b)-1]), nil
+	}
+	status, err := field()
+	if err != nil {
+		return "", err
+	}
+	path, err := field()
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(status, "R") || strings.HasPrefix(status, "C") {
+		// Renames and copies list the old name first.
+		return field()
+	}
+	return path, nil
+}
+
 func (s *gitLogStream) Close() error {
 	if s.cancel != nil {
 		s.cancel()
@@ -2,6 +2,10 @@ package backend
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -71,3 +75,87 @@ func TestParseGitLogRecord_ShortRecord(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+func TestLogStreamArgs(t *testing.T) {
+	t.Parallel()
+
+	args := logStreamArgs("/repo", LogSpec{})
+	if slices.Contains(args, "--parents") {
+		t.Fatalf("did not expect --parents without paths: %v", args)
+	}
+	if got := args[len(args)-2:]; !slices.Equal(got, []string{"HEAD", "--"}) {
+		t.Fatalf("expected HEAD walk, got %v", args)
+	}
+
+	args = logStreamArgs("/repo", LogSpec{
+		Revisions: []string{"main..feature", "--all"},
+		Paths:     []string{"src/"},
+	})
+	if !slices.Contains(args, "--parents") {
+		t.Fatalf("expected --parents with paths: %v", args)
+	}
+	if got := args[len(args)-4:]; !slices.Equal(got, []string{"main..feature", "--all", "--", "src/"}) {
+		t.Fatalf("unexpected revision/path args: %v", args)
+	}
+
+	args = logStreamArgs("/repo", LogSpec{Paths: []string{"a.txt"}, Follow: true})
+	if got := args[len(args)-6:]; !slices.Equal(got, []string{"--follow", "--name-status", "-z", "HEAD", "--", "a.txt"}) {
+		t.Fatalf("unexpected follow args: %v", args)
+	}
+}
+
+func TestCLILogFollow(t *testing.T) {
+	dir := t.TempDir()
+	runGitCmd(t, dir, nil, "init", "-q", "-b", "main")
+	runGitCmd(t, dir, nil, "config", "user.name", "Alice")
+	runGitCmd(t, dir, nil, "config", "user.email", "alice@example.com")
+	commit := func(msg string, files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			runGitCmd(t, dir, nil, "add", "--", name)
+		}
+		runGitCmd(t, dir, nil, "commit", "-q", "--no-gpg-sign", "-m", msg)
+	}
+	commit("add", map[string]string{"old name.txt": "one\ntwo\nthree\nfour\n"})
+	commit("edit", map[string]string{"old name.txt": "one\ntwo\nthree\nfour\nfive\n"})
+	runGitCmd(t, dir, nil, "mv", "old name.txt", "new.txt")
+	commit("rename", nil)
+	commit("unrelated", map[string]string{"other.txt": "other\n"})
+	commit("edit again", map[string]string{"new.txt": "one\ntwo\nthree\nfour\nfive\nsix\n"})
+
+	cli, err := OpenCLI(dir)
+	if err != nil {
+		t.Fatalf("OpenCLI: %v", err)
+	}
+	var got []string
+	for _, commit := range readLog(t, cli, LogSpec{Paths: []string{"new.txt"}, Follow: true}) {
+		got = append(got, strings.TrimSpace(commit.Message)+": "+commit.Path)
+	}
+	want := []string{"edit again: new.txt", "rename: new.txt", "edit: old name.txt", "add: old name.txt"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("follow log = %q, want %q", got, want)
+	}
+}
+
+func TestSearchArgs(t *testing.T) {
+	t.Parallel()
+
+	spec := LogSpec{Revisions: []string{"--all"}, Paths: []string{"src"}}
+	tests := []struct {
+		query SearchQuery
+		want  []string
+	}{
+		{SearchQuery{Kind: SearchPickaxe, Pattern: "foo bar"}, []string{"-Sfoo bar", "--all", "--", "src"}},
+		{SearchQuery{Kind: SearchChangedLines, Pattern: "^func"}, []string{"-G^func", "--all", "--", "src"}},
+		{SearchQuery{Kind: SearchPaths, Pattern: "docs/*.md"}, []string{"--full-history", "--all", "--", "docs/*.md"}},
+	}
+	for _, tc := range tests {
+		args := searchArgs(spec, tc.query)
+		if got := args[4:]; !slices.Equal(got, tc.want) {
+			t.Fatalf("searchArgs(%+v) = %v, want suffix %v", tc.query, args, tc.want)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitNative reads repositories directly from disk without a git executable.
+// It supports browsing history and commit diffs; operations that touch the
+// index or working tree report errors.ErrUnsupported.
+type gitNative struct {
+	path      string // working tree root
+	gitDir    string // per-worktree git directory (HEAD lives here)
+	commonDir string // shared git directory (objects and refs)
+	objects   *objectStore
+}
+
+func OpenNative(repoPath string) (Backend, error) {
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	root, gitDir, err := findGitDir(abs)
+	if err != nil {
+		return nil, fmt.Errorf("open repository: %w", err)
+	}
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		commonDir = filepath.Clean(commonDir)
+	}
+	if err := checkNativeRepoFormat(commonDir); err != nil {
+		return nil, fmt.Errorf("open repository: %w", err)
+	}
+	objects, err := openObjectStore(filepath.Join(commonDir, "objects"))
+	if err != nil {
+		return nil, fmt.Errorf("open repository: %w", err)
+	}
+	return &gitNative{path: root, gitDir: gitDir, commonDir: commonDir, objects: objects}, nil
+}
+
+// findGitDir walks up from dir to the working tree root holding a .git
+// directory or a "gitdir:" file, or to a git directory such as a bare
+// repository, which is its own root.
+func findGitDir(dir string) (root string, gitDir string, err error) {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			if info.IsDir() {
+				return dir, dotGit, nil
+			}
+			data, err := os.ReadFile(dotGit)
+			if err != nil {
+				return "", "", err
+			}
+			target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+			if !ok {
+				return "", "", fmt.Errorf("%s: invalid gitdir file", dotGit)
+			}
+			target = strings.TrimSpace(target)
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+			return dir, filepath.Clean(target), nil
+		}
+		if isGitDir(dir) {
+			return dir, dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("not a git repository (or any of the parent directories)")
+		}
+		dir = parent
+	}
+}
+
+// isGitDir reports whether dir has the HEAD, objects and refs of a git
+// directory.
+func isGitDir(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || info.IsDir() {
+		return false
+	}
+	for _, name := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// checkNativeRepoFormat rejects repositories using extensions this backend
+// cannot read, such as SHA-256 object names or reftable refs.
+func checkNativeRepoFormat(commonDir string) error {
+	data, err := os.ReadFile(filepath.Join(commonDir, "config"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	section := ""
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			section = strings.ToLower(strings.Trim(line, "[] \t"))
+			continue
+		}
+		if section != "extensions" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch {
+		case key == "objectformat" && value != "sha1":
+			return fmt.Errorf("object format %q is not supported by the native backend", value)
+		case key == "refstorage" && value != "files":
+			return fmt.Errorf("ref storage %q is not supported by the native backend", value)
+		}
+	}
+	return nil
+}
+
+func (g *gitNative) RepoPath() string {
+	if g == nil {
+		return ""
+	}
+	return g.path
+}
+
+func (g *gitNative) HeadState() (hash string, headName string, ok bool, err error) {
+	if g == nil || g.path == "" {
+		return "", "", false, fmt.Errorf("repository root not set")
+	}
+	target, symbolic, err := g.readHead()
+	if err != nil {
+		return "", "", false, err
+	}
+	id, found, err := g.resolveRef("HEAD")
+	if err != nil || !found {
+		return "", "", false, err
+	}
+	headName = "HEAD"
+	if symbolic {
+		headName = strings.TrimPrefix(target, "refs/heads/")
+	}
+	return id.String(), headName, true, nil
+}
+
+func (g *gitNative) ListRefs() ([]Ref, error) {
+	if g == nil || g.path == "" {
+		return nil, nil
+	}
+	names, err := g.refNames()
+	if err != nil {
+		return nil, err
+	}
+	var refs []Ref
+	for _, name := range names {
+		var kind RefKind
+		var short string
+		switch {
+		case strings.HasPrefix(name, "refs/heads/"):
+			kind, short = RefKindBranch, strings.TrimPrefix(name, "refs/heads/")
+		case strings.HasPrefix(name, "refs/remotes/"):
+			kind, short = RefKindRemoteBranch, strings.TrimPrefix(name, "refs/remotes/")
+		case strings.HasPrefix(name, "refs/tags/"):
+			kind, short = RefKindTag, strings.TrimPrefix(name, "refs/tags/")
+		default:
+			continue
+		}
+		id, found, err := g.resolveRef(name)
+		if err != nil {
+			return nil, err
+		}
+		if !found || short == "" {
+			continue
+		}
+		if kind == RefKindTag {
+			if id, err = g.peel(id); err != nil {
+				return nil, err
+			}
+		}
+		refs = append(refs, Ref{Hash: id.String(), Kind: kind, Name: short})
+	}
+	return refs, nil
+}
+
+// ListStashes reads the reflog of refs/stash, which lists the oldest entry
+// first.
+func (g *gitNative) ListStashes() ([]Stash, error) {
+	if g == nil || g.path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(g.commonDir, "logs", "refs", "stash"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read stash log: %w", err)
+	}
+	var lines []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	stashes := make([]Stash, 0, len(lines))
+	for i := len(lines) - 1; i >= 0; i-- {
+		header, message, _ := strings.Cut(lines[i], "\t")
+		fields := strings.Fields(header)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("unexpected stash log line: %q", lines[i])
+		}
+		stashes = append(stashes, Stash{
+			Hash:    fields[1],
+			Name:    fmt.Sprintf("stash@{%d}", len(stashes)),
+			Message: message,
+		})
+	}
+	return stashes, nil
+}
+
+func (g *gitNative) SwitchBranch(string) error {
+	return fmt.Errorf("switch branch: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) SwitchDetached(string) error {
+	return fmt.Errorf("switch detached: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) CreateTrackingBranch(string, string) error {
+	return fmt.Errorf("create tracking branch: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) CreateBranch(string, string, bool) error {
+	return fmt.Errorf("create branch: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) DeleteBranch(string, bool) error {
+	return fmt.Errorf("delete branch: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) RenameBranch(string, string) error {
+	return fmt.Errorf("rename branch: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) CreateTag(string, string, string) error {
+	return fmt.Errorf("create tag: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) DeleteTag(string) error {
+	return fmt.Errorf("delete tag: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) CherryPick(string, int) error {
+	return fmt.Errorf("cherry-pick: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) Revert(string, int) error {
+	return fmt.Errorf("revert: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) Reset(string, ResetMode) error {
+	return fmt.Errorf("reset: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) ResolveRevision(rev string) (string, error) {
+	if g == nil || g.path == "" {
+		return "", fmt.Errorf("repository root not set")
+	}
+	rev = strings.TrimSpace(rev)
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("bad revision %q", rev)
+	}
+	id, err := g.resolveRevision(rev)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// CountCommits walks the history reachable from revisions.
+func (g *gitNative) CountCommits(revisions []string) (int, error) {
+	w, err := g.newWalk(LogSpec{Revisions: revisions})
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list: %w", err)
+	}
+	count := 0
+	for {
+		_, err := w.next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("git rev-list: %w", err)
+		}
+		count++
+	}
+}
+
+// LogContains walks the history selected by spec until it reaches
+// commitHash.
+func (g *gitNative) LogContains(spec LogSpec, commitHash string) (bool, error) {
+	hash, err := g.ResolveRevision(commitHash)
+	if err != nil {
+		return false, err
+	}
+	w, err := g.newWalk(spec)
+	if err != nil {
+		return false, fmt.Errorf("git log: %w", err)
+	}
+	for {
+		commit, err := w.next()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("git log: %w", err)
+		}
+		if commit.Hash == hash {
+			return true, nil
+		}
+	}
+}
+
+func (g *gitNative) CombinedDiffText(string, DiffOptions) (string, error) {
+	return "", fmt.Errorf("combined diff: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) WorktreeDiffText(bool, DiffOptions) (string, error) {
+	return "", fmt.Errorf("worktree diff: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) LocalChangesStatus() (LocalChanges, error) {
+	return LocalChanges{}, fmt.Errorf("local changes: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) ApplyPatch(string, ApplyOptions) error {
+	return fmt.Errorf("apply patch: %w", errors.ErrUnsupported)
+}
+
+func (g *gitNative) StartBlame(string, string) (BlameStream, error) {
+	return nil, fmt.Errorf("blame: %w", errors.ErrUnsupported)
+}
@@ -0,0 +1,554 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/myers"
+)
+
+const (
+	diffContextLines = 3
+	// binaryCheckBytes matches how much of a blob git inspects for NUL bytes.
+	binaryCheckBytes = 8000
+	abbrevLen        = 7
+)
+
+// pathspec limits tree walks to literal paths (files or directories) and
+// glob patterns, relative to the repository root.
+type pathspec []pathspecItem
+
+type pathspecItem struct {
+	path string
+	// glob is set for patterns; like git, wildcards also match slashes.
+	glob *regexp.Regexp
+}
+
+func newPathspec(paths []string) pathspec {
+	var ps pathspec
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		p = path.Clean(strings.ReplaceAll(p, "\\", "/"))
+		if p == "." || p == "/" {
+			p = ""
+		}
+		item := pathspecItem{path: strings.TrimPrefix(p, "./")}
+		if strings.ContainsAny(item.path, "*?[") {
+			item.glob = globRegexp(item.path)
+		}
+		ps = append(ps, item)
+	}
+	return ps
+}
+
+func globRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return regexp.MustCompile("^" + regexp.QuoteMeta(glob) + "$")
+	}
+	return re
+}
+
+// matches reports whether the file at p is selected.
+func (ps pathspec) matches(p string) bool {
+	if len(ps) == 0 {
+		return true
+	}
+	for _, item := range ps {
+		if item.path == "" || p == item.path || strings.HasPrefix(p, item.path+"/") {
+			return true
+		}
+		if item.glob != nil && item.glob.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// mayContain reports whether the directory at dir can hold selected files.
+func (ps pathspec) mayContain(dir string) bool {
+	if len(ps) == 0 {
+		return true
+	}
+	for _, item := range ps {
+		if item.path == "" || item.glob != nil || dir == item.path ||
+			strings.HasPrefix(dir, item.path+"/") || strings.HasPrefix(item.path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// treeChange is a file added, removed or modified between two trees. A zero
+// mode means the file is absent on that side.
+type treeChange struct {
+	path    string
+	oldMode uint32
+	newMode uint32
+	oldID   objectID
+	newID   objectID
+}
+
+func (g *gitNative) readTree(id objectID) ([]treeEntry, error) {
+	if id == (objectID{}) {
+		return nil, nil
+	}
+	data, err := g.objects.readType(id, objectTree)
+	if err != nil {
+		return nil, err
+	}
+	return parseTreeObject(data)
+}
+
+// diffTrees lists the files that differ between trees a and b (either may be
+// zero for the empty tree), in the order git diff reports them.
+func (g *gitNative) diffTrees(a, b objectID, ps pathspec) ([]treeChange, error) {
+	var changes []treeChange
+	err := g.walkTreeDiff(a, b, "", ps, func(c treeChange) bool {
+		changes = append(changes, c)
+		return true
+	})
+	return changes, err
+}
+
+// treesDiffer reports whether any selected file differs between a and b.
+func (g *gitNative) treesDiffer(a, b objectID, ps pathspec) (bool, error) {
+	changed := false
+	err := g.walkTreeDiff(a, b, "", ps, func(treeChange) bool {
+		changed = true
+		return false
+	})
+	return changed, err
+}
+
+// walkTreeDiff calls fn for each differing file until it returns false.
+func (g *gitNative) walkTreeDiff(a, b objectID, prefix string, ps pathspec, fn func(treeChange) bool) error {
+	_, err := g.walkTreeDiffInner(a, b, prefix, ps, fn)
+	return err
+}
+
+func (g *gitNative) walkTreeDiffInner(
+	a, b objectID,
+	prefix string,
+	ps pathspec,
+	fn func(treeChange) bool,
+) (bool, error) {
+	if a == b {
+		return true, nil
+	}
+	oldEntries, err := g.readTree(a)
+	if err != nil {
+		return false, err
+	}
+	newEntries, err := g.readTree(b)
+	if err != nil {
+		return false, err
+	}
+	i, j := 0, 0
+	for i < len(oldEntries) || j < len(newEntries) {
+		var oldEntry, newEntry *treeEntry
+		switch {
+		case j >= len(newEntries):
+			oldEntry = &oldEntries[i]
+			i++
+		case i >= len(oldEntries):
+			newEntry = &newEntries[j]
+			j++
+		default:
+			switch cmp := strings.Compare(treeSortKey(oldEntries[i]), treeSortKey(newEntries[j])); {
+			case cmp < 0:
+				oldEntry = &oldEntries[i]
+				i++
+			case cmp > 0:
+				newEntry = &newEntries[j]
+				j++
+			default:
+				oldEntry = &oldEntries[i]
+				newEntry = &newEntries[j]
+				i++
+				j++
+			}
+		}
+		if oldEntry != nil && newEntry != nil && oldEntry.id == newEntry.id && oldEntry.mode == newEntry.mode {
+			continue
+		}
+		var name string
+		if oldEntry != nil {
+			name = oldEntry.name
+		} else {
+			name = newEntry.name
+		}
+		full := name
+		if prefix != "" {
+			full = prefix + "/" + name
+		}
+		// Entries paired by sort key are either both trees or both non-trees.
+		if (oldEntry != nil && oldEntry.isTree()) || (newEntry != nil && newEntry.isTree()) {
+			if !ps.mayContain(full) {
+				continue
+			}
+			var oldID, newID objectID
+			if oldEntry != nil {
+				oldID = oldEntry.id
+			}
+			if newEntry != nil {
+				newID = newEntry.id
+			}
+			more, err := g.walkTreeDiffInner(oldID, newID, full, ps, fn)
+			if err != nil || !more {
+				return more, err
+			}
+			continue
+		}
+		if !ps.matches(full) {
+			continue
+		}
+		change := treeChange{path: full}
+		if oldEntry != nil {
+			change.oldMode, change.oldID = oldEntry.mode, oldEntry.id
+		}
+		if newEntry != nil {
+			change.newMode, change.newID = newEntry.mode, newEntry.id
+		}
+		if !fn(change) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// treeSortKey orders entries like git trees do: directories sort as if their
+// name ended in a slash.
+func treeSortKey(e treeEntry) string {
+	if e.isTree() {
+		return e.name + "/"
+	}
+	return e.name
+}
+
+// nativeDiffContext returns the context lines asked by opts, or an error
+// for the options only git implements. Renames are never detected, so
+// turning their detection off is accepted.
+func nativeDiffContext(opts DiffOptions) (int, error) {
+	var unsupported string
+	switch {
+	case opts.Whitespace != WhitespaceShow, opts.IgnoreBlankLines:
+		unsupported = "ignoring whitespace"
+	case opts.RenameThreshold > 0, opts.CopyThreshold > 0:
+		unsupported = "rename and copy detection"
+	case opts.Algorithm != DiffAlgorithmDefault && opts.Algorithm != DiffAlgorithmMyers:
+		unsupported = fmt.Sprintf("the %s diff algorithm", opts.Algorithm)
+	}
+	if unsupported != "" {
+		return 0, fmt.Errorf("%s: %w", unsupported, errors.ErrUnsupported)
+	}
+	switch {
+	case opts.Context < 0:
+		return 0, nil
+	case opts.Context > 0:
+		return opts.Context, nil
+	default:
+		return diffContextLines, nil
+	}
+}
+
+func (g *gitNative) CommitDiffText(commitHash string, parentHash string, opts DiffOptions) (string, error) {
+	commitHash = strings.TrimSpace(commitHash)
+	parentHash = strings.TrimSpace(parentHash)
+	if commitHash == "" {
+		return "", fmt.Errorf("commit not specified")
+	}
+	context, err := nativeDiffContext(opts)
+	if err != nil {
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+	commitID, err := g.resolveRevision(commitHash)
+	if err != nil {
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+	commit, err := g.readCommit(commitID)
+	if err != nil {
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+	var parentTree objectID
+	if parentHash != "" {
+		parentID, err := g.resolveRevision(parentHash)
+		if err != nil {
+			return "", fmt.Errorf("git diff: %w", err)
+		}
+		parent, err := g.readCommit(parentID)
+		if err != nil {
+			return "", fmt.Errorf("git diff: %w", err)
+		}
+		parentTree = parent.tree
+	}
+	changes, err := g.diffTrees(parentTree, commit.tree, nil)
+	if err != nil {
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+	var b strings.Builder
+	for _, change := range changes {
+		if err := g.writeFilePatch(&b, change, context); err != nil {
+			return "", fmt.Errorf("git diff: %w", err)
+		}
+	}
+	return b.String(), nil
+}
+
+// writeFilePatch writes change as a "diff --git" section. Renames and copies
+// are not detected; they show up as a deletion and an addition.
+func (g *gitNative) writeFilePatch(b *strings.Builder, change treeChange, context int) error {
+	// A type change between a regular file and a symlink or submodule is
+	// shown by git as a deletion followed by an addition.
+	if change.oldMode != 0 && change.newMode != 0 && modeType(change.oldMode) != modeType(change.newMode) {
+		removed, added := change, change
+		removed.newMode, removed.newID = 0, objectID{}
+		added.oldMode, added.oldID = 0, objectID{}
+		if err := g.writeFilePatch(b, removed, context); err != nil {
+			return err
+		}
+		return g.writeFilePatch(b, added, context)
+	}
+	oldPath := quotePath("a/" + change.path)
+	newPath := quotePath("b/" + change.path)
+	fmt.Fprintf(b, "diff --git %s %s\n", oldPath, newPath)
+	switch {
+	case change.oldMode == 0:
+		fmt.Fprintf(b, "new file mode %06o\n", change.newMode)
+	case change.newMode == 0:
+		fmt.Fprintf(b, "deleted file mode %06o\n", change.oldMode)
+	case change.oldMode != change.newMode:
+		fmt.Fprintf(b, "old mode %06o\nnew mode %06o\n", change.oldMode, change.newMode)
+	}
+	if change.oldID == change.newID {
+		return nil
+	}
+	oldData, err := g.blobContent(change.oldMode, change.oldID)
+	if err != nil {
+		return err
+	}
+	newData, err := g.blobContent(change.newMode, change.newID)
+	if err != nil {
+		return err
+	}
+	index := fmt.Sprintf("index %s..%s", abbrevID(change.oldID), abbrevID(change.newID))
+	if change.oldMode == change.newMode {
+		index += fmt.Sprintf(" %06o", change.newMode)
+	}
+	b.WriteString(index + "\n")
+	if len(oldData) == 0 && len(newData) == 0 {
+		return nil
+	}
+	if change.oldMode == 0 {
+		oldPath = "/dev/null"
+	}
+	if change.newMode == 0 {
+		newPath = "/dev/null"
+	}
+	if isBinary(oldData) || isBinary(newData) {
+		fmt.Fprintf(b, "Binary files %s and %s differ\n", oldPath, newPath)
+		return nil
+	}
+	fmt.Fprintf(b, "--- %s\n+++ %s\n", oldPath, newPath)
+	writeUnifiedHunks(b, splitLines(oldData), splitLines(newData), context)
+	return nil
+}
+
+func modeType(mode uint32) uint32 {
+	return mode & 0o170000
+}
+
+// blobContent returns the diffable content of an entry. Submodules diff as
+// the commit they point at, like git does.
+func (g *gitNative) blobContent(mode uint32, id objectID) ([]byte, error) {
+	switch {
+	case mode == 0:
+		return nil, nil
+	case mode == modeGitlink:
+		return []byte("Subproject commit " + id.String() + "\n"), nil
+	default:
+		return g.objects.readType(id, objectBlob)
+	}
+}
+
+func abbrevID(id objectID) string {
+	return id.String()[:abbrevLen]
+}
+
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), binaryCheckBytes)], 0) >= 0
+}
+
+// splitLines splits data into lines that keep their trailing newline, so a
+// missing newline at end of file counts as a change.
+func splitLines(data []byte) []string {
+	var lines []string
+	for len(data) > 0 {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			lines = append(lines, string(data))
+			break
+		}
+		lines = append(lines, string(data[:idx+1]))
+		data = data[idx+1:]
+	}
+	return lines
+}
+
+// writeUnifiedHunks writes the hunks turning a into b with the given number
+// of context lines, merging hunks whose context would overlap.
+func writeUnifiedHunks(b *strings.Builder, a, bLines []string, context int) {
+	ops := myers.Diff(a, bLines)
+	var changes []myers.Op
+	for _, op := range ops {
+		if op.Kind != myers.Equal {
+			changes = append(changes, op)
+		}
+	}
+	for start := 0; start < len(changes); {
+		end := start + 1
+		for end < len(changes) && changes[end].AStart-changes[end-1].AEnd <= 2*context {
+			end++
+		}
+		first, last := changes[start], changes[end-1]
+		aStart := max(0, first.AStart-context)
+		aEnd := min(len(a), last.AEnd+context)
+		bStart := first.BStart - (first.AStart - aStart)
+		bEnd := last.BEnd + (aEnd - last.AEnd)
+		fmt.Fprintf(b, "@@ -%s +%s @@%s\n",
+			hunkRange(aStart, aEnd-aStart),
+			hunkRange(bStart, bEnd-bStart),
+			hunkFuncName(a, aStart))
+		ai, bi := aStart, bStart
+		for _, change := range changes[start:end] {
+			for ; ai < change.AStart; ai, bi = ai+1, bi+1 {
+				writeHunkLine(b, ' ', a[ai])
+			}
+			for ; ai < change.AEnd; ai++ {
+				writeHunkLine(b, '-', a[ai])
+			}
+			for ; bi < change.BEnd; bi++ {
+				writeHunkLine(b, '+', bLines[bi])
+			}
+		}
+		for ; ai < aEnd; ai++ {
+			writeHunkLine(b, ' ', a[ai])
+		}
+		start = end
+	}
+}
+
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return strconv.Itoa(start + 1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// hunkFuncName mimics git's default funcname heuristic: the closest line
+// before the hunk that starts with a letter, '_' or '$'.
+func hunkFuncName(lines []string, hunkStart int) string {
+	for i := hunkStart - 1; i >= 0; i-- {
+		line := lines[i]
+		if line == "" {
+			continue
+		}
+		c := line[0]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '$' {
+			line = strings.TrimRight(line[:min(len(line), 80)], " \t\r\n")
+			return " " + line
+		}
+	}
+	return ""
+}
+
+func writeHunkLine(b *strings.Builder, prefix byte, line string) {
+	b.WriteByte(prefix)
+	b.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		b.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// quotePath quotes p the way git does with core.quotePath enabled.
+func quotePath(p string) string {
+	needsQuote := false
+	for i := 0; i < len(p); i++ {
+		if c := p[i]; c < 0x20 || c >= 0x7f || c == '"' || c == '\\' {
+			needsQuote = true
+			break
+		}
+	}
+	if !needsQuote {
+		return p
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\v':
+			b.WriteString(`\v`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,475 @@
+package backend
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// nativeLogStream hands out the commits of a native history walk as it
+// goes.
+type nativeLogStream struct {
+	walk *nativeWalk
+}
+
+func (s *nativeLogStream) Next() (*Commit, error) {
+	if s.walk == nil {
+		return nil, io.EOF
+	}
+	commit, err := s.walk.next()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	return commit, err
+}
+
+func (s *nativeLogStream) Close() error {
+	s.walk = nil
+	return nil
+}
+
+// StartLogStream walks the history selected by spec newest first, reading
+// commits only as far as Next is called, like git log's priority queue. As
+// with git log without --date-order, a commit dated after one of its
+// children (clock skew) may come before it.
+func (g *gitNative) StartLogStream(spec LogSpec) (LogStream, error) {
+	w, err := g.newWalk(spec)
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	return &nativeLogStream{walk: w}, nil
+}
+
+// newWalk starts a walk of the history selected by spec.
+func (g *gitNative) newWalk(spec LogSpec) (*nativeWalk, error) {
+	if g == nil || g.path == "" {
+		return nil, fmt.Errorf("repository root not set")
+	}
+	if spec.Follow {
+		return nil, fmt.Errorf("follow: %w", errors.ErrUnsupported)
+	}
+	w := &nativeWalk{
+		g:     g,
+		paths: newPathspec(spec.Paths),
+		nodes: map[objectID]*walkNode{},
+	}
+	if err := w.parseRevisions(spec.Revisions); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// nativeWalk visits commits newest first by committer date. Commits
+// reachable from negative revisions ("^A", "A..B") go through the same queue
+// marked as excluded, so that they are known before the older commits they
+// hide come up, and the walk ends once only excluded commits are left.
+type nativeWalk struct {
+	g     *gitNative
+	paths pathspec
+	nodes map[objectID]*walkNode
+	queue walkQueue
+	// interesting counts the queued commits that are not excluded.
+	interesting int
+	seq         int
+}
+
+func (w *nativeWalk) parseRevisions(revisions []string) error {
+	if len(revisions) == 0 {
+		revisions = []string{"HEAD"}
+	}
+	negate := false
+	var tips, hide []objectID
+	for _, rev := range revisions {
+		switch rev {
+		case "--not":
+			negate = !negate
+			continue
+		case "--all", "--branches", "--tags", "--remotes":
+			ids, err := w.refTips(rev)
+			if err != nil {
+				return err
+			}
+			if negate {
+				hide = append(hide, ids...)
+			} else {
+				tips = append(tips, ids...)
+			}
+			continue
+		}
+		if strings.HasPrefix(rev, "-") {
+			return fmt.Errorf("option %q: %w", rev, errors.ErrUnsupported)
+		}
+		if left, right, ok := strings.Cut(rev, "..."); ok {
+			a, err := w.g.resolveRevision(left)
+			if err != nil {
+				return err
+			}
+			b, err := w.g.resolveRevision(right)
+			if err != nil {
+				return err
+			}
+			bases, err := w.g.mergeBases(a, b)
+			if err != nil {
+				return err
+			}
+			hide = append(hide, bases...)
+			tips = append(tips, a, b)
+			continue
+		}
+		if left, right, ok := strings.Cut(rev, ".."); ok {
+			a, err := w.g.resolveRevision(left)
+			if err != nil {
+				return err
+			}
+			b, err := w.g.resolveRevision(right)
+			if err != nil {
+				return err
+			}
+			hide = append(hide, a)
+			tips = append(tips, b)
+			continue
+		}
+		exclude := negate
+		if name, ok := strings.CutPrefix(rev, "^"); ok {
+			rev = name
+			exclude = !exclude
+		}
+		id, err := w.g.resolveRevision(rev)
+		if err != nil {
+			return err
+		}
+		if exclude {
+			hide = append(hide, id)
+		} else {
+			tips = append(tips, id)
+		}
+	}
+	for _, id := range hide {
+		if err := w.push(id, true); err != nil {
+			return err
+		}
+	}
+	for _, id := range tips {
+		if err := w.push(id, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refTips resolves the commits named by --all, --branches, --tags or --remotes.
+func (w *nativeWalk) refTips(option string) ([]objectID, error) {
+	names, err := w.g.refNames()
+	if err != nil {
+		return nil, err
+	}
+	prefix := map[string]string{
+		"--all":      "refs/",
+		"--branches": "refs/heads/",
+		"--tags":     "refs/tags/",
+		"--remotes":  "refs/remotes/",
+	}[option]
+	var ids []objectID
+	if option == "--all" {
+		if id, found, err := w.g.resolveRef("HEAD"); err != nil {
+			return nil, err
+		} else if found {
+			ids = append(ids, id)
+		}
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		id, found, err := w.g.resolveRef(name)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		id, err = w.g.peel(id)
+		if err != nil {
+			return nil, err
+		}
+		// Tags may point at trees or blobs; only commits start a walk.
+		if typ, _, err := w.g.objects.read(id); err != nil || typ != objectCommit {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// walkNode is the per-commit state of a walk.
+type walkNode struct {
+	commit *nativeCommit
+	// parents are the parents kept by simplification: all of them for commits
+	// that touch the paths, the first TREESAME parent otherwise.
+	parents    []objectID
+	shown      bool
+	simplified bool
+	// excluded marks commits reachable from a negative revision.
+	excluded bool
+	queued   bool
+	visited  bool
+	seq      int
+	// index is the position in walkQueue while queued.
+	index int
+}
+
+func (w *nativeWalk) node(id objectID) (*walkNode, error) {
+	if n, ok := w.nodes[id]; ok {
+		return n, nil
+	}
+	c, err := w.g.readCommit(id)
+	if err != nil {
+		return nil, err
+	}
+	n := &walkNode{commit: c, parents: c.parents, shown: true}
+	w.nodes[id] = n
+	return n, nil
+}
+
+// push queues the commit id, or marks it as excluded when it is reached from
+// a negative revision after being queued or visited.
+func (w *nativeWalk) push(id objectID, excluded bool) error {
+	n, err := w.node(id)
+	if err != nil {
+		return err
+	}
+	switch {
+	case n.queued:
+		if excluded && !n.excluded {
+			n.excluded = true
+			w.interesting--
+			heap.Fix(&w.queue, n.index)
+		}
+		return nil
+	case n.visited:
+		if excluded && !n.excluded {
+			// Only clock skew gets here; keep its parents out at least.
+			n.excluded = true
+			for _, parent := range n.commit.parents {
+				if err := w.push(parent, true); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	n.excluded = excluded
+	n.queued = true
+	n.seq = w.seq
+	w.seq++
+	if !excluded {
+		w.interesting++
+	}
+	heap.Push(&w.queue, n)
+	return nil
+}
+
+// next returns the next commit of the walk, or io.EOF.
+func (w *nativeWalk) next() (*Commit, error) {
+	for w.interesting > 0 {
+		n := heap.Pop(&w.queue).(*walkNode)
+		n.queued = false
+		n.visited = true
+		if n.excluded {
+			for _, parent := range n.commit.parents {
+				if err := w.push(parent, true); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		w.interesting--
+		if err := w.simplify(n); err != nil {
+			return nil, err
+		}
+		for _, parent := range n.parents {
+			if err := w.push(parent, false); err != nil {
+				return nil, err
+			}
+		}
+		if !n.shown {
+			continue
+		}
+		commit := n.commit.commit
+		if len(w.paths) > 0 {
+			parents, err := w.rewriteParents(n)
+			if err != nil {
+				return nil, err
+			}
+			copied := *commit
+			copied.ParentHashes = nil
+			for _, parent := range parents {
+				copied.ParentHashes = append(copied.ParentHashes, parent.String())
+			}
+			commit = &copied
+		}
+		return commit, nil
+	}
+	return nil, io.EOF
+}
+
+// simplify applies git's default history simplification for path-limited
+// walks: a commit is shown only if it differs from every parent at the
+// paths, and a merge TREESAME to one parent only follows that parent.
+func (w *nativeWalk) simplify(n *walkNode) error {
+	if n.simplified || len(w.paths) == 0 {
+		return nil
+	}
+	n.simplified = true
+	c := n.commit
+	if len(c.parents) == 0 {
+		changed, err := w.g.treesDiffer(objectID{}, c.tree, w.paths)
+		n.shown = changed
+		return err
+	}
+	for _, parentID := range c.parents {
+		parent, err := w.node(parentID)
+		if err != nil {
+			return err
+		}
+		changed, err := w.g.treesDiffer(parent.commit.tree, c.tree, w.paths)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			n.parents = []objectID{parentID}
+			n.shown = false
+			return nil
+		}
+	}
+	return nil
+}
+
+// rewriteParents replaces each parent of a shown commit by its nearest shown
+// ancestor along the simplified history, like git log --parents does for
+// path-limited walks. Excluded parents are kept as boundaries.
+func (w *nativeWalk) rewriteParents(n *walkNode) ([]objectID, error) {
+	var parents []objectID
+	for _, id := range n.parents {
+		for {
+			p, err := w.node(id)
+			if err != nil {
+				return nil, err
+			}
+			if p.excluded {
+				break
+			}
+			if err := w.simplify(p); err != nil {
+				return nil, err
+			}
+			if p.shown {
+				break
+			}
+			if len(p.parents) == 0 {
+				id = objectID{}
+				break
+			}
+			id = p.parents[0]
+		}
+		if id == (objectID{}) {
+			continue
+		}
+		if !slices.Contains(parents, id) {
+			parents = append(parents, id)
+		}
+	}
+	return parents, nil
+}
+
+// mergeBases returns the common ancestors of a and b found first walking
+// down from both, which together with their history are the commits "a...b"
+// excludes.
+func (g *gitNative) mergeBases(a, b objectID) ([]objectID, error) {
+	if a == b {
+		return []objectID{a}, nil
+	}
+	const (
+		fromA = 1 << iota
+		fromB
+		stale
+	)
+	flags := map[objectID]uint8{}
+	var queue walkQueue
+	seq := 0
+	push := func(id objectID, f uint8) error {
+		c, err := g.readCommit(id)
+		if err != nil {
+			return err
+		}
+		flags[id] |= f
+		heap.Push(&queue, &walkNode{commit: c, seq: seq})
+		seq++
+		return nil
+	}
+	if err := push(a, fromA); err != nil {
+		return nil, err
+	}
+	if err := push(b, fromB); err != nil {
+		return nil, err
+	}
+	var bases []objectID
+	for slices.ContainsFunc(queue, func(n *walkNode) bool { return flags[n.commit.id]&stale == 0 }) {
+		n := heap.Pop(&queue).(*walkNode)
+		f := flags[n.commit.id]
+		if f&(fromA|fromB) == fromA|fromB && f&stale == 0 {
+			bases = append(bases, n.commit.id)
+			f |= stale
+			flags[n.commit.id] = f
+		}
+		for _, parent := range n.commit.parents {
+			if flags[parent]&f == f {
+				continue
+			}
+			if err := push(parent, f); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return bases, nil
+}
+
+// walkQueue orders commits by committer date, newest first. Among commits
+// with the same date excluded ones come first, so that they hide their
+// ancestors in time, then the order in which the walk found them.
+type walkQueue []*walkNode
+
+func (q walkQueue) Len() int { return len(q) }
+
+func (q walkQueue) Less(i, j int) bool {
+	ti := q[i].commit.commit.Committer.When
+	tj := q[j].commit.commit.Committer.When
+	if !ti.Equal(tj) {
+		return ti.After(tj)
+	}
+	if q[i].excluded != q[j].excluded {
+		return q[i].excluded
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q walkQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *walkQueue) Push(x any) {
+	n := x.(*walkNode)
+	n.index = len(*q)
+	*q = append(*q, n)
+}
+
+func (q *walkQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
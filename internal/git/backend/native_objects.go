@@ -0,0 +1,313 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type objectType uint8
+
+const (
+	objectCommit objectType = 1
+	objectTree   objectType = 2
+	objectBlob   objectType = 3
+	objectTag    objectType = 4
+)
+
+func (t objectType) String() string {
+	switch t {
+	case objectCommit:
+		return "commit"
+	case objectTree:
+		return "tree"
+	case objectBlob:
+		return "blob"
+	case objectTag:
+		return "tag"
+	default:
+		return fmt.Sprintf("object(%d)", uint8(t))
+	}
+}
+
+func parseObjectType(name string) (objectType, error) {
+	switch name {
+	case "commit":
+		return objectCommit, nil
+	case "tree":
+		return objectTree, nil
+	case "blob":
+		return objectBlob, nil
+	case "tag":
+		return objectTag, nil
+	default:
+		return 0, fmt.Errorf("unknown object type %q", name)
+	}
+}
+
+// objectID is a binary SHA-1 object name.
+type objectID [20]byte
+
+func parseObjectID(s string) (objectID, error) {
+	var id objectID
+	if len(s) != 2*len(id) {
+		return id, fmt.Errorf("invalid object id %q", s)
+	}
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return id, fmt.Errorf("invalid object id %q", s)
+	}
+	return id, nil
+}
+
+func (id objectID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+var errObjectNotFound = errors.New("object not found")
+
+// objectCacheLimit bounds the number of decoded trees and commits kept around
+// while walking history or diffing.
+const objectCacheLimit = 4096
+
+// objectStore reads objects from a repository's loose object directories and
+// packfiles, including those of alternate object stores.
+type objectStore struct {
+	dirs []string
+
+	mu    sync.Mutex
+	packs []*packFile
+	cache map[objectID]cachedObject
+}
+
+type cachedObject struct {
+	typ  objectType
+	data []byte
+}
+
+func openObjectStore(objectsDir string) (*objectStore, error) {
+	s := &objectStore{cache: make(map[objectID]cachedObject)}
+	seen := map[string]bool{}
+	var visit func(dir string, depth int) error
+	visit = func(dir string, depth int) error {
+		if seen[dir] || depth > 5 {
+			return nil
+		}
+		seen[dir] = true
+		s.dirs = append(s.dirs, dir)
+		packs, err := filepath.Glob(filepath.Join(dir, "pack", "pack-*.idx"))
+		if err != nil {
+			return err
+		}
+		for _, idxPath := range packs {
+			pack, err := openPackFile(idxPath)
+			if err != nil {
+				return err
+			}
+			s.packs = append(s.packs, pack)
+		}
+		alternates, err := os.ReadFile(filepath.Join(dir, "info", "alternates"))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for line := range strings.SplitSeq(string(alternates), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if !filepath.IsAbs(line) {
+				line = filepath.Join(dir, line)
+			}
+			if err := visit(filepath.Clean(line), depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(objectsDir, 0); err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *objectStore) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pack := range s.packs {
+		pack.close()
+	}
+	s.packs = nil
+	s.cache = nil
+}
+
+// read returns the type and contents of the object named id.
+func (s *objectStore) read(id objectID) (objectType, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if obj, ok := s.cache[id]; ok {
+		return obj.typ, obj.data, nil
+	}
+	typ, data, err := s.readUncached(id)
+	if err != nil {
+		return 0, nil, err
+	}
+	if typ != objectBlob {
+		if len(s.cache) >= objectCacheLimit {
+			clear(s.cache)
+		}
+		s.cache[id] = cachedObject{typ: typ, data: data}
+	}
+	return typ, data, nil
+}
+
+// readType reads the object named id and checks that it has the wanted type.
+func (s *objectStore) readType(id objectID, want objectType) ([]byte, error) {
+	typ, data, err := s.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if typ != want {
+		return nil, fmt.Errorf("object %s is a %s, not a %s", id, typ, want)
+	}
+	return data, nil
+}
+
+func (s *objectStore) readUncached(id objectID) (objectType, []byte, error) {
+	for _, pack := range s.packs {
+		if offset, ok := pack.find(id); ok {
+			return pack.readAt(offset, s.readUncached)
+		}
+	}
+	hexID := id.String()
+	for _, dir := range s.dirs {
+		typ, data, err := readLooseObject(filepath.Join(dir, hexID[:2], hexID[2:]))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("read object %s: %w", hexID, err)
+		}
+		return typ, data, nil
+	}
+	// Objects can move into new packs while we are running (gc, fetch).
+	if s.rescanPacks() {
+		return s.readUncached(id)
+	}
+	return 0, nil, fmt.Errorf("%w: %s", errObjectNotFound, hexID)
+}
+
+// rescanPacks opens packfiles created since the store was opened and reports
+// whether any were found.
+func (s *objectStore) rescanPacks() bool {
+	found := false
+	for _, dir := range s.dirs {
+		idxPaths, err := filepath.Glob(filepath.Join(dir, "pack", "pack-*.idx"))
+		if err != nil {
+			continue
+		}
+		for _, idxPath := range idxPaths {
+			if slices.ContainsFunc(s.packs, func(p *packFile) bool { return p.idxPath == idxPath }) {
+				continue
+			}
+			pack, err := openPackFile(idxPath)
+			if err != nil {
+				continue
+			}
+			s.packs = append(s.packs, pack)
+			found = true
+		}
+	}
+	return found
+}
+
+// resolvePrefix expands an abbreviated hex object name to the unique object
+// it names.
+func (s *objectStore) resolvePrefix(prefix string) (objectID, error) {
+	var zero objectID
+	prefix = strings.ToLower(prefix)
+	if len(prefix) < 4 || len(prefix) > 40 {
+		return zero, fmt.Errorf("%w: %s", errObjectNotFound, prefix)
+	}
+	if _, err := strconv.ParseUint(prefix[:min(len(prefix), 16)], 16, 64); err != nil {
+		return zero, fmt.Errorf("%w: %s", errObjectNotFound, prefix)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matches := map[objectID]struct{}{}
+	for _, pack := range s.packs {
+		for _, id := range pack.findPrefix(prefix) {
+			matches[id] = struct{}{}
+		}
+	}
+	for _, dir := range s.dirs {
+		entries, err := os.ReadDir(filepath.Join(dir, prefix[:2]))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := prefix[:2] + entry.Name()
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			if id, err := parseObjectID(name); err == nil {
+				matches[id] = struct{}{}
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return zero, fmt.Errorf("%w: %s", errObjectNotFound, prefix)
+	case 1:
+		for id := range matches {
+			return id, nil
+		}
+	}
+	return zero, fmt.Errorf("short object id %s is ambiguous", prefix)
+}
+
+func readLooseObject(path string) (objectType, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+	zr, err := zlib.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, err
+	}
+	header, data, ok := bytes.Cut(raw, []byte{0})
+	if !ok {
+		return 0, nil, fmt.Errorf("malformed loose object header")
+	}
+	typeName, sizeStr, ok := strings.Cut(string(header), " ")
+	if !ok {
+		return 0, nil, fmt.Errorf("malformed loose object header %q", header)
+	}
+	typ, err := parseObjectType(typeName)
+	if err != nil {
+		return 0, nil, err
+	}
+	size, err := strconv.Atoi(sizeStr)
+	if err != nil || size != len(data) {
+		return 0, nil, fmt.Errorf("loose object size mismatch")
+	}
+	return typ, data, nil
+}
@@ -0,0 +1,342 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	packObjectOfsDelta = 6
+	packObjectRefDelta = 7
+
+	// maxDeltaChain guards against corrupt packs with cyclic deltas.
+	maxDeltaChain = 10000
+	// deltaBaseCacheLimit bounds the number of delta bases kept per pack.
+	deltaBaseCacheLimit = 256
+)
+
+// packFile is a packfile together with its version 2 index.
+type packFile struct {
+	idxPath string
+	pack    *os.File
+
+	fanout   [256]uint32
+	names    []byte // count * 20 bytes, sorted
+	offsets  []byte // count * 4 bytes
+	offsets8 []byte // large offsets
+
+	bases map[int64]cachedObject
+}
+
+func openPackFile(idxPath string) (*packFile, error) {
+	idx, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+	p := &packFile{idxPath: idxPath, bases: make(map[int64]cachedObject)}
+	if err := p.parseIndex(idx); err != nil {
+		return nil, fmt.Errorf("%s: %w", idxPath, err)
+	}
+	pack, err := os.Open(strings.TrimSuffix(idxPath, ".idx") + ".pack")
+	if err != nil {
+		return nil, err
+	}
+	var header [12]byte
+	if _, err := pack.ReadAt(header[:], 0); err != nil {
+		pack.Close()
+		return nil, fmt.Errorf("read pack header: %w", err)
+	}
+	if string(header[:4]) != "PACK" {
+		pack.Close()
+		return nil, fmt.Errorf("%s: not a packfile", pack.Name())
+	}
+	p.pack = pack
+	return p, nil
+}
+
+func (p *packFile) parseIndex(idx []byte) error {
+	const headerLen = 8 + 256*4
+	if len(idx) < headerLen || !bytes.Equal(idx[:4], []byte{0xff, 't', 'O', 'c'}) {
+		return fmt.Errorf("unsupported pack index format")
+	}
+	if version := binary.BigEndian.Uint32(idx[4:8]); version != 2 {
+		return fmt.Errorf("unsupported pack index version %d", version)
+	}
+	for i := range p.fanout {
+		p.fanout[i] = binary.BigEndian.Uint32(idx[8+4*i:])
+	}
+	count := int(p.fanout[255])
+	namesStart := headerLen
+	crcStart := namesStart + 20*count
+	offsetsStart := crcStart + 4*count
+	offsets8Start := offsetsStart + 4*count
+	if len(idx) < offsets8Start+40 {
+		return fmt.Errorf("truncated pack index")
+	}
+	p.names = idx[namesStart:crcStart]
+	p.offsets = idx[offsetsStart:offsets8Start]
+	// The index ends with the pack and index checksums.
+	p.offsets8 = idx[offsets8Start : len(idx)-40]
+	return nil
+}
+
+func (p *packFile) close() {
+	if p.pack != nil {
+		p.pack.Close()
+	}
+}
+
+func (p *packFile) name(i int) []byte {
+	return p.names[20*i : 20*i+20]
+}
+
+func (p *packFile) find(id objectID) (int64, bool) {
+	lo := 0
+	if id[0] > 0 {
+		lo = int(p.fanout[id[0]-1])
+	}
+	hi := int(p.fanout[id[0]])
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(p.name(lo+i), id[:]) >= 0
+	})
+	if i >= hi || !bytes.Equal(p.name(i), id[:]) {
+		return 0, false
+	}
+	return p.offset(i), true
+}
+
+func (p *packFile) offset(i int) int64 {
+	off := binary.BigEndian.Uint32(p.offsets[4*i:])
+	if off&0x80000000 == 0 {
+		return int64(off)
+	}
+	pos := int(off&0x7fffffff) * 8
+	if pos+8 > len(p.offsets8) {
+		return -1
+	}
+	return int64(binary.BigEndian.Uint64(p.offsets8[pos:]))
+}
+
+func (p *packFile) findPrefix(prefix string) []objectID {
+	first, err := hex.DecodeString(prefix[:2])
+	if err != nil {
+		return nil
+	}
+	lo := 0
+	if first[0] > 0 {
+		lo = int(p.fanout[first[0]-1])
+	}
+	hi := int(p.fanout[first[0]])
+	var ids []objectID
+	for i := lo; i < hi; i++ {
+		name := hex.EncodeToString(p.name(i))
+		if name < prefix {
+			continue
+		}
+		if !strings.HasPrefix(name, prefix) {
+			break
+		}
+		var id objectID
+		copy(id[:], p.name(i))
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// readAt decodes the object stored at offset, resolving deltas. readBase is
+// used to look up the bases of REF_DELTA objects, which may live elsewhere.
+func (p *packFile) readAt(
+	offset int64,
+	readBase func(objectID) (objectType, []byte, error),
+) (objectType, []byte, error) {
+	var deltas [][]byte
+	var typ objectType
+	var data []byte
+	// baseAt is the offset of a delta base read from this pack, worth caching
+	// since sibling deltas usually share it.
+	baseAt := int64(-1)
+	for {
+		if len(deltas) > maxDeltaChain {
+			return 0, nil, fmt.Errorf("delta chain too long in %s", p.pack.Name())
+		}
+		if base, ok := p.bases[offset]; ok {
+			typ, data = base.typ, base.data
+			break
+		}
+		entry, err := p.readEntry(offset)
+		if err != nil {
+			return 0, nil, fmt.Errorf("%s at %d: %w", p.pack.Name(), offset, err)
+		}
+		if entry.typ != packObjectOfsDelta && entry.typ != packObjectRefDelta {
+			typ, data = objectType(entry.typ), entry.data
+			baseAt = offset
+			break
+		}
+		deltas = append(deltas, entry.data)
+		if entry.typ == packObjectOfsDelta {
+			offset = entry.baseOffset
+			continue
+		}
+		typ, data, err = readBase(entry.baseID)
+		if err != nil {
+			return 0, nil, err
+		}
+		break
+	}
+	if len(deltas) > 0 && baseAt >= 0 {
+		p.cacheBase(baseAt, typ, data)
+	}
+	for i := len(deltas) - 1; i >= 0; i-- {
+		var err error
+		data, err = applyDelta(data, deltas[i])
+		if err != nil {
+			return 0, nil, fmt.Errorf("%s: %w", p.pack.Name(), err)
+		}
+	}
+	return typ, data, nil
+}
+
+func (p *packFile) cacheBase(offset int64, typ objectType, data []byte) {
+	if len(p.bases) >= deltaBaseCacheLimit {
+		clear(p.bases)
+	}
+	p.bases[offset] = cachedObject{typ: typ, data: data}
+}
+
+type packEntry struct {
+	typ        uint8
+	data       []byte
+	baseOffset int64
+	baseID     objectID
+}
+
+func (p *packFile) readEntry(offset int64) (packEntry, error) {
+	var entry packEntry
+	r := bufio.NewReader(io.NewSectionReader(p.pack, offset, 1<<62))
+	c, err := r.ReadByte()
+	if err != nil {
+		return entry, err
+	}
+	entry.typ = (c >> 4) & 0x7
+	size := uint64(c & 0x0f)
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = r.ReadByte(); err != nil {
+			return entry, err
+		}
+		size |= uint64(c&0x7f) << shift
+	}
+	switch entry.typ {
+	case packObjectOfsDelta:
+		c, err := r.ReadByte()
+		if err != nil {
+			return entry, err
+		}
+		rel := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return entry, err
+			}
+			rel = ((rel + 1) << 7) | int64(c&0x7f)
+		}
+		if rel <= 0 || rel > offset {
+			return entry, fmt.Errorf("invalid delta base offset")
+		}
+		entry.baseOffset = offset - rel
+	case packObjectRefDelta:
+		if _, err := io.ReadFull(r, entry.baseID[:]); err != nil {
+			return entry, err
+		}
+	case uint8(objectCommit), uint8(objectTree), uint8(objectBlob), uint8(objectTag):
+	default:
+		return entry, fmt.Errorf("unknown pack object type %d", entry.typ)
+	}
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return entry, err
+	}
+	defer zr.Close()
+	entry.data = make([]byte, size)
+	if _, err := io.ReadFull(zr, entry.data); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+func applyDelta(base, delta []byte) ([]byte, error) {
+	readSize := func() (uint64, bool) {
+		var size uint64
+		for shift := 0; len(delta) > 0; shift += 7 {
+			c := delta[0]
+			delta = delta[1:]
+			size |= uint64(c&0x7f) << shift
+			if c&0x80 == 0 {
+				return size, true
+			}
+		}
+		return 0, false
+	}
+	srcSize, ok := readSize()
+	if !ok || srcSize != uint64(len(base)) {
+		return nil, fmt.Errorf("delta base size mismatch")
+	}
+	dstSize, ok := readSize()
+	if !ok {
+		return nil, fmt.Errorf("truncated delta")
+	}
+	out := make([]byte, 0, dstSize)
+	for len(delta) > 0 {
+		cmd := delta[0]
+		delta = delta[1:]
+		switch {
+		case cmd&0x80 != 0:
+			var off, size uint64
+			for i := range 4 {
+				if cmd&(1<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, fmt.Errorf("truncated delta copy")
+				}
+				off |= uint64(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+			for i := range 3 {
+				if cmd&(0x10<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, fmt.Errorf("truncated delta copy")
+				}
+				size |= uint64(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if off+size > uint64(len(base)) {
+				return nil, fmt.Errorf("delta copy out of range")
+			}
+			out = append(out, base[off:off+size]...)
+		case cmd != 0:
+			if int(cmd) > len(delta) {
+				return nil, fmt.Errorf("truncated delta insert")
+			}
+			out = append(out, delta[:cmd]...)
+			delta = delta[cmd:]
+		default:
+			return nil, fmt.Errorf("invalid delta opcode")
+		}
+	}
+	if uint64(len(out)) != dstSize {
+		return nil, fmt.Errorf("delta result size mismatch")
+	}
+	return out, nil
+}
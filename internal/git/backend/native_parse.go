@@ -0,0 +1,147 @@
+package backend
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nativeCommit is a parsed commit object.
+type nativeCommit struct {
+	id      objectID
+	tree    objectID
+	parents []objectID
+	commit  *Commit
+}
+
+func parseCommitObject(id objectID, data []byte) (*nativeCommit, error) {
+	nc := &nativeCommit{id: id, commit: &Commit{Hash: id.String()}}
+	rest := data
+	for len(rest) > 0 {
+		line, tail, _ := bytes.Cut(rest, []byte{'\n'})
+		rest = tail
+		if len(line) == 0 {
+			nc.commit.Message = string(rest)
+			break
+		}
+		key, value, _ := bytes.Cut(line, []byte{' '})
+		switch string(key) {
+		case "tree":
+			tree, err := parseObjectID(string(value))
+			if err != nil {
+				return nil, fmt.Errorf("commit %s: %w", id, err)
+			}
+			nc.tree = tree
+		case "parent":
+			parent, err := parseObjectID(string(value))
+			if err != nil {
+				return nil, fmt.Errorf("commit %s: %w", id, err)
+			}
+			nc.parents = append(nc.parents, parent)
+			nc.commit.ParentHashes = append(nc.commit.ParentHashes, parent.String())
+		case "author":
+			nc.commit.Author = parseSignature(string(value))
+		case "committer":
+			nc.commit.Committer = parseSignature(string(value))
+		default:
+			// Skip other headers (encoding, gpgsig, mergetag) and their
+			// space-prefixed continuation lines.
+		}
+	}
+	return nc, nil
+}
+
+// parseSignature parses "Name <email> 1700000000 +0100".
+func parseSignature(s string) Signature {
+	var sig Signature
+	lt := strings.IndexByte(s, '<')
+	gt := strings.LastIndexByte(s, '>')
+	if lt < 0 || gt < lt {
+		sig.Name = strings.TrimSpace(s)
+		return sig
+	}
+	sig.Name = strings.TrimSpace(s[:lt])
+	sig.Email = s[lt+1 : gt]
+	fields := strings.Fields(s[gt+1:])
+	if len(fields) == 0 {
+		return sig
+	}
+	secs, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return sig
+	}
+	loc := time.UTC
+	if len(fields) > 1 && len(fields[1]) == 5 {
+		tz := fields[1]
+		hours, errH := strconv.Atoi(tz[1:3])
+		mins, errM := strconv.Atoi(tz[3:5])
+		if errH == nil && errM == nil {
+			offset := hours*3600 + mins*60
+			if tz[0] == '-' {
+				offset = -offset
+			}
+			if offset != 0 {
+				loc = time.FixedZone("", offset)
+			}
+		}
+	}
+	sig.When = time.Unix(secs, 0).In(loc)
+	return sig
+}
+
+const (
+	modeTree    = 0o040000
+	modeBlob    = 0o100644
+	modeExec    = 0o100755
+	modeSymlink = 0o120000
+	modeGitlink = 0o160000
+)
+
+type treeEntry struct {
+	name string
+	mode uint32
+	id   objectID
+}
+
+func (e treeEntry) isTree() bool {
+	return e.mode == modeTree
+}
+
+func parseTreeObject(data []byte) ([]treeEntry, error) {
+	var entries []treeEntry
+	for len(data) > 0 {
+		sp := bytes.IndexByte(data, ' ')
+		if sp < 0 {
+			return nil, fmt.Errorf("malformed tree entry")
+		}
+		mode, err := strconv.ParseUint(string(data[:sp]), 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("malformed tree entry mode: %w", err)
+		}
+		data = data[sp+1:]
+		nul := bytes.IndexByte(data, 0)
+		if nul < 0 || len(data) < nul+1+20 {
+			return nil, fmt.Errorf("malformed tree entry")
+		}
+		entry := treeEntry{name: string(data[:nul]), mode: uint32(mode)}
+		copy(entry.id[:], data[nul+1:nul+21])
+		entries = append(entries, entry)
+		data = data[nul+21:]
+	}
+	return entries, nil
+}
+
+// parseTagObject returns the object an annotated tag points at.
+func parseTagObject(data []byte) (objectID, error) {
+	for line := range bytes.SplitSeq(data, []byte{'\n'}) {
+		if len(line) == 0 {
+			break
+		}
+		if target, ok := bytes.CutPrefix(line, []byte("object ")); ok {
+			return parseObjectID(string(target))
+		}
+	}
+	return objectID{}, fmt.Errorf("tag object without target")
+}
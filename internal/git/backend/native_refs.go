@@ -0,0 +1,329 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxSymrefDepth matches git's limit on chains of symbolic refs.
+const maxSymrefDepth = 5
+
+// readHead returns the target of HEAD and whether it is a symbolic ref.
+func (g *gitNative) readHead() (target string, symbolic bool, err error) {
+	data, err := os.ReadFile(filepath.Join(g.gitDir, "HEAD"))
+	if err != nil {
+		return "", false, fmt.Errorf("read HEAD: %w", err)
+	}
+	line := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(line, "ref:"); ok {
+		return strings.TrimSpace(ref), true, nil
+	}
+	return line, false, nil
+}
+
+// refDir returns the git directory holding name: pseudo refs such as HEAD are
+// per worktree, everything under refs/ is shared.
+func (g *gitNative) refDir(name string) string {
+	if strings.HasPrefix(name, "refs/") {
+		return g.commonDir
+	}
+	return g.gitDir
+}
+
+// resolveRef follows name, which may be symbolic, to an object id. found is
+// false when the ref (or the branch HEAD points to) does not exist.
+func (g *gitNative) resolveRef(name string) (id objectID, found bool, err error) {
+	var packed map[string]objectID
+	for range maxSymrefDepth {
+		data, err := os.ReadFile(filepath.Join(g.refDir(name), filepath.FromSlash(name)))
+		switch {
+		case err == nil:
+			line := strings.TrimSpace(string(data))
+			if target, ok := strings.CutPrefix(line, "ref:"); ok {
+				name = strings.TrimSpace(target)
+				continue
+			}
+			// Files such as FETCH_HEAD carry extra fields after the hash.
+			if len(line) > 40 {
+				line = line[:40]
+			}
+			id, err := parseObjectID(line)
+			if err != nil {
+				return id, false, fmt.Errorf("ref %s: %w", name, err)
+			}
+			return id, true, nil
+		case errors.Is(err, fs.ErrNotExist), isDirError(err):
+		default:
+			return id, false, fmt.Errorf("read ref %s: %w", name, err)
+		}
+		if packed == nil {
+			if packed, err = g.readPackedRefs(); err != nil {
+				return id, false, err
+			}
+		}
+		id, found = packed[name]
+		return id, found, nil
+	}
+	return id, false, fmt.Errorf("ref %s: too many levels of symbolic refs", name)
+}
+
+func isDirError(err error) bool {
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) {
+		return false
+	}
+	info, statErr := os.Stat(pathErr.Path)
+	return statErr == nil && info.IsDir()
+}
+
+// readPackedRefs parses packed-refs. Peeled tag lines ("^<hash>") are skipped
+// since tags are peeled through their objects.
+func (g *gitNative) readPackedRefs() (map[string]objectID, error) {
+	refs := map[string]objectID{}
+	data, err := os.ReadFile(filepath.Join(g.commonDir, "packed-refs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return refs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read packed-refs: %w", err)
+	}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		hash, name, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("unexpected packed-refs line: %q", line)
+		}
+		id, err := parseObjectID(hash)
+		if err != nil {
+			return nil, fmt.Errorf("packed-refs: %w", err)
+		}
+		refs[name] = id
+	}
+	return refs, nil
+}
+
+// refNames lists every ref under refs/, sorted like git show-ref.
+func (g *gitNative) refNames() ([]string, error) {
+	packed, err := g.readPackedRefs()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	for name := range packed {
+		seen[name] = struct{}{}
+	}
+	refsDir := filepath.Join(g.commonDir, "refs")
+	err = filepath.WalkDir(refsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(d.Name(), ".lock") {
+			return nil
+		}
+		rel, err := filepath.Rel(g.commonDir, path)
+		if err != nil {
+			return err
+		}
+		seen[filepath.ToSlash(rel)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list refs: %w", err)
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// peel follows annotated tags to the object they ultimately point at.
+func (g *gitNative) peel(id objectID) (objectID, error) {
+	for range maxDeltaChain {
+		typ, data, err := g.objects.read(id)
+		if err != nil {
+			return id, err
+		}
+		if typ != objectTag {
+			return id, nil
+		}
+		if id, err = parseTagObject(data); err != nil {
+			return id, err
+		}
+	}
+	return id, fmt.Errorf("tag chain too long")
+}
+
+// resolveRevision resolves a single revision expression such as "main",
+// "v1.0^{}", "HEAD~3", "abc1234^2" or "@" to a commit.
+func (g *gitNative) resolveRevision(rev string) (objectID, error) {
+	base, suffix := splitRevisionSuffix(rev)
+	id, err := g.resolveRevisionName(base)
+	if err != nil {
+		return id, err
+	}
+	for suffix != "" {
+		switch {
+		case strings.HasPrefix(suffix, "^{"):
+			end := strings.IndexByte(suffix, '}')
+			if end < 0 {
+				return id, fmt.Errorf("bad revision %q", rev)
+			}
+			if peelTo := suffix[2:end]; peelTo != "" && peelTo != "commit" {
+				return id, fmt.Errorf("bad revision %q: only ^{} and ^{commit} are supported", rev)
+			}
+			suffix = suffix[end+1:]
+			if id, err = g.peel(id); err != nil {
+				return id, err
+			}
+		case suffix[0] == '^' || suffix[0] == '~':
+			op := suffix[0]
+			suffix = suffix[1:]
+			n := 1
+			digits := len(suffix) - len(strings.TrimLeft(suffix, "0123456789"))
+			if digits > 0 {
+				if n, err = strconv.Atoi(suffix[:digits]); err != nil {
+					return id, fmt.Errorf("bad revision %q", rev)
+				}
+				suffix = suffix[digits:]
+			}
+			if id, err = g.walkRevisionStep(id, op, n); err != nil {
+				return id, fmt.Errorf("bad revision %q: %w", rev, err)
+			}
+		default:
+			return id, fmt.Errorf("bad revision %q", rev)
+		}
+	}
+	return g.commitish(id, rev)
+}
+
+func splitRevisionSuffix(rev string) (base string, suffix string) {
+	idx := strings.IndexAny(rev, "^~")
+	if idx < 0 {
+		return rev, ""
+	}
+	return rev[:idx], rev[idx:]
+}
+
+// walkRevisionStep applies "^n" (n-th parent) or "~n" (n-th first-parent
+// ancestor) to id.
+func (g *gitNative) walkRevisionStep(id objectID, op byte, n int) (objectID, error) {
+	commit, err := g.readCommit(id)
+	if err != nil {
+		return id, err
+	}
+	if op == '^' {
+		if n == 0 {
+			return commit.id, nil
+		}
+		if n > len(commit.parents) {
+			return id, fmt.Errorf("commit %s has no parent %d", commit.id, n)
+		}
+		return commit.parents[n-1], nil
+	}
+	for range n {
+		if len(commit.parents) == 0 {
+			return id, fmt.Errorf("commit %s has no parent", commit.id)
+		}
+		if commit, err = g.readCommit(commit.parents[0]); err != nil {
+			return id, err
+		}
+	}
+	return commit.id, nil
+}
+
+// resolveRevisionName resolves a ref name or (abbreviated) object name using
+// the lookup order documented in gitrevisions(7).
+func (g *gitNative) resolveRevisionName(name string) (objectID, error) {
+	if name == "" || name == "@" {
+		name = "HEAD"
+	}
+	if len(name) == 40 {
+		if id, err := parseObjectID(name); err == nil {
+			return id, nil
+		}
+	}
+	candidates := []string{
+		name,
+		"refs/" + name,
+		"refs/tags/" + name,
+		"refs/heads/" + name,
+		"refs/remotes/" + name,
+		"refs/remotes/" + name + "/HEAD",
+	}
+	for _, candidate := range candidates {
+		if candidate == name && !isPseudoRef(name) && !strings.HasPrefix(name, "refs/") {
+			continue
+		}
+		id, found, err := g.resolveRef(candidate)
+		if err != nil {
+			return id, err
+		}
+		if found {
+			return id, nil
+		}
+	}
+	id, err := g.objects.resolvePrefix(name)
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return id, fmt.Errorf("unknown revision %q", name)
+		}
+		return id, err
+	}
+	return id, nil
+}
+
+// isPseudoRef reports whether name looks like HEAD, FETCH_HEAD and friends,
+// which live directly in the git directory.
+func isPseudoRef(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'A' || r > 'Z') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// commitish peels id to a commit, reporting rev in errors.
+func (g *gitNative) commitish(id objectID, rev string) (objectID, error) {
+	id, err := g.peel(id)
+	if err != nil {
+		return id, err
+	}
+	typ, _, err := g.objects.read(id)
+	if err != nil {
+		return id, err
+	}
+	if typ != objectCommit {
+		return id, fmt.Errorf("revision %q is a %s, not a commit", rev, typ)
+	}
+	return id, nil
+}
+
+func (g *gitNative) readCommit(id objectID) (*nativeCommit, error) {
+	id, err := g.peel(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := g.objects.readType(id, objectCommit)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommitObject(id, data)
+}
@@ -0,0 +1,174 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/myers"
+)
+
+// SearchCommits walks the history selected by spec like StartLogStream and
+// keeps the commits matching query. As with git log, merges are never
+// matched by -S or -G since their diffs are not inspected. Regular
+// expressions use Go's RE2 syntax rather than POSIX.
+func (g *gitNative) SearchCommits(ctx context.Context, spec LogSpec, query SearchQuery) ([]string, error) {
+	if query.Pattern == "" {
+		return nil, fmt.Errorf("search pattern not specified")
+	}
+	var match func(parentTree, tree objectID) (bool, error)
+	var touching *pathspec
+	switch query.Kind {
+	case SearchPaths:
+		if len(spec.Paths) == 0 {
+			spec.Paths = []string{query.Pattern}
+			break
+		}
+		// Like git log --full-history, the commits of the view are kept
+		// when they change the searched path.
+		ps := newPathspec([]string{query.Pattern})
+		touching = &ps
+	case SearchPickaxe:
+		needle := []byte(query.Pattern)
+		match = g.diffMatcher(spec.Paths, func(oldData, newData []byte) bool {
+			return bytes.Count(oldData, needle) != bytes.Count(newData, needle)
+		})
+	case SearchChangedLines:
+		re, err := regexp.Compile(query.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("git log: invalid pattern: %w", err)
+		}
+		match = g.diffMatcher(spec.Paths, func(oldData, newData []byte) bool {
+			return changedLinesMatch(splitLines(oldData), splitLines(newData), re)
+		})
+	default:
+		return nil, fmt.Errorf("unknown search kind %d", query.Kind)
+	}
+	w, err := g.newWalk(spec)
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	var hashes []string
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("git log: %w", err)
+		}
+		commit, err := w.next()
+		if err == io.EOF {
+			return hashes, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("git log: %w", err)
+		}
+		if match == nil && touching == nil {
+			hashes = append(hashes, commit.Hash)
+			continue
+		}
+		id, err := parseObjectID(commit.Hash)
+		if err != nil {
+			return nil, err
+		}
+		c, err := g.readCommit(id)
+		if err != nil {
+			return nil, fmt.Errorf("git log: %w", err)
+		}
+		if touching != nil {
+			ok, err := g.commitTouches(c, *touching)
+			if err != nil {
+				return nil, fmt.Errorf("git log: %w", err)
+			}
+			if ok {
+				hashes = append(hashes, commit.Hash)
+			}
+			continue
+		}
+		if len(c.parents) > 1 {
+			continue
+		}
+		var parentTree objectID
+		if len(c.parents) > 0 {
+			parent, err := g.readCommit(c.parents[0])
+			if err != nil {
+				return nil, fmt.Errorf("git log: %w", err)
+			}
+			parentTree = parent.tree
+		}
+		ok, err := match(parentTree, c.tree)
+		if err != nil {
+			return nil, fmt.Errorf("git log: %w", err)
+		}
+		if ok {
+			hashes = append(hashes, commit.Hash)
+		}
+	}
+}
+
+// commitTouches reports whether c changes a path matched by ps. A merge
+// touches it unless it is TREESAME to all of its parents.
+func (g *gitNative) commitTouches(c *nativeCommit, ps pathspec) (bool, error) {
+	if len(c.parents) == 0 {
+		return g.treesDiffer(objectID{}, c.tree, ps)
+	}
+	for _, parentID := range c.parents {
+		parent, err := g.readCommit(parentID)
+		if err != nil {
+			return false, err
+		}
+		differ, err := g.treesDiffer(parent.tree, c.tree, ps)
+		if err != nil || differ {
+			return differ, err
+		}
+	}
+	return false, nil
+}
+
+// diffMatcher returns a function reporting whether fn matches the contents of
+// any text file changed between two trees.
+func (g *gitNative) diffMatcher(paths []string, fn func(oldData, newData []byte) bool) func(a, b objectID) (bool, error) {
+	ps := newPathspec(paths)
+	return func(a, b objectID) (bool, error) {
+		changes, err := g.diffTrees(a, b, ps)
+		if err != nil {
+			return false, err
+		}
+		for _, change := range changes {
+			oldData, err := g.blobContent(change.oldMode, change.oldID)
+			if err != nil {
+				return false, err
+			}
+			newData, err := g.blobContent(change.newMode, change.newID)
+			if err != nil {
+				return false, err
+			}
+			// Like git, binary files are skipped without --text.
+			if isBinary(oldData) || isBinary(newData) {
+				continue
+			}
+			if fn(oldData, newData) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+func changedLinesMatch(a, b []string, re *regexp.Regexp) bool {
+	for _, op := range myers.Diff(a, b) {
+		var lines []string
+		switch op.Kind {
+		case myers.Delete:
+			lines = a[op.AStart:op.AEnd]
+		case myers.Insert:
+			lines = b[op.BStart:op.BEnd]
+		}
+		for _, line := range lines {
+			if re.MatchString(strings.TrimSuffix(line, "\n")) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,572 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNativeMatchesCLI(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := createNativeTestRepo(t)
+
+	t.Run("loose", func(t *testing.T) {
+		compareBackends(t, dir)
+	})
+	runGitCmd(t, dir, nil, "repack", "-adf", "-q")
+	runGitCmd(t, dir, nil, "pack-refs", "--all")
+	t.Run("packed", func(t *testing.T) {
+		compareBackends(t, dir)
+	})
+}
+
+func compareBackends(t *testing.T, dir string) {
+	t.Helper()
+	cli, err := OpenCLI(dir)
+	if err != nil {
+		t.Fatalf("OpenCLI: %v", err)
+	}
+	native, err := OpenNative(filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatalf("OpenNative: %v", err)
+	}
+	if cli.RepoPath() != native.RepoPath() {
+		t.Fatalf("RepoPath: cli=%q native=%q", cli.RepoPath(), native.RepoPath())
+	}
+
+	cliHash, cliName, cliOK, err := cli.HeadState()
+	if err != nil {
+		t.Fatalf("cli HeadState: %v", err)
+	}
+	hash, name, ok, err := native.HeadState()
+	if err != nil {
+		t.Fatalf("native HeadState: %v", err)
+	}
+	if hash != cliHash || name != cliName || ok != cliOK {
+		t.Fatalf("HeadState: native=(%s %s %v) cli=(%s %s %v)", hash, name, ok, cliHash, cliName, cliOK)
+	}
+
+	cliRefs, err := cli.ListRefs()
+	if err != nil {
+		t.Fatalf("cli ListRefs: %v", err)
+	}
+	refs, err := native.ListRefs()
+	if err != nil {
+		t.Fatalf("native ListRefs: %v", err)
+	}
+	if !slices.Equal(refs, cliRefs) {
+		t.Fatalf("ListRefs:\nnative=%+v\ncli=%+v", refs, cliRefs)
+	}
+
+	cliStashes, err := cli.ListStashes()
+	if err != nil {
+		t.Fatalf("cli ListStashes: %v", err)
+	}
+	stashes, err := native.ListStashes()
+	if err != nil {
+		t.Fatalf("native ListStashes: %v", err)
+	}
+	if len(cliStashes) != 2 || !slices.Equal(stashes, cliStashes) {
+		t.Fatalf("ListStashes:\nnative=%+v\ncli=%+v", stashes, cliStashes)
+	}
+
+	for _, rev := range []string{"HEAD", "HEAD~2", "main~1^2", "v1.0", "light", "feature", cliHash[:7]} {
+		want, err := cli.ResolveRevision(rev)
+		if err != nil {
+			t.Fatalf("cli ResolveRevision(%q): %v", rev, err)
+		}
+		got, err := native.ResolveRevision(rev)
+		if err != nil {
+			t.Fatalf("native ResolveRevision(%q): %v", rev, err)
+		}
+		if got != want {
+			t.Fatalf("ResolveRevision(%q): native=%s cli=%s", rev, got, want)
+		}
+	}
+	for _, rev := range []string{"missing", "--all", "HEAD~100"} {
+		if _, err := cli.ResolveRevision(rev); err == nil {
+			t.Fatalf("cli ResolveRevision(%q): expected error", rev)
+		}
+		if _, err := native.ResolveRevision(rev); err == nil {
+			t.Fatalf("native ResolveRevision(%q): expected error", rev)
+		}
+	}
+
+	for _, revisions := range [][]string{{"HEAD"}, {"feature..main"}, {"HEAD..refs/heads/feature"}, {"--all", "^main~1"}} {
+		want, err := cli.CountCommits(revisions)
+		if err != nil {
+			t.Fatalf("cli CountCommits(%q): %v", revisions, err)
+		}
+		got, err := native.CountCommits(revisions)
+		if err != nil {
+			t.Fatalf("native CountCommits(%q): %v", revisions, err)
+		}
+		if got != want {
+			t.Fatalf("CountCommits(%q): native=%d cli=%d", revisions, got, want)
+		}
+	}
+
+	// LogContains is checked for every commit against the commits each spec
+	// lists.
+	allCommits := readLog(t, cli, LogSpec{Revisions: []string{"--all"}})
+	specs := []LogSpec{
+		{},
+		{Revisions: []string{"--all"}},
+		{Revisions: []string{"main..feature"}},
+		{Revisions: []string{"feature...main"}},
+		{Revisions: []string{"HEAD~2", "^HEAD~4"}},
+		{Revisions: []string{"refs/heads/feature", "^HEAD~2"}},
+		{Revisions: []string{"v1.0^{}"}},
+		{Revisions: []string{"--all"}, Paths: []string{"dir"}},
+		{Paths: []string{"a.txt"}},
+		{Paths: []string{"*.go"}},
+	}
+	for _, spec := range specs {
+		cliCommits := readLog(t, cli, spec)
+		commits := readLog(t, native, spec)
+		if len(commits) != len(cliCommits) {
+			t.Fatalf("log %q: native returned %d commits, cli %d", spec, len(commits), len(cliCommits))
+		}
+		for i := range commits {
+			got, want := commits[i], cliCommits[i]
+			if got.Hash != want.Hash || !slices.Equal(got.ParentHashes, want.ParentHashes) {
+				t.Fatalf("log %q[%d]: native=%s %v cli=%s %v",
+					spec, i, got.Hash, got.ParentHashes, want.Hash, want.ParentHashes)
+			}
+			if !sameSignature(got.Author, want.Author) || !sameSignature(got.Committer, want.Committer) {
+				t.Fatalf("log %q[%d]: signatures differ: native=%+v cli=%+v", spec, i, got, want)
+			}
+			if got.Message != want.Message {
+				t.Fatalf("log %q[%d]: message native=%q cli=%q", spec, i, got.Message, want.Message)
+			}
+		}
+		for _, c := range allCommits {
+			want := slices.ContainsFunc(cliCommits, func(logged *Commit) bool { return logged.Hash == c.Hash })
+			for name, backend := range map[string]Backend{"cli": cli, "native": native} {
+				got, err := backend.LogContains(spec, c.Hash)
+				if err != nil {
+					t.Fatalf("%s LogContains(%q, %s): %v", name, spec, c.Hash, err)
+				}
+				if got != want {
+					t.Fatalf("%s LogContains(%q, %s) = %v, want %v", name, spec, c.Hash, got, want)
+				}
+			}
+		}
+	}
+
+	searches := []struct {
+		spec  LogSpec
+		query SearchQuery
+	}{
+		{LogSpec{}, SearchQuery{Kind: SearchPickaxe, Pattern: "return"}},
+		{LogSpec{Revisions: []string{"--all"}}, SearchQuery{Kind: SearchPickaxe, Pattern: "line 3"}},
+		{LogSpec{Revisions: []string{"--all"}}, SearchQuery{Kind: SearchChangedLines, Pattern: "^line [0-9]+$"}},
+		{LogSpec{Revisions: []string{"--all"}, Paths: []string{"dir"}}, SearchQuery{Kind: SearchChangedLines, Pattern: "package"}},
+		{LogSpec{Revisions: []string{"--all"}}, SearchQuery{Kind: SearchPaths, Pattern: "dir/*.go"}},
+		{LogSpec{Revisions: []string{"--all"}, Paths: []string{"dir"}}, SearchQuery{Kind: SearchPaths, Pattern: "dir/c.go"}},
+		{LogSpec{Paths: []string{"dir/b.go"}}, SearchQuery{Kind: SearchPaths, Pattern: "dir"}},
+		{LogSpec{Paths: []string{"dir"}}, SearchQuery{Kind: SearchPaths, Pattern: "a.txt"}},
+		{LogSpec{}, SearchQuery{Kind: SearchPickaxe, Pattern: "no such text"}},
+	}
+	for _, search := range searches {
+		want, err := cli.SearchCommits(context.Background(), search.spec, search.query)
+		if err != nil {
+			t.Fatalf("cli SearchCommits(%q, %+v): %v", search.spec, search.query, err)
+		}
+		got, err := native.SearchCommits(context.Background(), search.spec, search.query)
+		if err != nil {
+			t.Fatalf("native SearchCommits(%q, %+v): %v", search.spec, search.query, err)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("SearchCommits(%q, %+v):\nnative=%v\ncli=%v", search.spec, search.query, got, want)
+		}
+		if search.query.Kind == SearchPaths && len(search.spec.Paths) > 0 {
+			var view []string
+			for _, c := range readLog(t, cli, search.spec) {
+				view = append(view, c.Hash)
+			}
+			for _, hash := range want {
+				if !slices.Contains(view, hash) {
+					t.Fatalf("SearchCommits(%q, %+v) = %v, %s is not in the view", search.spec, search.query, want, hash)
+				}
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, backend := range map[string]Backend{"cli": cli, "native": native} {
+		if _, err := backend.SearchCommits(ctx, LogSpec{}, SearchQuery{Kind: SearchPickaxe, Pattern: "return"}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("%s SearchCommits with a cancelled context: err = %v, want context.Canceled", name, err)
+		}
+	}
+
+	diffOptions := []DiffOptions{
+		{},
+		{Context: 1},
+		{Context: NoContext},
+		{RenameThreshold: -1, Algorithm: DiffAlgorithmMyers},
+	}
+	for _, commit := range readLog(t, cli, LogSpec{Revisions: []string{"--all"}}) {
+		parent := ""
+		if len(commit.ParentHashes) > 0 {
+			parent = commit.ParentHashes[0]
+		}
+		for _, opts := range diffOptions {
+			want, err := cli.CommitDiffText(commit.Hash, parent, opts)
+			if err != nil {
+				t.Fatalf("cli CommitDiffText(%+v): %v", opts, err)
+			}
+			got, err := native.CommitDiffText(commit.Hash, parent, opts)
+			if err != nil {
+				t.Fatalf("native CommitDiffText(%+v): %v", opts, err)
+			}
+			// git show separates the (empty) header from the patch with a newline.
+			if strings.TrimLeft(got, "\n") != strings.TrimLeft(want, "\n") {
+				t.Fatalf("diff of %s with %+v:\nnative:\n%s\ncli:\n%s", commit.Message, opts, got, want)
+			}
+		}
+
+		wantTree, err := cli.ListTree(commit.Hash)
+		if err != nil {
+			t.Fatalf("cli ListTree: %v", err)
+		}
+		tree, err := native.ListTree(commit.Hash)
+		if err != nil {
+			t.Fatalf("native ListTree: %v", err)
+		}
+		if !slices.Equal(tree, wantTree) {
+			t.Fatalf("ListTree of %s:\nnative=%+v\ncli=%+v", commit.Hash, tree, wantTree)
+		}
+		for _, entry := range tree {
+			if entry.Kind != TreeEntryBlob {
+				continue
+			}
+			want, err := cli.ReadBlob(commit.Hash, entry.Path)
+			if err != nil {
+				t.Fatalf("cli ReadBlob(%s): %v", entry.Path, err)
+			}
+			got, err := native.ReadBlob(commit.Hash, entry.Path)
+			if err != nil {
+				t.Fatalf("native ReadBlob(%s): %v", entry.Path, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("ReadBlob(%s): native=%q cli=%q", entry.Path, got, want)
+			}
+		}
+		for _, path := range []string{"missing.txt", "dir", "a.txt/x"} {
+			if _, err := cli.ReadBlob(commit.Hash, path); err == nil {
+				t.Fatalf("cli ReadBlob(%q): expected error", path)
+			}
+			if _, err := native.ReadBlob(commit.Hash, path); err == nil {
+				t.Fatalf("native ReadBlob(%q): expected error", path)
+			}
+		}
+	}
+}
+
+func TestNativeLogStreamIsLazy(t *testing.T) {
+	dir := t.TempDir()
+	runGitCmd(t, dir, nil, "init", "-q", "-b", "main")
+	tree := runGitCmd(t, dir, nil, "mktree")
+	parent := ""
+	for i := range 20 {
+		when := time.Unix(int64(1700000000+i*60), 0).UTC().Format(time.RFC3339)
+		env := []string{"GIT_AUTHOR_DATE=" + when, "GIT_COMMITTER_DATE=" + when,
+			"GIT_AUTHOR_NAME=Alice", "GIT_AUTHOR_EMAIL=alice@example.com",
+			"GIT_COMMITTER_NAME=Alice", "GIT_COMMITTER_EMAIL=alice@example.com"}
+		args := []string{"commit-tree", "-m", fmt.Sprintf("commit %d", i), tree}
+		if parent != "" {
+			args = append(args, "-p", parent)
+		}
+		parent = runGitCmd(t, dir, env, args...)
+	}
+	runGitCmd(t, dir, nil, "update-ref", "refs/heads/main", parent)
+
+	native, err := OpenNative(dir)
+	if err != nil {
+		t.Fatalf("OpenNative: %v", err)
+	}
+	stream, err := native.StartLogStream(LogSpec{})
+	if err != nil {
+		t.Fatalf("StartLogStream: %v", err)
+	}
+	defer stream.Close()
+	if commit, err := stream.Next(); err != nil || commit.Hash != parent {
+		t.Fatalf("Next = %v, %v, want %s", commit, err, parent)
+	}
+	if read := len(stream.(*nativeLogStream).walk.nodes); read > 2 {
+		t.Fatalf("read %d commits to return the first one", read)
+	}
+
+	// The excluded side of a range stops as soon as it hides the rest.
+	stream, err = native.StartLogStream(LogSpec{Revisions: []string{"main~3..main"}})
+	if err != nil {
+		t.Fatalf("StartLogStream(range): %v", err)
+	}
+	defer stream.Close()
+	var count int
+	for {
+		if _, err := stream.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Next(range): %v", err)
+		}
+		count++
+	}
+	if count != 3 {
+		t.Fatalf("range returned %d commits, want 3", count)
+	}
+	if read := len(stream.(*nativeLogStream).walk.nodes); read > 5 {
+		t.Fatalf("read %d commits for a range of 3", read)
+	}
+}
+
+func TestOpenBareRepository(t *testing.T) {
+	bare := filepath.Join(t.TempDir(), "repo.git")
+	runGitCmd(t, t.TempDir(), nil, "clone", "-q", "--bare", createNativeTestRepo(t), bare)
+	cli, err := OpenCLI(bare)
+	if err != nil {
+		t.Fatalf("OpenCLI: %v", err)
+	}
+	native, err := OpenNative(filepath.Join(bare, "refs"))
+	if err != nil {
+		t.Fatalf("OpenNative: %v", err)
+	}
+	if cli.RepoPath() != bare || native.RepoPath() != bare {
+		t.Fatalf("RepoPath: cli=%q native=%q, want %q", cli.RepoPath(), native.RepoPath(), bare)
+	}
+	cliCommits := readLog(t, cli, LogSpec{Revisions: []string{"--all"}})
+	commits := readLog(t, native, LogSpec{Revisions: []string{"--all"}})
+	if len(commits) == 0 || len(commits) != len(cliCommits) {
+		t.Fatalf("native returned %d commits, cli %d", len(commits), len(cliCommits))
+	}
+	if _, err := cli.LocalChangesStatus(); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported for local changes, got %v", err)
+	}
+}
+
+func TestNativeUnsupported(t *testing.T) {
+	dir := createNativeTestRepo(t)
+	native, err := OpenNative(dir)
+	if err != nil {
+		t.Fatalf("OpenNative: %v", err)
+	}
+	if _, err := native.LocalChangesStatus(); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := native.CombinedDiffText("HEAD", DiffOptions{}); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := native.StartBlame("HEAD", "long.txt"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := native.CreateBranch("topic", "HEAD", false); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := native.CreateTag("v2", "HEAD", "release"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := native.ApplyPatch("diff --git a/a.txt b/a.txt\n", ApplyOptions{Cached: true}); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := native.SwitchDetached("HEAD~1"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := native.CreateTrackingBranch("main-copy", "origin/main"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := native.CherryPick("HEAD", 0); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := native.Revert("HEAD", 0); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := native.Reset("HEAD~1", ResetHard); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	for _, opts := range []DiffOptions{
+		{Whitespace: WhitespaceIgnoreAll},
+		{IgnoreBlankLines: true},
+		{CopyThreshold: 50},
+		{Algorithm: DiffAlgorithmPatience},
+	} {
+		if _, err := native.CommitDiffText("HEAD", "", opts); !errors.Is(err, errors.ErrUnsupported) {
+			t.Fatalf("CommitDiffText(%+v): expected ErrUnsupported, got %v", opts, err)
+		}
+	}
+	if _, err := native.StartLogStream(LogSpec{Revisions: []string{"--first-parent"}}); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := native.StartLogStream(LogSpec{Paths: []string{"a.txt"}, Follow: true}); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := native.StartLogStream(LogSpec{Revisions: []string{"missing"}}); err == nil {
+		t.Fatal("expected error for unknown revision")
+	}
+}
+
+func TestQuotePath(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"a/plain.txt":   "a/plain.txt",
+		"a/tab\tname":   `"a/tab\tname"`,
+		"a/quote\"name": `"a/quote\"name"`,
+		"a/café":        `"a/caf\303\251"`,
+	}
+	for in, want := range tests {
+		if got := quotePath(in); got != want {
+			t.Fatalf("quotePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	t.Parallel()
+
+	base := []byte("hello world")
+	// src size 11, dst size 12, copy "hello " then insert "gophers"... trimmed.
+	delta := []byte{11, 12, 0x91, 0, 6, 6, 'g', 'o', 'p', 'h', 'e', 'r'}
+	got, err := applyDelta(base, delta)
+	if err != nil {
+		t.Fatalf("applyDelta: %v", err)
+	}
+	if string(got) != "hello gopher" {
+		t.Fatalf("applyDelta = %q", got)
+	}
+	if _, err := applyDelta([]byte("short"), delta); err == nil {
+		t.Fatal("expected base size mismatch")
+	}
+}
+
+func sameSignature(a, b Signature) bool {
+	_, offA := a.When.Zone()
+	_, offB := b.When.Zone()
+	return a.Name == b.Name && a.Email == b.Email && a.When.Equal(b.When) && offA == offB
+}
+
+func readLog(t *testing.T, b Backend, spec LogSpec) []*Commit {
+	t.Helper()
+	stream, err := b.StartLogStream(spec)
+	if err != nil {
+		t.Fatalf("StartLogStream(%q): %v", spec, err)
+	}
+	defer stream.Close()
+	var commits []*Commit
+	for {
+		commit, err := stream.Next()
+		if err == io.EOF {
+			return commits
+		}
+		if err != nil {
+			t.Fatalf("Next(%q): %v", spec, err)
+		}
+		commits = append(commits, commit)
+	}
+}
+
+// createNativeTestRepo builds a small history exercising merges, tags, modes,
+// binary files and unusual file names.
+func createNativeTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGitCmd(t, dir, nil, "init", "-q", "-b", "main")
+	runGitCmd(t, dir, nil, "config", "user.name", "Alice")
+	runGitCmd(t, dir, nil, "config", "user.email", "alice@example.com")
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+
+	step := 0
+	commit := func(msg string, files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			if content == "" {
+				runGitCmd(t, dir, nil, "rm", "-q", "--", name)
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatalf("MkdirAll: %v", err)
+			}
+			if content == "<empty>" {
+				content = ""
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			runGitCmd(t, dir, nil, "add", "--", name)
+		}
+		step++
+		when := time.Unix(int64(1700000000+step*60), 0).In(time.FixedZone("", 5400)).Format(time.RFC3339)
+		env := []string{"GIT_AUTHOR_DATE=" + when, "GIT_COMMITTER_DATE=" + when}
+		runGitCmd(t, dir, env, "commit", "-q", "--no-gpg-sign", "--allow-empty", "-m", msg)
+	}
+
+	var long strings.Builder
+	for i := range 40 {
+		fmt.Fprintf(&long, "line %d\n", i)
+	}
+	commit("initial\n\nWith a body.", map[string]string{
+		"a.txt":      "one\ntwo\nthree\n",
+		"dir/b.go":   "package b\n\nfunc B() int {\n\treturn 1\n}\n",
+		"long.txt":   long.String(),
+		"empty.txt":  "<empty>",
+		"no-eol.txt": "no newline",
+	})
+	commit("edit files", map[string]string{
+		"a.txt":      "one\n2\nthree\nfour\n",
+		"long.txt":   strings.Replace(strings.Replace(long.String(), "line 3\n", "line three\n", 1), "line 30\n", "", 1),
+		"no-eol.txt": "no newline\nnow",
+		"café.txt":   "unicode name\n",
+	})
+	runGitCmd(t, dir, nil, "update-index", "--chmod=+x", "dir/b.go")
+	commit("make executable", map[string]string{"bin.dat": "bin\x00ary\n"})
+	runGitCmd(t, dir, nil, "tag", "-a", "v1.0", "-m", "release")
+	runGitCmd(t, dir, nil, "tag", "light")
+
+	runGitCmd(t, dir, nil, "switch", "-q", "-c", "feature")
+	commit("feature work", map[string]string{"dir/c.go": "package b\n\nconst C = 3\n"})
+	commit("feature edit", map[string]string{"dir/b.go": "package b\n\nfunc B() int {\n\treturn 2\n}\n"})
+	runGitCmd(t, dir, nil, "switch", "-q", "main")
+	commit("main work", map[string]string{"a.txt": "", "empty.txt": ""})
+	step++
+	when := time.Unix(int64(1700000000+step*60), 0).UTC().Format(time.RFC3339)
+	runGitCmd(t, dir, []string{"GIT_AUTHOR_DATE=" + when, "GIT_COMMITTER_DATE=" + when},
+		"merge", "-q", "--no-ff", "--no-gpg-sign", "-m", "merge feature", "feature")
+	commit("after merge", map[string]string{"bin.dat": "bin\x00ary\nchanged\n"})
+	runGitCmd(t, dir, nil, "update-ref", "refs/remotes/origin/main", "HEAD~1")
+	runGitCmd(t, dir, nil, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	for _, content := range []string{"stashed once\n", "stashed twice\n"} {
+		if err := os.WriteFile(filepath.Join(dir, "long.txt"), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		runGitCmd(t, dir, nil, "stash", "push", "-q", "-m", strings.TrimSpace(content))
+	}
+	return dir
+}
+
+func runGitCmd(t *testing.T, dir string, extraEnv []string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), extraEnv...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String())
+}
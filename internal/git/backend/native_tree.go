@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (g *gitNative) ListTree(commitHash string) ([]TreeEntry, error) {
+	commit, err := g.treeCommit(commitHash)
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree: %w", err)
+	}
+	var entries []TreeEntry
+	if err := g.appendTreeEntries(&entries, commit.tree, ""); err != nil {
+		return nil, fmt.Errorf("git ls-tree: %w", err)
+	}
+	return entries, nil
+}
+
+// appendTreeEntries appends the entries of tree id to entries, recursing into
+// subdirectories right after listing them.
+func (g *gitNative) appendTreeEntries(entries *[]TreeEntry, id objectID, prefix string) error {
+	tree, err := g.readTree(id)
+	if err != nil {
+		return err
+	}
+	for _, e := range tree {
+		entry := TreeEntry{Path: prefix + e.name, Mode: fmt.Sprintf("%06o", e.mode), Hash: e.id.String()}
+		switch {
+		case e.isTree():
+			entry.Kind = TreeEntryTree
+		case e.mode == modeGitlink:
+			entry.Kind = TreeEntrySubmodule
+		}
+		*entries = append(*entries, entry)
+		if e.isTree() {
+			if err := g.appendTreeEntries(entries, e.id, entry.Path+"/"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *gitNative) ReadBlob(commitHash string, path string) ([]byte, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path not specified")
+	}
+	commit, err := g.treeCommit(commitHash)
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	id := commit.tree
+	mode := uint32(modeTree)
+	for name := range strings.SplitSeq(path, "/") {
+		if mode != modeTree {
+			return nil, fmt.Errorf("git cat-file: path %q does not exist in %s", path, commitHash)
+		}
+		tree, err := g.readTree(id)
+		if err != nil {
+			return nil, fmt.Errorf("git cat-file: %w", err)
+		}
+		found := false
+		for _, e := range tree {
+			if e.name == name {
+				id, mode, found = e.id, e.mode, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("git cat-file: path %q does not exist in %s", path, commitHash)
+		}
+	}
+	if mode == modeTree || mode == modeGitlink {
+		return nil, fmt.Errorf("git cat-file: %q is not a file", path)
+	}
+	data, err := g.objects.readType(id, objectBlob)
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	return data, nil
+}
+
+func (g *gitNative) treeCommit(commitHash string) (*nativeCommit, error) {
+	commitHash = strings.TrimSpace(commitHash)
+	if commitHash == "" {
+		return nil, fmt.Errorf("commit not specified")
+	}
+	id, err := g.resolveRevision(commitHash)
+	if err != nil {
+		return nil, err
+	}
+	return g.readCommit(id)
+}
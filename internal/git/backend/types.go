@@ -1,6 +1,11 @@
 package backend
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
 
 type Signature struct {
 	Name  string
@@ -14,11 +19,69 @@ type Commit struct {
 	Author       Signature
 	Committer    Signature
 	Message      string
+	// Path is the name of the followed file at this commit, set by log
+	// streams started with LogSpec.Follow.
+	Path string
 }
 
 type LocalChanges struct {
 	HasWorktree bool
 	HasStaged   bool
+	// Conflicts lists the paths left unmerged by a merge, cherry-pick or
+	// revert that stopped on conflicts.
+	Conflicts []string
+}
+
+// ApplyOptions select where ApplyPatch applies a patch.
+type ApplyOptions struct {
+	// Cached applies the patch to the index only, leaving the working tree
+	// alone. Otherwise only the working tree is patched.
+	Cached bool
+	// Reverse applies the patch backwards, undoing it.
+	Reverse bool
+	// UnidiffZero accepts hunks without context lines, as made by diffs
+	// without context.
+	UnidiffZero bool
+}
+
+// ResetMode selects what Reset resets besides HEAD.
+type ResetMode uint8
+
+const (
+	// ResetSoft keeps the index and the working tree.
+	ResetSoft ResetMode = iota
+	// ResetMixed resets the index but keeps the working tree.
+	ResetMixed
+	// ResetHard resets the index and the working tree, discarding local
+	// changes.
+	ResetHard
+)
+
+func (m ResetMode) String() string {
+	switch m {
+	case ResetSoft:
+		return "soft"
+	case ResetMixed:
+		return "mixed"
+	case ResetHard:
+		return "hard"
+	default:
+		return fmt.Sprintf("ResetMode(%d)", m)
+	}
+}
+
+// ConflictError reports a cherry-pick or revert that stopped on conflicts.
+// The repository is left in the middle of the operation, with the
+// conflicting files unmerged.
+type ConflictError struct {
+	// Op is the git command that stopped, "cherry-pick" or "revert".
+	Op    string
+	Files []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s stopped with conflicts in %s; resolve them and commit, or run \"git %s --abort\"",
+		e.Op, strings.Join(e.Files, ", "), e.Op)
 }
 
 type RefKind uint8
@@ -34,3 +97,178 @@ type Ref struct {
 	Kind RefKind
 	Name string // short name: main, origin/main, v1
 }
+
+// Stash is an entry of the stash list.
+type Stash struct {
+	Hash    string
+	Name    string // stash@{0}
+	Message string // e.g. "WIP on main: 1234567 subject"
+}
+
+// TreeEntryKind is the type of object a tree entry points at.
+type TreeEntryKind uint8
+
+const (
+	TreeEntryBlob TreeEntryKind = iota
+	TreeEntryTree
+	// TreeEntrySubmodule is a gitlink to a commit of another repository.
+	TreeEntrySubmodule
+)
+
+// TreeEntry is a file or directory in the tree of a commit.
+type TreeEntry struct {
+	Path string // from the repository root, using "/" separators
+	Mode string // octal, e.g. 100644 or 040000
+	Kind TreeEntryKind
+	Hash string
+}
+
+// BlameChunk is a run of consecutive lines that git blame attributes to the
+// same commit.
+type BlameChunk struct {
+	Hash string
+	// FinalLine is the first line of the run in the blamed file and OrigLine
+	// the same line in Path at Hash. Both count from 1.
+	FinalLine int
+	OrigLine  int
+	Lines     int
+	// Path is the name of the file at Hash, which differs from the blamed
+	// path across renames.
+	Path    string
+	Author  Signature
+	Summary string
+	// Boundary marks a root commit, or the oldest commit of a limited range,
+	// that is blamed for lines it did not necessarily change.
+	Boundary bool
+}
+
+// LogSpec selects the commits walked by a log stream, mirroring the revision
+// and pathspec arguments accepted by gitk.
+type LogSpec struct {
+	// Revisions holds revision arguments such as "main..feature" or "--all".
+	// An empty list walks from HEAD.
+	Revisions []string
+	// Paths limits the walk to commits touching these pathspecs.
+	Paths []string
+	// Follow continues the history of the single path in Paths across
+	// renames, like git log --follow.
+	Follow bool
+}
+
+// Equal reports whether s and other select the same commits.
+func (s LogSpec) Equal(other LogSpec) bool {
+	return slices.Equal(s.Revisions, other.Revisions) && slices.Equal(s.Paths, other.Paths) && s.Follow == other.Follow
+}
+
+func (s LogSpec) String() string {
+	parts := append([]string(nil), s.Revisions...)
+	if s.Follow {
+		parts = append(parts, "--follow")
+	}
+	if len(s.Paths) > 0 {
+		parts = append(parts, "--")
+		parts = append(parts, s.Paths...)
+	}
+	return strings.Join(parts, " ")
+}
+
+// SearchKind selects how commits are matched by Backend.SearchCommits,
+// mirroring gitk's find modes.
+type SearchKind uint8
+
+const (
+	// SearchPickaxe matches commits changing the number of occurrences of a
+	// string, like git log -S.
+	SearchPickaxe SearchKind = iota
+	// SearchChangedLines matches commits adding or removing lines matching a
+	// regular expression, like git log -G.
+	SearchChangedLines
+	// SearchPaths matches commits touching a pathspec.
+	SearchPaths
+)
+
+type SearchQuery struct {
+	Kind    SearchKind
+	Pattern string
+}
+
+// DiffWhitespace selects which whitespace changes diffs ignore.
+type DiffWhitespace uint8
+
+const (
+	WhitespaceShow DiffWhitespace = iota
+	// WhitespaceIgnoreAll ignores whitespace when comparing lines, like
+	// git diff -w.
+	WhitespaceIgnoreAll
+	// WhitespaceIgnoreChange ignores changes in the amount of whitespace,
+	// like git diff -b.
+	WhitespaceIgnoreChange
+)
+
+// DiffAlgorithm names a git diff algorithm. The empty value uses the one
+// configured for the repository.
+type DiffAlgorithm string
+
+const (
+	DiffAlgorithmDefault   DiffAlgorithm = ""
+	DiffAlgorithmMyers     DiffAlgorithm = "myers"
+	DiffAlgorithmMinimal   DiffAlgorithm = "minimal"
+	DiffAlgorithmPatience  DiffAlgorithm = "patience"
+	DiffAlgorithmHistogram DiffAlgorithm = "histogram"
+)
+
+// NoContext asks for diffs without context lines, since a zero Context keeps
+// the default. Any negative Context does the same.
+const NoContext = -1
+
+// DiffOptions tune how diffs are computed. The zero value gives git's
+// defaults.
+type DiffOptions struct {
+	Whitespace DiffWhitespace
+	// IgnoreBlankLines ignores changes whose lines are all blank.
+	IgnoreBlankLines bool
+	// Context is the number of lines of context around changes; zero keeps
+	// the default of three and NoContext shows none.
+	Context int
+	// RenameThreshold is the similarity percentage for a deletion and an
+	// addition to count as a rename; zero keeps git's default and a negative
+	// value turns rename detection off.
+	RenameThreshold int
+	// CopyThreshold is the similarity percentage for an addition to count as
+	// a copy of another file; zero turns copy detection off.
+	CopyThreshold int
+	Algorithm     DiffAlgorithm
+}
+
+// args returns the git diff arguments for o.
+func (o DiffOptions) args() []string {
+	var args []string
+	switch o.Whitespace {
+	case WhitespaceIgnoreAll:
+		args = append(args, "-w")
+	case WhitespaceIgnoreChange:
+		args = append(args, "-b")
+	}
+	if o.IgnoreBlankLines {
+		args = append(args, "--ignore-blank-lines")
+	}
+	switch {
+	case o.Context < 0:
+		args = append(args, "-U0")
+	case o.Context > 0:
+		args = append(args, fmt.Sprintf("-U%d", o.Context))
+	}
+	switch {
+	case o.RenameThreshold < 0:
+		args = append(args, "--no-renames")
+	case o.RenameThreshold > 0:
+		args = append(args, fmt.Sprintf("-M%d%%", min(o.RenameThreshold, 100)))
+	}
+	if o.CopyThreshold > 0 {
+		args = append(args, fmt.Sprintf("-C%d%%", min(o.CopyThreshold, 100)))
+	}
+	if o.Algorithm != DiffAlgorithmDefault {
+		args = append(args, "--diff-algorithm="+string(o.Algorithm))
+	}
+	return args
+}
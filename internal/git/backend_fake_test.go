@@ -1,6 +1,7 @@
 package git
 
 import (
+	"context"
 	"errors"
 
 	gitbackend "github.com/thiagokokada/gitk-go/internal/git/backend"
@@ -11,23 +12,44 @@ type fakeBackend struct {
 
 	headStateFunc          func() (hash string, headName string, ok bool, err error)
 	listRefsFunc           func() ([]gitbackend.Ref, error)
+	listStashesFunc        func() ([]gitbackend.Stash, error)
 	switchBranchFunc       func(branch string) error
+	switchDetachedFunc     func(rev string) error
+	createTrackingFunc     func(name string, remoteBranch string) error
+	createBranchFunc       func(name string, commitHash string, checkout bool) error
+	deleteBranchFunc       func(name string, force bool) error
+	renameBranchFunc       func(oldName string, newName string) error
+	createTagFunc          func(name string, commitHash string, message string) error
+	deleteTagFunc          func(name string) error
+	cherryPickFunc         func(commitHash string, mainline int) error
+	revertFunc             func(commitHash string, mainline int) error
+	resetFunc              func(commitHash string, mode gitbackend.ResetMode) error
+	resolveRevisionFunc    func(rev string) (string, error)
+	logContainsFunc        func(spec gitbackend.LogSpec, commitHash string) (bool, error)
+	countCommitsFunc       func(revisions []string) (int, error)
 	commitDiffTextFunc     func(commitHash string, parentHash string) (string, error)
+	combinedDiffTextFunc   func(commitHash string) (string, error)
 	worktreeDiffTextFunc   func(staged bool) (string, error)
 	localChangesStatusFunc func() (gitbackend.LocalChanges, error)
-	startLogStreamFunc     func(fromHash string) (gitbackend.LogStream, error)
+	applyPatchFunc         func(patch string, opts gitbackend.ApplyOptions) error
+	startLogStreamFunc     func(spec gitbackend.LogSpec) (gitbackend.LogStream, error)
+	searchCommitsFunc      func(spec gitbackend.LogSpec, query gitbackend.SearchQuery) ([]string, error)
+	listTreeFunc           func(commitHash string) ([]gitbackend.TreeEntry, error)
+	readBlobFunc           func(commitHash string, path string) ([]byte, error)
+	startBlameFunc         func(commitHash string, path string) (gitbackend.BlameStream, error)
 
 	lastCommitHash   string
 	lastParentHash   string
 	lastStagedParam  *bool
 	lastSwitchBranch string
+	lastDiffOptions  gitbackend.DiffOptions
 }
 
 func (f *fakeBackend) RepoPath() string { return f.repoPath }
 
-func (f *fakeBackend) StartLogStream(fromHash string) (gitbackend.LogStream, error) {
+func (f *fakeBackend) StartLogStream(spec gitbackend.LogSpec) (gitbackend.LogStream, error) {
 	if f.startLogStreamFunc != nil {
-		return f.startLogStreamFunc(fromHash)
+		return f.startLogStreamFunc(spec)
 	}
 	return nil, errors.New("unexpected StartLogStream call")
 }
@@ -46,6 +68,13 @@ func (f *fakeBackend) ListRefs() ([]gitbackend.Ref, error) {
 	return nil, errors.New("unexpected ListRefs call")
 }
 
+func (f *fakeBackend) ListStashes() ([]gitbackend.Stash, error) {
+	if f.listStashesFunc != nil {
+		return f.listStashesFunc()
+	}
+	return nil, errors.New("unexpected ListStashes call")
+}
+
 func (f *fakeBackend) SwitchBranch(branch string) error {
 	f.lastSwitchBranch = branch
 	if f.switchBranchFunc != nil {
@@ -54,8 +83,100 @@ func (f *fakeBackend) SwitchBranch(branch string) error {
 	return errors.New("unexpected SwitchBranch call")
 }
 
-func (f *fakeBackend) CommitDiffText(commitHash string, parentHash string) (string, error) {
+func (f *fakeBackend) SwitchDetached(rev string) error {
+	if f.switchDetachedFunc != nil {
+		return f.switchDetachedFunc(rev)
+	}
+	return errors.New("unexpected SwitchDetached call")
+}
+
+func (f *fakeBackend) CreateTrackingBranch(name string, remoteBranch string) error {
+	if f.createTrackingFunc != nil {
+		return f.createTrackingFunc(name, remoteBranch)
+	}
+	return errors.New("unexpected CreateTrackingBranch call")
+}
+
+func (f *fakeBackend) CreateBranch(name string, commitHash string, checkout bool) error {
+	if f.createBranchFunc != nil {
+		return f.createBranchFunc(name, commitHash, checkout)
+	}
+	return errors.New("unexpected CreateBranch call")
+}
+
+func (f *fakeBackend) DeleteBranch(name string, force bool) error {
+	if f.deleteBranchFunc != nil {
+		return f.deleteBranchFunc(name, force)
+	}
+	return errors.New("unexpected DeleteBranch call")
+}
+
+func (f *fakeBackend) RenameBranch(oldName string, newName string) error {
+	if f.renameBranchFunc != nil {
+		return f.renameBranchFunc(oldName, newName)
+	}
+	return errors.New("unexpected RenameBranch call")
+}
+
+func (f *fakeBackend) CreateTag(name string, commitHash string, message string) error {
+	if f.createTagFunc != nil {
+		return f.createTagFunc(name, commitHash, message)
+	}
+	return errors.New("unexpected CreateTag call")
+}
+
+func (f *fakeBackend) DeleteTag(name string) error {
+	if f.deleteTagFunc != nil {
+		return f.deleteTagFunc(name)
+	}
+	return errors.New("unexpected DeleteTag call")
+}
+
+func (f *fakeBackend) CherryPick(commitHash string, mainline int) error {
+	if f.cherryPickFunc != nil {
+		return f.cherryPickFunc(commitHash, mainline)
+	}
+	return errors.New("unexpected CherryPick call")
+}
+
+func (f *fakeBackend) Revert(commitHash string, mainline int) error {
+	if f.revertFunc != nil {
+		return f.revertFunc(commitHash, mainline)
+	}
+	return errors.New("unexpected Revert call")
+}
+
+func (f *fakeBackend) Reset(commitHash string, mode gitbackend.ResetMode) error {
+	if f.resetFunc != nil {
+		return f.resetFunc(commitHash, mode)
+	}
+	return errors.New("unexpected Reset call")
+}
+
+func (f *fakeBackend) ResolveRevision(rev string) (string, error) {
+	if f.resolveRevisionFunc != nil {
+		return f.resolveRevisionFunc(rev)
+	}
+	return "", errors.New("unexpected ResolveRevision call")
+}
+
+func (f *fakeBackend) LogContains(spec gitbackend.LogSpec, commitHash string) (bool, error) {
+	if f.logContainsFunc != nil {
+		return f.logContainsFunc(spec, commitHash)
+	}
+	return false, errors.New("unexpected LogContains call")
+}
+
+func (f *fakeBackend) CountCommits(revisions []string) (int, error) {
+	if f.countCommitsFunc != nil {
+		return f.countCommitsFunc(revisions)
+	}
+	return 0, errors.New("unexpected CountCommits call")
+}
+
+func (f *fakeBackend) CommitDiffText(commitHash string, parentHash string, opts gitbackend.DiffOptions) (string, error) {
 	f.lastCommitHash = commitHash
+	f.lastDiffOptions = opts
 	f.lastParentHash = parentHash
 	if f.commitDiffTextFunc != nil {
 		return f.commitDiffTextFunc(commitHash, parentHash)
@@ -63,17 +184,62 @@ func (f *fakeBackend) CommitDiffText(commitHash string, parentHash string) (stri
 	return "", errors.New("unexpected CommitDiffText call")
 }
 
-func (f *fakeBackend) WorktreeDiffText(staged bool) (string, error) {
+func (f *fakeBackend) CombinedDiffText(commitHash string, opts gitbackend.DiffOptions) (string, error) {
+	f.lastCommitHash = commitHash
+	f.lastDiffOptions = opts
+	if f.combinedDiffTextFunc != nil {
+		return f.combinedDiffTextFunc(commitHash)
+	}
+	return "", errors.New("unexpected CombinedDiffText call")
+}
+
+func (f *fakeBackend) WorktreeDiffText(staged bool, opts gitbackend.DiffOptions) (string, error) {
 	f.lastStagedParam = &staged
+	f.lastDiffOptions = opts
 	if f.worktreeDiffTextFunc != nil {
 		return f.worktreeDiffTextFunc(staged)
 	}
 	return "", errors.New("unexpected WorktreeDiffText call")
 }
 
+func (f *fakeBackend) ApplyPatch(patch string, opts gitbackend.ApplyOptions) error {
+	if f.applyPatchFunc != nil {
+		return f.applyPatchFunc(patch, opts)
+	}
+	return errors.New("unexpected ApplyPatch call")
+}
+
 func (f *fakeBackend) LocalChangesStatus() (gitbackend.LocalChanges, error) {
 	if f.localChangesStatusFunc != nil {
 		return f.localChangesStatusFunc()
 	}
 	return gitbackend.LocalChanges{}, errors.New("unexpected LocalChangesStatus call")
 }
+
+func (f *fakeBackend) SearchCommits(_ context.Context, spec gitbackend.LogSpec, query gitbackend.SearchQuery) ([]string, error) {
+	if f.searchCommitsFunc != nil {
+		return f.searchCommitsFunc(spec, query)
+	}
+	return nil, errors.New("unexpected SearchCommits call")
+}
+
+func (f *fakeBackend) ListTree(commitHash string) ([]gitbackend.TreeEntry, error) {
+	if f.listTreeFunc != nil {
+		return f.listTreeFunc(commitHash)
+	}
+	return nil, errors.New("unexpected ListTree call")
+}
+
+func (f *fakeBackend) ReadBlob(commitHash string, path string) ([]byte, error) {
+	if f.readBlobFunc != nil {
+		return f.readBlobFunc(commitHash, path)
+	}
+	return nil, errors.New("unexpected ReadBlob call")
+}
+
+func (f *fakeBackend) StartBlame(commitHash string, path string) (gitbackend.BlameStream, error) {
+	if f.startBlameFunc != nil {
+		return f.startBlameFunc(commitHash, path)
+	}
+	return nil, errors.New("unexpected StartBlame call")
+}
@@ -0,0 +1,19 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Blame starts streaming the origin of each line of the file at path in
+// commitHash. The caller must close the stream.
+func (s *Service) Blame(commitHash, path string) (BlameStream, error) {
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return nil, fmt.Errorf("repository root not set")
+	}
+	commitHash = strings.TrimSpace(commitHash)
+	if commitHash == "" || path == "" {
+		return nil, fmt.Errorf("commit or path not specified")
+	}
+	return s.backend.StartBlame(commitHash, path)
+}
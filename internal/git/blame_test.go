@@ -0,0 +1,29 @@
+package git
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBlame_DelegatesToBackend(t *testing.T) {
+	t.Parallel()
+
+	var gotHash, gotPath string
+	backend := &fakeBackend{
+		repoPath: "repo",
+		startBlameFunc: func(commitHash, path string) (BlameStream, error) {
+			gotHash, gotPath = commitHash, path
+			return nil, errors.New("boom")
+		},
+	}
+	svc := NewWithBackend(backend)
+	if _, err := svc.Blame("abc", ""); err == nil {
+		t.Fatal("expected error without path")
+	}
+	if _, err := svc.Blame(" abc ", "dir/a.go"); err == nil || err.Error() != "boom" {
+		t.Fatalf("expected backend error, got %v", err)
+	}
+	if gotHash != "abc" || gotPath != "dir/a.go" {
+		t.Fatalf("StartBlame(%q, %q), want (abc, dir/a.go)", gotHash, gotPath)
+	}
+}
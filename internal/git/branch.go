@@ -68,3 +68,121 @@ func (s *Service) SwitchBranch(branch string) error {
 	}
 	return nil
 }
+
+// SwitchDetached checks out the commit named by rev, detaching HEAD.
+func (s *Service) SwitchDetached(rev string) error {
+	return s.moveHead(rev, func(rev string) error {
+		return s.backend.SwitchDetached(rev)
+	})
+}
+
+// CreateTrackingBranch creates the local branch name tracking remoteBranch,
+// such as "origin/main", and switches to it.
+func (s *Service) CreateTrackingBranch(name, remoteBranch string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("branch not specified")
+	}
+	return s.moveHead(remoteBranch, func(remoteBranch string) error {
+		return s.backend.CreateTrackingBranch(name, remoteBranch)
+	})
+}
+
+// CreateBranch creates branch name at commit, switching to it when checkout
+// is set.
+func (s *Service) CreateBranch(name, commit string, checkout bool) error {
+	name, commit = strings.TrimSpace(name), strings.TrimSpace(commit)
+	if name == "" {
+		return fmt.Errorf("branch not specified")
+	}
+	if commit == "" {
+		return fmt.Errorf("commit not specified")
+	}
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return fmt.Errorf("repository root not set")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.backend.CreateBranch(name, commit, checkout); err != nil {
+		return err
+	}
+	if checkout && s.scan != nil {
+		s.scan.close()
+		s.scan = nil
+	}
+	return nil
+}
+
+// DeleteBranch deletes the local branch name. Unless force is set, git
+// refuses to delete it when it is not merged.
+func (s *Service) DeleteBranch(name string, force bool) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("branch not specified")
+	}
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return fmt.Errorf("repository root not set")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.DeleteBranch(name, force)
+}
+
+func (s *Service) RenameBranch(oldName, newName string) error {
+	oldName, newName = strings.TrimSpace(oldName), strings.TrimSpace(newName)
+	if oldName == "" || newName == "" {
+		return fmt.Errorf("branch not specified")
+	}
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return fmt.Errorf("repository root not set")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.RenameBranch(oldName, newName)
+}
+
+// CreateTag tags commit. A non-empty message makes an annotated tag.
+func (s *Service) CreateTag(name, commit, message string) error {
+	name, commit = strings.TrimSpace(name), strings.TrimSpace(commit)
+	if name == "" {
+		return fmt.Errorf("tag not specified")
+	}
+	if commit == "" {
+		return fmt.Errorf("commit not specified")
+	}
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return fmt.Errorf("repository root not set")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.CreateTag(name, commit, strings.TrimSpace(message))
+}
+
+func (s *Service) DeleteTag(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("tag not specified")
+	}
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return fmt.Errorf("repository root not set")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.DeleteTag(name)
+}
+
+// UnmergedCommits counts the commits of the local branch name that are not
+// reachable from HEAD, which deleting the branch may lose.
+func (s *Service) UnmergedCommits(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, fmt.Errorf("branch not specified")
+	}
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return 0, fmt.Errorf("repository root not set")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.CountCommits([]string{"HEAD..refs/heads/" + name})
+}
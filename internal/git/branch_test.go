@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"slices"
 	"testing"
 
@@ -79,6 +80,58 @@ func TestSwitchBranch_CallsBackendAndClearsScan(t *testing.T) {
 	}
 }
 
+func TestSwitchDetachedAndTracking_CallBackendAndClearScan(t *testing.T) {
+	var detached, tracking, remote string
+	svc := NewWithBackend(&fakeBackend{
+		repoPath: "repo",
+		switchDetachedFunc: func(rev string) error {
+			detached = rev
+			return nil
+		},
+		createTrackingFunc: func(name string, remoteBranch string) error {
+			tracking, remote = name, remoteBranch
+			return nil
+		},
+	})
+
+	svc.scan = &scanSession{}
+	if err := svc.SwitchDetached(" v1 "); err != nil {
+		t.Fatalf("SwitchDetached() error = %v", err)
+	}
+	if detached != "v1" || svc.scan != nil {
+		t.Fatalf("detached = %q, scan = %v; want v1 and no scan", detached, svc.scan)
+	}
+	svc.scan = &scanSession{}
+	if err := svc.CreateTrackingBranch("feature", "origin/feature"); err != nil {
+		t.Fatalf("CreateTrackingBranch() error = %v", err)
+	}
+	if tracking != "feature" || remote != "origin/feature" || svc.scan != nil {
+		t.Fatalf("tracking = %q from %q, scan = %v", tracking, remote, svc.scan)
+	}
+	if err := svc.CreateTrackingBranch(" ", "origin/feature"); err == nil {
+		t.Fatalf("expected an error without a branch name")
+	}
+}
+
+func TestBranchLabels_DetachedHead(t *testing.T) {
+	svc := NewWithBackend(&fakeBackend{
+		repoPath: "repo",
+		listRefsFunc: func() ([]gitbackend.Ref, error) {
+			return []gitbackend.Ref{{Hash: "abc", Kind: gitbackend.RefKindTag, Name: "v1"}}, nil
+		},
+		headStateFunc: func() (string, string, bool, error) {
+			return "abc", "HEAD", true, nil
+		},
+	})
+	labels, err := svc.BranchLabels()
+	if err != nil {
+		t.Fatalf("BranchLabels() error = %v", err)
+	}
+	if want := []string{"HEAD (detached)", "tag: v1"}; !slices.Equal(labels["abc"], want) {
+		t.Fatalf("labels = %v, want %v", labels["abc"], want)
+	}
+}
+
 func TestSwitchBranch_ChangesHead(t *testing.T) {
 	dir, _ := createTestRepo(t, 2)
 
@@ -93,7 +146,7 @@ func TestSwitchBranch_ChangesHead(t *testing.T) {
 	if err := svc.SwitchBranch("feature"); err != nil {
 		t.Fatalf("SwitchBranch: %v", err)
 	}
-	_, head, _, err := svc.ScanCommits(0, 1)
+	_, head, _, err := svc.ScanCommits(LogSpec{}, 0, 1)
 	if err != nil {
 		t.Fatalf("ScanCommits: %v", err)
 	}
@@ -101,3 +154,150 @@ func TestSwitchBranch_ChangesHead(t *testing.T) {
 		t.Fatalf("head = %q, want %q", head, "feature")
 	}
 }
+
+func TestListRefs_SortsAndIncludesStashes(t *testing.T) {
+	fb := &fakeBackend{
+		repoPath: "/repo",
+		listRefsFunc: func() ([]gitbackend.Ref, error) {
+			return []gitbackend.Ref{
+				{Hash: "t1", Kind: gitbackend.RefKindTag, Name: "v1"},
+				{Hash: "b2", Kind: gitbackend.RefKindBranch, Name: "topic"},
+				{Hash: "r1", Kind: gitbackend.RefKindRemoteBranch, Name: "origin/main"},
+				{Hash: "b1", Kind: gitbackend.RefKindBranch, Name: "main"},
+			}, nil
+		},
+		listStashesFunc: func() ([]gitbackend.Stash, error) {
+			return []gitbackend.Stash{{Hash: "s0", Name: "stash@{0}", Message: "WIP on main"}}, nil
+		},
+		headStateFunc: func() (string, string, bool, error) { return "", "", false, nil },
+	}
+	svc := NewWithBackend(fb)
+	list, err := svc.ListRefs()
+	if err != nil {
+		t.Fatalf("ListRefs: %v", err)
+	}
+	var names []string
+	for _, ref := range list.Refs {
+		names = append(names, ref.Name)
+	}
+	if want := []string{"main", "topic", "origin/main", "v1"}; !slices.Equal(names, want) {
+		t.Fatalf("refs = %v, want %v", names, want)
+	}
+	if len(list.Stashes) != 1 || list.Stashes[0].Name != "stash@{0}" {
+		t.Fatalf("unexpected stashes %+v", list.Stashes)
+	}
+	if list.HeadName != "HEAD" {
+		t.Fatalf("HeadName = %q, want HEAD for an unborn branch", list.HeadName)
+	}
+}
+
+func TestResolveRevision_TrimsAndRejectsEmpty(t *testing.T) {
+	fb := &fakeBackend{
+		repoPath: "/repo",
+		resolveRevisionFunc: func(rev string) (string, error) {
+			if rev != "HEAD~2" {
+				t.Fatalf("backend got %q", rev)
+			}
+			return "abc", nil
+		},
+	}
+	svc := NewWithBackend(fb)
+	hash, err := svc.ResolveRevision("  HEAD~2 ")
+	if err != nil || hash != "abc" {
+		t.Fatalf("ResolveRevision = %q, %v", hash, err)
+	}
+	if _, err := svc.ResolveRevision(" "); err == nil {
+		t.Fatalf("expected error for empty revision")
+	}
+}
+
+func TestLogContains_PassesSpec(t *testing.T) {
+	spec := LogSpec{Revisions: []string{"main..feature"}, Paths: []string{"dir"}}
+	fb := &fakeBackend{
+		repoPath: "/repo",
+		logContainsFunc: func(got LogSpec, commitHash string) (bool, error) {
+			if !got.Equal(spec) || commitHash != "abc" {
+				t.Fatalf("backend got %q %q", got, commitHash)
+			}
+			return true, nil
+		},
+	}
+	svc := NewWithBackend(fb)
+	if ok, err := svc.LogContains(spec, " abc "); err != nil || !ok {
+		t.Fatalf("LogContains = %v, %v", ok, err)
+	}
+	if _, err := svc.LogContains(spec, ""); err == nil {
+		t.Fatalf("expected error for empty revision")
+	}
+}
+
+func TestCreateBranch_ClearsScanOnCheckout(t *testing.T) {
+	var got []string
+	f := &fakeBackend{
+		repoPath: "repo",
+		createBranchFunc: func(name string, commitHash string, checkout bool) error {
+			got = append(got, fmt.Sprintf("%s@%s checkout=%v", name, commitHash, checkout))
+			return nil
+		},
+	}
+	svc := NewWithBackend(f)
+	svc.scan = &scanSession{}
+
+	if err := svc.CreateBranch(" topic ", "abc", false); err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+	if svc.scan == nil {
+		t.Fatalf("expected scan session to be kept without checkout")
+	}
+	if err := svc.CreateBranch("topic2", "abc", true); err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+	if svc.scan != nil {
+		t.Fatalf("expected scan session to be cleared after checkout")
+	}
+	want := []string{"topic@abc checkout=false", "topic2@abc checkout=true"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("backend calls = %q, want %q", got, want)
+	}
+	if err := svc.CreateBranch("", "abc", false); err == nil {
+		t.Fatalf("expected an error without a branch name")
+	}
+}
+
+func TestCreateTag_TrimsMessage(t *testing.T) {
+	var gotMessage string
+	svc := NewWithBackend(&fakeBackend{
+		repoPath: "repo",
+		createTagFunc: func(name string, commitHash string, message string) error {
+			gotMessage = message
+			return nil
+		},
+	})
+	if err := svc.CreateTag("v1", "abc", "  \n"); err != nil {
+		t.Fatalf("CreateTag() error = %v", err)
+	}
+	if gotMessage != "" {
+		t.Fatalf("message = %q, want a lightweight tag", gotMessage)
+	}
+}
+
+func TestUnmergedCommits_CountsCommitsMissingFromHead(t *testing.T) {
+	var gotRevisions []string
+	svc := NewWithBackend(&fakeBackend{
+		repoPath: "repo",
+		countCommitsFunc: func(revisions []string) (int, error) {
+			gotRevisions = revisions
+			return 2, nil
+		},
+	})
+	n, err := svc.UnmergedCommits("feature")
+	if err != nil {
+		t.Fatalf("UnmergedCommits() error = %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("UnmergedCommits() = %d, want 2", n)
+	}
+	if !slices.Equal(gotRevisions, []string{"HEAD..refs/heads/feature"}) {
+		t.Fatalf("unexpected revisions %q", gotRevisions)
+	}
+}
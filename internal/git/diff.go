@@ -5,18 +5,109 @@ import (
 	"strings"
 )
 
-func (s *Service) Diff(commit *Commit) (string, []FileSection, error) {
+// MergeDiffMode selects the diff shown for merge commits: against one of
+// their parents or combined against all of them. The zero value diffs
+// against the first parent, like for any other commit.
+type MergeDiffMode int
+
+const (
+	MergeDiffFirstParent MergeDiffMode = iota
+	// MergeDiffCombined shows the dense combined diff, which leaves out the
+	// hunks taken as is from one of the parents, so only the conflict
+	// resolutions remain.
+	MergeDiffCombined
+)
+
+// MergeDiffParent returns the mode diffing merges against their n-th parent,
+// counting from 1.
+func MergeDiffParent(n int) MergeDiffMode {
+	if n <= 1 {
+		return MergeDiffFirstParent
+	}
+	return MergeDiffMode(n)
+}
+
+// Parent returns the parent, counting from 1, that merges are diffed
+// against, or 0 for the combined diff.
+func (m MergeDiffMode) Parent() int {
+	switch {
+	case m == MergeDiffCombined:
+		return 0
+	case m < MergeDiffCombined:
+		return 1
+	default:
+		return int(m)
+	}
+}
+
+func (m MergeDiffMode) String() string {
+	switch parent := m.Parent(); parent {
+	case 0:
+		return "combined"
+	case 1:
+		return "first parent"
+	default:
+		return fmt.Sprintf("parent %d", parent)
+	}
+}
+
+// Diff returns the header of commit followed by its diff against its first
+// parent. rel adds the relation lines to the header when not nil. Merges are
+// diffed as selected by mode, which is noted below the header. Commits read
+// while following a file only show the changes to that file.
+func (s *Service) Diff(commit *Commit, rel *CommitRelations, mode MergeDiffMode, opts DiffOptions) (string, []FileSection, error) {
 	if commit == nil {
 		return "", nil, fmt.Errorf("commit not specified")
 	}
-	header := FormatCommitHeader(commit)
-	diffText, err := s.commitDiffText(commit)
+	header := FormatCommitHeaderWith(commit, rel)
+	mode = mergeDiffModeFor(commit, mode)
+	if len(commit.ParentHashes) > 1 {
+		header = strings.TrimRight(header, "\n") + "\n\n" + mergeDiffNote(commit, mode) + "\n"
+	}
+	diffText, err := s.commitDiffText(commit, mode, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	if commit.Path != "" {
+		diffText = limitDiffToPath(diffText, commit.Path)
+		if strings.TrimSpace(diffText) == "" {
+			return header + "\nNo changes to " + commit.Path + ".", nil, nil
+		}
+	}
+	if strings.TrimSpace(diffText) == "" {
+		if mode == MergeDiffCombined {
+			return header + "\nNo changes besides the ones taken from the parents.", nil, nil
+		}
+		return header + "\nNo file level changes.", nil, nil
+	}
+	text, sections := joinDiff(header, diffText)
+	return text, sections, nil
+}
+
+// CompareDiff returns the diff between two commits, from the tree of from to
+// the tree of to.
+func (s *Service) CompareDiff(from, to string, opts DiffOptions) (string, []FileSection, error) {
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return "", nil, fmt.Errorf("repository root not set")
+	}
+	if from == "" || to == "" {
+		return "", nil, fmt.Errorf("commit not specified")
+	}
+	header := fmt.Sprintf("Diff from %s\n       to %s\n", from, to)
+	diffText, err := s.backend.CommitDiffText(to, from, opts)
 	if err != nil {
 		return "", nil, err
 	}
 	if strings.TrimSpace(diffText) == "" {
 		return header + "\nNo file level changes.", nil, nil
 	}
+	text, sections := joinDiff(header, diffText)
+	return text, sections, nil
+}
+
+// joinDiff appends diffText to header, returning the file sections with line
+// numbers relative to the joined text.
+func joinDiff(header, diffText string) (string, []FileSection) {
 	if !strings.HasSuffix(header, "\n") {
 		header += "\n"
 	}
@@ -27,33 +118,149 @@ func (s *Service) Diff(commit *Commit) (string, []FileSection, error) {
 		b.WriteByte('\n')
 	}
 	lineOffset := strings.Count(header, "\n")
-	sections := parseGitDiffSections(diffText, lineOffset)
-	return b.String(), sections, nil
+	return b.String(), parseGitDiffSections(diffText, lineOffset)
 }
 
-func (s *Service) commitDiffText(commit *Commit) (string, error) {
+// limitDiffToPath keeps the file diffs of diffText whose old or new name is
+// path.
+func limitDiffToPath(diffText, path string) string {
+	var b, file strings.Builder
+	flush := func() {
+		for _, sec := range parseGitDiffSections(file.String(), 0) {
+			if sec.Path == path || sec.OldPath == path {
+				b.WriteString(file.String())
+				break
+			}
+		}
+		file.Reset()
+	}
+	for line := range strings.SplitAfterSeq(diffText, "\n") {
+		if strings.HasPrefix(line, "diff --") {
+			flush()
+		}
+		file.WriteString(line)
+	}
+	flush()
+	return b.String()
+}
+
+// mergeDiffModeFor returns the mode commit is diffed with: the first parent
+// unless commit is a merge with the parent mode asks for.
+func mergeDiffModeFor(commit *Commit, mode MergeDiffMode) MergeDiffMode {
+	if len(commit.ParentHashes) < 2 || mode.Parent() > len(commit.ParentHashes) {
+		return MergeDiffFirstParent
+	}
+	return mode
+}
+
+func mergeDiffNote(commit *Commit, mode MergeDiffMode) string {
+	if mode == MergeDiffCombined {
+		return fmt.Sprintf("Merge diff: combined against all %d parents", len(commit.ParentHashes))
+	}
+	return fmt.Sprintf("Merge diff: %s %s", mode, commit.ParentHashes[mode.Parent()-1])
+}
+
+func (s *Service) commitDiffText(commit *Commit, mode MergeDiffMode, opts DiffOptions) (string, error) {
+	if mode == MergeDiffCombined {
+		return s.backend.CombinedDiffText(commit.Hash, opts)
+	}
 	if len(commit.ParentHashes) > 0 {
-		parent := commit.ParentHashes[0]
-		return s.backend.CommitDiffText(commit.Hash, parent)
+		parent := commit.ParentHashes[mode.Parent()-1]
+		return s.backend.CommitDiffText(commit.Hash, parent, opts)
 	}
-	return s.backend.CommitDiffText(commit.Hash, "")
+	return s.backend.CommitDiffText(commit.Hash, "", opts)
 }
 
+// parseGitDiffSections splits diffText in file sections, reading their
+// change type from the extended headers and counting their changed lines.
 func parseGitDiffSections(diffText string, lineOffset int) []FileSection {
 	lines := strings.Split(diffText, "\n")
 	var sections []FileSection
+	var cur *FileSection
+	// cols is the number of marker columns of the current hunk, or 0 outside
+	// hunks.
+	cols := 0
 	for i, line := range lines {
-		if !strings.HasPrefix(line, "diff --git ") {
+		if strings.HasPrefix(line, "diff --") {
+			cols = 0
+			cur = nil
+			if path := parseGitDiffPath(line); path != "" {
+				sections = append(sections, FileSection{Path: path, Line: lineOffset + i + 1, Status: "M"})
+				cur = &sections[len(sections)-1]
+			}
 			continue
 		}
-		if path := parseGitDiffPath(line); path != "" {
-			sections = append(sections, FileSection{Path: path, Line: lineOffset + i + 1})
+		if cur == nil {
+			continue
+		}
+		if n := len(line) - len(strings.TrimLeft(line, "@")); n >= 2 {
+			cols = n - 1
+			continue
+		}
+		if cols > 0 {
+			if len(line) < cols || strings.Trim(line[:cols], "+- ") != "" {
+				continue
+			}
+			switch markers := line[:cols]; {
+			case strings.Contains(markers, "+"):
+				cur.Added++
+			case strings.Contains(markers, "-"):
+				cur.Deleted++
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "new file mode "):
+			cur.Status = "A"
+		case strings.HasPrefix(line, "deleted file mode "):
+			cur.Status = "D"
+		case strings.HasPrefix(line, "rename from "):
+			cur.Status, cur.OldPath = "R", unquoteDiffPath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "copy from "):
+			cur.Status, cur.OldPath = "C", unquoteDiffPath(strings.TrimPrefix(line, "copy from "))
+		case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+			cur.Binary = true
 		}
 	}
-	return sections
+	return mergeTypeChanges(sections)
 }
 
+// mergeTypeChanges joins the deletion and addition git writes for a file
+// whose type changed, such as a file replaced by a symlink.
+func mergeTypeChanges(sections []FileSection) []FileSection {
+	out := sections[:0]
+	for _, sec := range sections {
+		if n := len(out); n > 0 && sec.Status == "A" && out[n-1].Status == "D" && out[n-1].Path == sec.Path {
+			prev := &out[n-1]
+			prev.Status = "T"
+			prev.Added += sec.Added
+			prev.Deleted += sec.Deleted
+			prev.Binary = prev.Binary || sec.Binary
+			continue
+		}
+		out = append(out, sec)
+	}
+	return out
+}
+
+func unquoteDiffPath(path string) string {
+	if tokens := diffLineTokens(path); strings.HasPrefix(path, "\"") && len(tokens) > 0 {
+		return tokens[0]
+	}
+	return path
+}
+
+// parseGitDiffPath returns the path of a "diff --git" line or of the
+// "diff --cc" line starting a file in a combined diff.
 func parseGitDiffPath(line string) string {
+	for _, prefix := range []string{"diff --cc ", "diff --combined "} {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			if tokens := diffLineTokens(strings.TrimSpace(rest)); len(tokens) > 0 {
+				return tokens[0]
+			}
+			return ""
+		}
+	}
 	const prefix = "diff --git "
 	if !strings.HasPrefix(line, prefix) {
 		return ""
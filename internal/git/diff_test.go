@@ -1,6 +1,10 @@
 package git
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -33,6 +37,79 @@ func TestParseGitDiffSections(t *testing.T) {
 	}
 }
 
+func TestParseGitDiffSections_StatusAndStats(t *testing.T) {
+	t.Parallel()
+
+	diffText := strings.Join([]string{
+		"diff --git a/mod.txt b/mod.txt",
+		"index 1111111..2222222 100644",
+		"--- a/mod.txt",
+		"+++ b/mod.txt",
+		"@@ -1,3 +1,3 @@",
+		" keep",
+		"-old",
+		"+new",
+		"+more",
+		"\\ No newline at end of file",
+		"diff --git a/new.txt b/new.txt",
+		"new file mode 100644",
+		"--- /dev/null",
+		"+++ b/new.txt",
+		"@@ -0,0 +1 @@",
+		"+hello",
+		"diff --git a/gone.txt b/gone.txt",
+		"deleted file mode 100644",
+		"--- a/gone.txt",
+		"+++ /dev/null",
+		"@@ -1,2 +0,0 @@",
+		"-a",
+		"-b",
+		"diff --git a/old.txt b/renamed.txt",
+		"similarity index 100%",
+		"rename from old.txt",
+		"rename to renamed.txt",
+		"diff --git a/src.txt b/copy.txt",
+		"similarity index 90%",
+		"copy from src.txt",
+		"copy to copy.txt",
+		"diff --git a/img.png b/img.png",
+		"index 3333333..4444444 100644",
+		"Binary files a/img.png and b/img.png differ",
+		"diff --git a/link b/link",
+		"deleted file mode 100644",
+		"--- a/link",
+		"+++ /dev/null",
+		"@@ -1 +0,0 @@",
+		"-target",
+		"diff --git a/link b/link",
+		"new file mode 120000",
+		"--- /dev/null",
+		"+++ b/link",
+		"@@ -0,0 +1 @@",
+		"+target",
+		"diff --cc both.txt",
+		"@@@ -1,1 -1,1 +1,1 @@@",
+		"- ours",
+		" -theirs",
+		"++resolved",
+	}, "\n")
+
+	got := parseGitDiffSections(diffText, 0)
+	want := []FileSection{
+		{Path: "mod.txt", Line: 1, Status: "M", Added: 2, Deleted: 1},
+		{Path: "new.txt", Line: 11, Status: "A", Added: 1},
+		{Path: "gone.txt", Line: 17, Status: "D", Deleted: 2},
+		{Path: "renamed.txt", Line: 24, Status: "R", OldPath: "old.txt"},
+		{Path: "copy.txt", Line: 28, Status: "C", OldPath: "src.txt"},
+		{Path: "img.png", Line: 32, Status: "M", Binary: true},
+		{Path: "link", Line: 35, Status: "T", Added: 1, Deleted: 1},
+		{Path: "both.txt", Line: 47, Status: "M", Added: 1, Deleted: 2},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("sections =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
 func TestDiff_NoFileLevelChanges(t *testing.T) {
 	t.Parallel()
 
@@ -60,7 +137,7 @@ func TestDiff_NoFileLevelChanges(t *testing.T) {
 		Message: "msg",
 	}
 
-	diff, sections, err := svc.Diff(commit)
+	diff, sections, err := svc.Diff(commit, nil, MergeDiffFirstParent, DiffOptions{})
 	if err != nil {
 		t.Fatalf("Diff: %v", err)
 	}
@@ -93,7 +170,7 @@ func TestDiff_PassesParentHashToBackend(t *testing.T) {
 		Message:      "msg",
 	}
 
-	diff, sections, err := svc.Diff(commit)
+	diff, sections, err := svc.Diff(commit, nil, MergeDiffFirstParent, DiffOptions{})
 	if err != nil {
 		t.Fatalf("Diff: %v", err)
 	}
@@ -107,3 +184,208 @@ func TestDiff_PassesParentHashToBackend(t *testing.T) {
 		t.Fatalf("unexpected sections: %+v", sections)
 	}
 }
+
+func TestDiff_LimitedToFollowedFile(t *testing.T) {
+	t.Parallel()
+
+	diffText := strings.Join([]string{
+		"diff --git a/old.txt b/new.txt",
+		"similarity index 90%",
+		"rename from old.txt",
+		"rename to new.txt",
+		"@@ -1 +1 @@",
+		"-a",
+		"+b",
+		"diff --git a/other.txt b/other.txt",
+		"@@ -1 +1 @@",
+		"-c",
+		"+d",
+		"",
+	}, "\n")
+	backend := &fakeBackend{
+		repoPath: "repo",
+		commitDiffTextFunc: func(commitHash string, parentHash string) (string, error) {
+			return diffText, nil
+		},
+	}
+	svc := NewWithBackend(backend)
+
+	for _, path := range []string{"new.txt", "old.txt"} {
+		commit := &Commit{Hash: "aaaa", ParentHashes: []string{"bbbb"}, Message: "msg", Path: path}
+		diff, sections, err := svc.Diff(commit, nil, MergeDiffFirstParent, DiffOptions{})
+		if err != nil {
+			t.Fatalf("Diff: %v", err)
+		}
+		if strings.Contains(diff, "other.txt") {
+			t.Fatalf("expected only the followed file, got:\n%s", diff)
+		}
+		if len(sections) != 1 || sections[0].Path != "new.txt" || sections[0].OldPath != "old.txt" || sections[0].Added != 1 {
+			t.Fatalf("unexpected sections: %+v", sections)
+		}
+	}
+
+	commit := &Commit{Hash: "aaaa", ParentHashes: []string{"bbbb"}, Message: "msg", Path: "missing.txt"}
+	diff, sections, err := svc.Diff(commit, nil, MergeDiffFirstParent, DiffOptions{})
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(sections) != 0 || !strings.HasSuffix(diff, "No changes to missing.txt.") {
+		t.Fatalf("unexpected diff %q with sections %+v", diff, sections)
+	}
+}
+
+func TestCompareDiff_DiffsFromFirstToSecond(t *testing.T) {
+	t.Parallel()
+
+	backend := &fakeBackend{
+		repoPath: "repo",
+		commitDiffTextFunc: func(commitHash string, parentHash string) (string, error) {
+			return "diff --git a/foo.txt b/foo.txt\n", nil
+		},
+	}
+	svc := NewWithBackend(backend)
+
+	diff, sections, err := svc.CompareDiff("head", "topic", DiffOptions{})
+	if err != nil {
+		t.Fatalf("CompareDiff: %v", err)
+	}
+	if backend.lastCommitHash != "topic" || backend.lastParentHash != "head" {
+		t.Fatalf("backend called with commit=%q parent=%q", backend.lastCommitHash, backend.lastParentHash)
+	}
+	if !strings.HasPrefix(diff, "Diff from head\n") {
+		t.Fatalf("unexpected header:\n%s", diff)
+	}
+	if len(sections) != 1 || sections[0].Path != "foo.txt" || sections[0].Line != 3 {
+		t.Fatalf("unexpected sections: %+v", sections)
+	}
+}
+
+func TestDiff_MergeModes(t *testing.T) {
+	t.Parallel()
+
+	first := "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	second := "cccccccccccccccccccccccccccccccccccccccc"
+	commit := &Commit{
+		Hash:         "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		ParentHashes: []string{first, second},
+		Message:      "Merge branch 'topic'",
+	}
+
+	tests := []struct {
+		mode       MergeDiffMode
+		wantParent string
+		wantNote   string
+	}{
+		{MergeDiffFirstParent, first, "Merge diff: first parent " + first},
+		{MergeDiffParent(2), second, "Merge diff: parent 2 " + second},
+		{MergeDiffParent(3), first, "Merge diff: first parent " + first},
+	}
+	for _, tt := range tests {
+		backend := &fakeBackend{
+			repoPath: "repo",
+			commitDiffTextFunc: func(commitHash string, parentHash string) (string, error) {
+				return "diff --git a/foo.txt b/foo.txt\n", nil
+			},
+		}
+		diff, _, err := NewWithBackend(backend).Diff(commit, nil, tt.mode, DiffOptions{})
+		if err != nil {
+			t.Fatalf("Diff(%v): %v", tt.mode, err)
+		}
+		if backend.lastParentHash != tt.wantParent {
+			t.Fatalf("Diff(%v) parent = %q, want %q", tt.mode, backend.lastParentHash, tt.wantParent)
+		}
+		if !strings.Contains(diff, tt.wantNote) {
+			t.Fatalf("Diff(%v) missing %q:\n%s", tt.mode, tt.wantNote, diff)
+		}
+	}
+
+	backend := &fakeBackend{
+		repoPath: "repo",
+		combinedDiffTextFunc: func(commitHash string) (string, error) {
+			return "diff --cc foo.txt\nindex 1,2..3\n--- a/foo.txt\n+++ b/foo.txt\n@@@ -1,1 -1,1 +1,1 @@@\n- a\n -b\n++c\n", nil
+		},
+	}
+	diff, sections, err := NewWithBackend(backend).Diff(commit, nil, MergeDiffCombined, DiffOptions{})
+	if err != nil {
+		t.Fatalf("Diff(combined): %v", err)
+	}
+	if !strings.Contains(diff, "Merge diff: combined against all 2 parents") {
+		t.Fatalf("missing combined note:\n%s", diff)
+	}
+	if len(sections) != 1 || sections[0].Path != "foo.txt" {
+		t.Fatalf("unexpected sections: %+v", sections)
+	}
+	lines := strings.Split(diff, "\n")
+	if got := lines[sections[0].Line-1]; got != "diff --cc foo.txt" {
+		t.Fatalf("section line = %q", got)
+	}
+}
+
+func TestDiff_CombinedShowsConflictResolution(t *testing.T) {
+	dir, _ := createTestRepo(t, 1)
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	runGit(t, dir, nil, "checkout", "-q", "-b", "topic")
+	writeFile("file.txt", "topic\n")
+	runGit(t, dir, nil, "commit", "-qam", "topic", "--no-gpg-sign")
+	runGit(t, dir, nil, "checkout", "-q", "main")
+	writeFile("file.txt", "main\n")
+	writeFile("other.txt", "clean\n")
+	runGit(t, dir, nil, "add", "other.txt")
+	runGit(t, dir, nil, "commit", "-qam", "main", "--no-gpg-sign")
+	cmd := exec.Command("git", "-C", dir, "merge", "-q", "topic")
+	_ = cmd.Run() // conflicts
+	writeFile("file.txt", "resolved\n")
+	runGit(t, dir, nil, "commit", "-qam", "merge", "--no-gpg-sign")
+	hash := runGit(t, dir, nil, "rev-parse", "HEAD")
+	parents := strings.Fields(runGit(t, dir, nil, "rev-parse", "HEAD^1", "HEAD^2"))
+
+	svc, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	commit := &Commit{Hash: hash, ParentHashes: parents, Message: "merge"}
+	_, sections, err := svc.Diff(commit, nil, MergeDiffCombined, DiffOptions{})
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(sections) != 1 || sections[0].Path != "file.txt" {
+		t.Fatalf("combined diff sections = %+v, want only the conflicted file.txt", sections)
+	}
+	_, sections, err = svc.Diff(commit, nil, MergeDiffParent(2), DiffOptions{})
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(sections) != 2 {
+		t.Fatalf("diff against parent 2 sections = %+v, want file.txt and other.txt", sections)
+	}
+}
+
+func TestCompareDiff_IgnoresWhitespace(t *testing.T) {
+	dir, hashes := createTestRepo(t, 1)
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("commit  0\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	runGit(t, dir, nil, "commit", "-qam", "spacing", "--no-gpg-sign")
+	head := runGit(t, dir, nil, "rev-parse", "HEAD")
+
+	svc, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	_, sections, err := svc.CompareDiff(hashes[0], head, DiffOptions{})
+	if err != nil || len(sections) != 1 {
+		t.Fatalf("CompareDiff = %+v, %v; want one section", sections, err)
+	}
+	diff, sections, err := svc.CompareDiff(hashes[0], head, DiffOptions{Whitespace: WhitespaceIgnoreChange})
+	if err != nil {
+		t.Fatalf("CompareDiff: %v", err)
+	}
+	if len(sections) != 0 && strings.Contains(diff, "@@") {
+		t.Fatalf("expected no hunks when ignoring whitespace changes, got:\n%s", diff)
+	}
+}
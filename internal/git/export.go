@@ -0,0 +1,175 @@
+package git
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExportFormat selects how Export writes commits.
+type ExportFormat uint8
+
+const (
+	// ExportText writes one line per commit like
+	// `git log --graph --oneline --decorate`.
+	ExportText ExportFormat = iota
+	// ExportJSON writes one JSON object per commit, including the graph lanes
+	// and edges.
+	ExportJSON
+)
+
+func (f ExportFormat) String() string {
+	switch f {
+	case ExportText:
+		return "text"
+	case ExportJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("ExportFormat(%d)", uint8(f))
+	}
+}
+
+func ExportFormatFromString(raw string) (ExportFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", ExportText.String():
+		return ExportText, nil
+	case ExportJSON.String():
+		return ExportJSON, nil
+	default:
+		return ExportText, fmt.Errorf("unknown export format %q (want text or json)", raw)
+	}
+}
+
+func (k GraphEdgeKind) String() string {
+	switch k {
+	case GraphEdgeContinue:
+		return "continue"
+	case GraphEdgeFork:
+		return "fork"
+	case GraphEdgeMerge:
+		return "merge"
+	default:
+		return fmt.Sprintf("GraphEdgeKind(%d)", uint8(k))
+	}
+}
+
+type exportSignature struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	When  time.Time `json:"date"`
+}
+
+type exportEdge struct {
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	Kind  string `json:"kind"`
+	Color int    `json:"color"`
+}
+
+type exportGraph struct {
+	Column int          `json:"column"`
+	Color  int          `json:"color"`
+	Lanes  int          `json:"lanes"`
+	Edges  []exportEdge `json:"edges"`
+}
+
+type exportCommit struct {
+	Hash      string          `json:"hash"`
+	Parents   []string        `json:"parents"`
+	Refs      []string        `json:"refs"`
+	Author    exportSignature `json:"author"`
+	Committer exportSignature `json:"committer"`
+	Subject   string          `json:"subject"`
+	Message   string          `json:"message"`
+	Graph     *exportGraph    `json:"graph,omitempty"`
+}
+
+// Export writes up to limit commits selected by spec to w, with the same
+// graph layout and ref labels the GUI shows. A zero limit exports the whole
+// history. Commits are scanned and written in batches.
+func (s *Service) Export(w io.Writer, spec LogSpec, format ExportFormat, limit uint) error {
+	labels, err := s.BranchLabels()
+	if err != nil {
+		return fmt.Errorf("list refs: %w", err)
+	}
+	bw := bufio.NewWriter(w)
+	var skip uint
+	for limit == 0 || skip < limit {
+		batch := uint(DefaultBatch)
+		if limit > 0 {
+			batch = min(batch, limit-skip)
+		}
+		entries, _, hasMore, err := s.ScanCommits(spec, skip, batch)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := writeExportEntry(bw, entry, labels[entry.Commit.Hash], format); err != nil {
+				return err
+			}
+		}
+		skip += uint(len(entries))
+		if !hasMore || len(entries) == 0 {
+			break
+		}
+	}
+	return bw.Flush()
+}
+
+// writeExportEntry writes entry in format.
+func writeExportEntry(w io.Writer, entry *Entry, refs []string, format ExportFormat) error {
+	c := entry.Commit
+	subject := strings.SplitN(strings.TrimSpace(c.Message), "\n", 2)[0]
+	if format == ExportText {
+		hash := c.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		line := fmt.Sprintf("%s %s", entry.Graph, hash)
+		if len(refs) > 0 {
+			line += fmt.Sprintf(" (%s)", strings.Join(refs, ", "))
+		}
+		if _, err := fmt.Fprintf(w, "%s %s\n", line, subject); err != nil {
+			return err
+		}
+		// Edges changing column go on a line of their own, like git log
+		// --graph draws them.
+		if connectors := entry.GraphRow.Connectors(); connectors != "" {
+			if _, err := fmt.Fprintln(w, connectors); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	rec := exportCommit{
+		Hash:      c.Hash,
+		Parents:   c.ParentHashes,
+		Refs:      refs,
+		Author:    exportSignature(c.Author),
+		Committer: exportSignature(c.Committer),
+		Subject:   subject,
+		Message:   c.Message,
+	}
+	if rec.Parents == nil {
+		rec.Parents = []string{}
+	}
+	if rec.Refs == nil {
+		rec.Refs = []string{}
+	}
+	if row := entry.GraphRow; row != nil {
+		rec.Graph = &exportGraph{Column: row.Column, Color: row.Color, Lanes: row.Lanes, Edges: []exportEdge{}}
+		for _, e := range row.Edges {
+			rec.Graph.Edges = append(rec.Graph.Edges, exportEdge{From: e.From, To: e.To, Kind: e.Kind.String(), Color: e.Color})
+		}
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportText(t *testing.T) {
+	dir, hashes := createTestRepo(t, 3)
+	runGit(t, dir, nil, "tag", "v1", hashes[1])
+	svc, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	svc.SetGraphMaxColumns(2)
+	var buf bytes.Buffer
+	if err := svc.Export(&buf, LogSpec{}, ExportText, 0); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	want := strings.Join([]string{
+		"* " + hashes[0][:7] + " (HEAD -> main, main) commit 2",
+		"* " + hashes[1][:7] + " (tag: v1) commit 1",
+		"* " + hashes[2][:7] + " commit 0",
+	}, "\n") + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteExportEntryConnectors(t *testing.T) {
+	// A merges B and C, which both fork from D.
+	commits := []*Commit{
+		{Hash: "aaaaaaaaaa", ParentHashes: []string{"bbbbbbbbbb", "cccccccccc"}, Message: "merge"},
+		{Hash: "bbbbbbbbbb", ParentHashes: []string{"dddddddddd"}, Message: "main"},
+		{Hash: "cccccccccc", ParentHashes: []string{"dddddddddd"}, Message: "side"},
+		{Hash: "dddddddddd", Message: "base"},
+	}
+	builder := newGraphBuilder(DefaultGraphMaxColumns)
+	var buf bytes.Buffer
+	for _, c := range commits {
+		row := builder.Row(c)
+		entry := &Entry{Commit: c, Graph: row.String(), GraphRow: row}
+		if err := writeExportEntry(&buf, entry, nil, ExportText); err != nil {
+			t.Fatalf("writeExportEntry: %v", err)
+		}
+	}
+	want := "* aaaaaaa merge\n|\\\n* | bbbbbbb main\n| * ccccccc side\n|/\n* ddddddd base\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExportEnumStrings(t *testing.T) {
+	if got := ExportFormat(9).String(); got != "ExportFormat(9)" {
+		t.Fatalf("ExportFormat(9).String() = %q", got)
+	}
+	if got := GraphEdgeKind(9).String(); got != "GraphEdgeKind(9)" {
+		t.Fatalf("GraphEdgeKind(9).String() = %q", got)
+	}
+	if got := GraphEdgeMerge.String(); got != "merge" {
+		t.Fatalf("GraphEdgeMerge.String() = %q", got)
+	}
+}
+
+func TestExportJSONLimit(t *testing.T) {
+	dir, hashes := createTestRepo(t, 3)
+	svc, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := svc.Export(&buf, LogSpec{}, ExportJSON, 2); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+	var rec exportCommit
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if rec.Hash != hashes[0] || rec.Subject != "commit 2" || rec.Author.Name != "Alice" {
+		t.Fatalf("unexpected record %+v", rec)
+	}
+	if len(rec.Parents) != 1 || rec.Parents[0] != hashes[1] {
+		t.Fatalf("unexpected parents %v", rec.Parents)
+	}
+	want := exportEdge{From: 0, To: 0, Kind: "continue", Color: 0}
+	if rec.Graph == nil || rec.Graph.Lanes != 1 || len(rec.Graph.Edges) != 1 || rec.Graph.Edges[0] != want {
+		t.Fatalf("unexpected graph %+v", rec.Graph)
+	}
+}
+
+func TestExportFormatFromString(t *testing.T) {
+	for raw, want := range map[string]ExportFormat{"": ExportText, "text": ExportText, " JSON ": ExportJSON} {
+		got, err := ExportFormatFromString(raw)
+		if err != nil || got != want {
+			t.Fatalf("ExportFormatFromString(%q) = %v, %v", raw, got, err)
+		}
+	}
+	if _, err := ExportFormatFromString("xml"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
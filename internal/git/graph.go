@@ -0,0 +1,206 @@
+package git
+
+import (
+	"slices"
+	"strings"
+)
+
+// GraphEdgeKind describes how a lane connects a row to the row below it.
+type GraphEdgeKind uint8
+
+const (
+	// GraphEdgeContinue is a lane carried on to the next row, possibly shifting
+	// column when lanes to its left end.
+	GraphEdgeContinue GraphEdgeKind = iota
+	// GraphEdgeFork joins the commit's lane into a lane already tracking its
+	// first parent, i.e. the point where two branches forked.
+	GraphEdgeFork
+	// GraphEdgeMerge connects a merge commit to one of its secondary parents.
+	GraphEdgeMerge
+)
+
+// GraphEdge connects lane From in one row to lane To in the next row.
+type GraphEdge struct {
+	From  int
+	To    int
+	Kind  GraphEdgeKind
+	Color int
+}
+
+// GraphRow is the lane layout of a single commit row. Column is the lane
+// holding the commit node and Lanes the number of lanes crossing the row.
+// Edges lead from this row into the next one; a lane without an outgoing
+// edge ends at this row. Colors are stable per lane.
+type GraphRow struct {
+	Column int
+	Color  int
+	Lanes  int
+	Edges  []GraphEdge
+}
+
+// String renders the commit line of the row as text: "*" marks the commit
+// and "|" the other lanes. The edges that change column are drawn by
+// Connectors on a line of their own, as git log --graph does.
+func (r *GraphRow) String() string {
+	if r == nil {
+		return ""
+	}
+	cells := graphCells(r.Lanes)
+	for i := range r.Lanes {
+		cells[2*i] = '|'
+	}
+	cells[2*r.Column] = '*'
+	return strings.TrimRight(string(cells), " ")
+}
+
+// Connectors renders the line between the row and the next one: "|" for the
+// lanes going straight down and "/", "\" and "-" for the edges that change
+// column. It is empty when no edge changes column.
+func (r *GraphRow) Connectors() string {
+	if r == nil || !slices.ContainsFunc(r.Edges, func(e GraphEdge) bool { return e.From != e.To }) {
+		return ""
+	}
+	width := 0
+	for _, e := range r.Edges {
+		width = max(width, e.From+1, e.To+1)
+	}
+	cells := graphCells(width)
+	for _, e := range r.Edges {
+		switch {
+		case e.To == e.From:
+			cells[2*e.To] = '|'
+		case e.To > e.From:
+			for i := e.From; i < e.To-1; i++ {
+				setGraphSep(cells, 2*i+1, '-')
+			}
+			setGraphSep(cells, 2*e.To-1, '\\')
+		default:
+			for i := e.To + 1; i < e.From; i++ {
+				setGraphSep(cells, 2*i+1, '-')
+			}
+			setGraphSep(cells, 2*e.To+1, '/')
+		}
+	}
+	return strings.TrimRight(string(cells), " ")
+}
+
+// graphCells returns a blank line for lanes lanes, with a separator cell
+// between each of them.
+func graphCells(lanes int) []byte {
+	cells := make([]byte, max(2*lanes-1, 1))
+	for i := range cells {
+		cells[i] = ' '
+	}
+	return cells
+}
+
+func setGraphSep(cells []byte, pos int, c byte) {
+	if cells[pos] == ' ' || cells[pos] == '-' {
+		cells[pos] = c
+	}
+}
+
+// graphBuilder assigns commits to lanes in the order they are streamed. Each
+// lane tracks the hash of the next commit expected in it.
+type graphBuilder struct {
+	columns    []string
+	colors     []int
+	nextColor  int
+	maxColumns int
+}
+
+func newGraphBuilder(maxColumns int) *graphBuilder {
+	if maxColumns <= 0 {
+		maxColumns = DefaultGraphMaxColumns
+	}
+	return &graphBuilder{maxColumns: maxColumns}
+}
+
+func (g *graphBuilder) trim() {
+	if g.maxColumns <= 0 {
+		return
+	}
+	if len(g.columns) > g.maxColumns {
+		g.columns = g.columns[:g.maxColumns]
+		g.colors = g.colors[:g.maxColumns]
+	}
+}
+
+func (g *graphBuilder) Row(c *Commit) *GraphRow {
+	idx := slices.Index(g.columns, c.Hash)
+	if idx == -1 {
+		// A commit nobody points at yet starts a new lane on the right. Make
+		// room for it first so the row never exceeds the cap.
+		if g.maxColumns > 0 && len(g.columns) >= g.maxColumns {
+			g.columns = g.columns[:g.maxColumns-1]
+			g.colors = g.colors[:g.maxColumns-1]
+		}
+		g.columns = append(g.columns, c.Hash)
+		g.colors = append(g.colors, g.newColor())
+		idx = len(g.columns) - 1
+	}
+	row := &GraphRow{
+		Column: idx,
+		Color:  g.colors[idx],
+		Lanes:  len(g.columns),
+	}
+
+	prev := slices.Clone(g.columns)
+	nodeColor := g.colors[idx]
+	nodeKind := GraphEdgeContinue
+	// merges records the secondary parents as edges from the node.
+	var merges []string
+	switch {
+	case len(c.ParentHashes) == 0:
+		g.removeColumn(idx)
+	case slices.Contains(g.columns, c.ParentHashes[0]):
+		nodeKind = GraphEdgeFork
+		g.removeColumn(idx)
+	default:
+		g.columns[idx] = c.ParentHashes[0]
+	}
+	if len(c.ParentHashes) > 0 {
+		pos := min(idx+1, len(g.columns))
+		for _, parent := range c.ParentHashes[1:] {
+			merges = append(merges, parent)
+			if slices.Contains(g.columns, parent) {
+				continue
+			}
+			g.columns = slices.Insert(g.columns, pos, parent)
+			g.colors = slices.Insert(g.colors, pos, g.newColor())
+			pos++
+		}
+	}
+	g.trim()
+
+	for i, hash := range prev {
+		if i == idx {
+			continue
+		}
+		if to := slices.Index(g.columns, hash); to >= 0 {
+			row.Edges = append(row.Edges, GraphEdge{From: i, To: to, Color: g.colors[to]})
+		}
+	}
+	if len(c.ParentHashes) > 0 {
+		if to := slices.Index(g.columns, c.ParentHashes[0]); to >= 0 {
+			row.Edges = append(row.Edges, GraphEdge{From: idx, To: to, Kind: nodeKind, Color: nodeColor})
+		}
+	}
+	for _, parent := range merges {
+		if to := slices.Index(g.columns, parent); to >= 0 {
+			row.Edges = append(row.Edges, GraphEdge{From: idx, To: to, Kind: GraphEdgeMerge, Color: g.colors[to]})
+		}
+	}
+	return row
+}
+
+func (g *graphBuilder) newColor() int {
+	color := g.nextColor
+	g.nextColor++
+	return color
+}
+
+func (g *graphBuilder) removeColumn(idx int) {
+	g.columns = slices.Delete(g.columns, idx, idx+1)
+	g.colors = slices.Delete(g.colors, idx, idx+1)
+}
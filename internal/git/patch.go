@@ -0,0 +1,217 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	gitbackend "github.com/thiagokokada/gitk-go/internal/git/backend"
+)
+
+// PatchAction selects what ApplyPatch does with a patch of the local changes.
+type PatchAction uint8
+
+const (
+	// PatchStage adds the changes of a patch of the working tree to the
+	// index.
+	PatchStage PatchAction = iota
+	// PatchUnstage removes the changes of a patch of the index from it,
+	// keeping them in the working tree.
+	PatchUnstage
+	// PatchDiscard reverts the changes of a patch of the working tree.
+	PatchDiscard
+)
+
+// PatchFile is the diff of one file in a unified diff, split in hunks so that
+// partial patches can be built from it.
+type PatchFile struct {
+	Path string
+	// Line is the 1-based line of the "diff --git" header in the diff.
+	Line  int
+	Hunks []PatchHunk
+	// Binary is set when git left out the changes of a binary file, which
+	// then cannot be applied.
+	Binary bool
+	// header holds the lines from "diff --git" up to the first hunk.
+	header []string
+}
+
+// PatchHunk is a hunk of a PatchFile.
+type PatchHunk struct {
+	// Line and End are the 1-based lines of the "@@" header and of the last
+	// line of the hunk in the diff.
+	Line, End int
+	lines     []string
+}
+
+// ParsePatch splits the file diffs of diff, ignoring the text before the
+// first one and combined diffs. A blank line ends the file, except inside a
+// hunk, where it is a context line git wrote without its leading space, as
+// it does with diff.suppressBlankEmpty.
+func ParsePatch(diff string) []PatchFile {
+	var files []PatchFile
+	var cur *PatchFile
+	var hunk *PatchHunk
+	// oldLeft and newLeft count the lines the current hunk still has on each
+	// side, according to its header.
+	var oldLeft, newLeft int
+	for i, line := range strings.Split(diff, "\n") {
+		lineNo := i + 1
+		if strings.HasPrefix(line, "diff --") {
+			cur, hunk = nil, nil
+			if path := parseGitDiffPath(line); path != "" && strings.HasPrefix(line, "diff --git ") {
+				files = append(files, PatchFile{Path: path, Line: lineNo, header: []string{line}})
+				cur = &files[len(files)-1]
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			cur.Hunks = append(cur.Hunks, PatchHunk{Line: lineNo, End: lineNo, lines: []string{line}})
+			hunk = &cur.Hunks[len(cur.Hunks)-1]
+			oldLeft, newLeft = parseHunkCounts(line)
+		case hunk != nil && line == "" && (oldLeft > 0 || newLeft > 0):
+			hunk.lines = append(hunk.lines, " ")
+			hunk.End = lineNo
+			oldLeft--
+			newLeft--
+		case hunk != nil && line != "" && strings.ContainsRune(" +-\\", rune(line[0])):
+			hunk.lines = append(hunk.lines, line)
+			hunk.End = lineNo
+			switch line[0] {
+			case ' ':
+				oldLeft--
+				newLeft--
+			case '-':
+				oldLeft--
+			case '+':
+				newLeft--
+			}
+		case hunk == nil && line != "":
+			cur.header = append(cur.header, line)
+			if strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch" {
+				cur.Binary = true
+			}
+		default:
+			cur, hunk = nil, nil
+		}
+	}
+	return files
+}
+
+// parseHunkCounts returns the old and new line counts of the "@@ -a,b +c,d @@"
+// hunk header line, or zeros when it cannot be parsed.
+func parseHunkCounts(line string) (int, int) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return 0, 0
+	}
+	count := func(r string) int {
+		_, n, found := strings.Cut(r[1:], ",")
+		if !found {
+			// A range without a count has one line.
+			return 1
+		}
+		v, err := strconv.Atoi(n)
+		if err != nil {
+			return 0
+		}
+		return v
+	}
+	if !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0
+	}
+	return count(fields[1]), count(fields[2])
+}
+
+// PatchFileAt returns the index of the file of files whose diff holds the
+// 1-based line, or -1.
+func PatchFileAt(files []PatchFile, line int) int {
+	for i, file := range files {
+		end := file.Line + len(file.header) - 1
+		if n := len(file.Hunks); n > 0 {
+			end = file.Hunks[n-1].End
+		}
+		if line >= file.Line && line <= end {
+			return i
+		}
+	}
+	return -1
+}
+
+// HunkAt returns the index of the hunk of f holding the 1-based line, or -1.
+func (f PatchFile) HunkAt(line int) int {
+	for i, hunk := range f.Hunks {
+		if line >= hunk.Line && line <= hunk.End {
+			return i
+		}
+	}
+	return -1
+}
+
+// Patch returns the patch of the whole file.
+func (f PatchFile) Patch() string {
+	var b strings.Builder
+	writeLines(&b, f.header)
+	for _, hunk := range f.Hunks {
+		writeLines(&b, hunk.lines)
+	}
+	return b.String()
+}
+
+// HunkPatch returns the patch of the file limited to its hunk i. The other
+// hunks are left out, which keeps the line numbers of hunk i right.
+func (f PatchFile) HunkPatch(i int) string {
+	if i < 0 || i >= len(f.Hunks) {
+		return ""
+	}
+	var b strings.Builder
+	writeLines(&b, f.header)
+	writeLines(&b, f.Hunks[i].lines)
+	return b.String()
+}
+
+func writeLines(b *strings.Builder, lines []string) {
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+}
+
+// PatchableDiff reports whether patches of local diffs made with opts can be
+// applied. Diffs ignoring whitespace or blank lines hide changes the patch
+// would need.
+func PatchableDiff(opts DiffOptions) bool {
+	return opts.Whitespace == WhitespaceShow && !opts.IgnoreBlankLines
+}
+
+// ApplyPatch applies patch, built from the local diff made with opts, as
+// action does.
+func (s *Service) ApplyPatch(patch string, action PatchAction, opts DiffOptions) error {
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return fmt.Errorf("repository root not set")
+	}
+	if strings.TrimSpace(patch) == "" {
+		return fmt.Errorf("patch not specified")
+	}
+	if !PatchableDiff(opts) {
+		return fmt.Errorf("diffs ignoring whitespace or blank lines cannot be applied")
+	}
+	apply := gitbackend.ApplyOptions{UnidiffZero: opts.Context == NoContext}
+	switch action {
+	case PatchStage:
+		apply.Cached = true
+	case PatchUnstage:
+		apply.Cached, apply.Reverse = true, true
+	case PatchDiscard:
+		apply.Reverse = true
+	default:
+		return fmt.Errorf("unknown patch action %d", action)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.ApplyPatch(patch, apply)
+}
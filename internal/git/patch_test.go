@@ -0,0 +1,259 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gitbackend "github.com/thiagokokada/gitk-go/internal/git/backend"
+)
+
+const samplePatchDiff = `Local uncommitted changes, not checked in to index
+diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+-one
++ONE
+ two
+ three
+@@ -10,2 +10,3 @@ ten
+ ten
+ eleven
++twelve
+\ No newline at end of file
+
+diff --git a/bin.dat b/bin.dat
+index 3333333..4444444 100644
+Binary files a/bin.dat and b/bin.dat differ
+
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..5555555
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++new
+`
+
+func TestParsePatch(t *testing.T) {
+	files := ParsePatch(samplePatchDiff)
+	if len(files) != 3 {
+		t.Fatalf("got %d files, want 3: %+v", len(files), files)
+	}
+	a, bin, added := files[0], files[1], files[2]
+	if a.Path != "a.txt" || a.Line != 2 || len(a.Hunks) != 2 || a.Binary {
+		t.Fatalf("unexpected a.txt %+v", a)
+	}
+	if a.Hunks[0].Line != 6 || a.Hunks[0].End != 10 || a.Hunks[1].Line != 11 || a.Hunks[1].End != 15 {
+		t.Fatalf("unexpected hunk lines %+v", a.Hunks)
+	}
+	if bin.Path != "bin.dat" || !bin.Binary || len(bin.Hunks) != 0 {
+		t.Fatalf("unexpected bin.dat %+v", bin)
+	}
+	if added.Path != "new.txt" || len(added.Hunks) != 1 {
+		t.Fatalf("unexpected new.txt %+v", added)
+	}
+
+	for line, want := range map[int]int{1: -1, 2: 0, 8: 0, 15: 0, 16: -1, 17: 1, 19: 1, 20: -1, 21: 2, 27: 2, 28: -1} {
+		if got := PatchFileAt(files, line); got != want {
+			t.Fatalf("PatchFileAt(%d) = %d, want %d", line, got, want)
+		}
+	}
+	for line, want := range map[int]int{3: -1, 6: 0, 10: 0, 11: 1, 15: 1, 16: -1} {
+		if got := a.HunkAt(line); got != want {
+			t.Fatalf("HunkAt(%d) = %d, want %d", line, got, want)
+		}
+	}
+
+	wantHunk := "diff --git a/a.txt b/a.txt\nindex 1111111..2222222 100644\n--- a/a.txt\n+++ b/a.txt\n" +
+		"@@ -10,2 +10,3 @@ ten\n ten\n eleven\n+twelve\n\\ No newline at end of file\n"
+	if got := a.HunkPatch(1); got != wantHunk {
+		t.Fatalf("HunkPatch(1) = %q, want %q", got, wantHunk)
+	}
+	if got := a.HunkPatch(2); got != "" {
+		t.Fatalf("HunkPatch(2) = %q, want empty", got)
+	}
+	if got := a.Patch(); !strings.Contains(got, "+ONE\n") || !strings.HasSuffix(got, "file\n") {
+		t.Fatalf("Patch() = %q", got)
+	}
+}
+
+func TestApplyPatch_Options(t *testing.T) {
+	var got []gitbackend.ApplyOptions
+	svc := NewWithBackend(&fakeBackend{
+		repoPath: "repo",
+		applyPatchFunc: func(patch string, opts gitbackend.ApplyOptions) error {
+			got = append(got, opts)
+			return nil
+		},
+	})
+	for _, action := range []PatchAction{PatchStage, PatchUnstage, PatchDiscard} {
+		if err := svc.ApplyPatch("diff --git a/a b/a\n", action, DiffOptions{}); err != nil {
+			t.Fatalf("ApplyPatch(%d): %v", action, err)
+		}
+	}
+	if err := svc.ApplyPatch("diff --git a/a b/a\n", PatchStage, DiffOptions{Context: NoContext}); err != nil {
+		t.Fatalf("ApplyPatch without context: %v", err)
+	}
+	want := []gitbackend.ApplyOptions{
+		{Cached: true},
+		{Cached: true, Reverse: true},
+		{Reverse: true},
+		{Cached: true, UnidiffZero: true},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("apply options = %+v, want %+v", got, want)
+	}
+	if err := svc.ApplyPatch("diff --git a/a b/a\n", PatchStage, DiffOptions{Whitespace: WhitespaceIgnoreAll}); err == nil {
+		t.Fatalf("expected an error for a diff ignoring whitespace")
+	}
+	if err := svc.ApplyPatch(" ", PatchStage, DiffOptions{}); err == nil {
+		t.Fatalf("expected an error for an empty patch")
+	}
+}
+
+func TestApplyPatch_StagesSingleHunk(t *testing.T) {
+	dir, _ := createTestRepo(t, 1)
+	svc, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	var lines []string
+	for i := range 20 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	path := filepath.Join(dir, "file.txt")
+	write := func() {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	write()
+	runGit(t, dir, nil, "commit", "-qam", "twenty lines", "--no-gpg-sign")
+	lines[1], lines[18] = "first change", "second change"
+	write()
+
+	diff, _, err := svc.WorktreeDiff(false, DiffOptions{})
+	if err != nil {
+		t.Fatalf("WorktreeDiff: %v", err)
+	}
+	files := ParsePatch(diff)
+	if len(files) != 1 || len(files[0].Hunks) != 2 {
+		t.Fatalf("expected one file with two hunks, got %+v", files)
+	}
+	if err := svc.ApplyPatch(files[0].HunkPatch(1), PatchStage, DiffOptions{}); err != nil {
+		t.Fatalf("ApplyPatch stage: %v", err)
+	}
+	staged := runGit(t, dir, nil, "diff", "--cached")
+	if !strings.Contains(staged, "+second change") || strings.Contains(staged, "first change") {
+		t.Fatalf("expected only the second hunk to be staged, got:\n%s", staged)
+	}
+
+	diff, _, err = svc.WorktreeDiff(false, DiffOptions{})
+	if err != nil {
+		t.Fatalf("WorktreeDiff: %v", err)
+	}
+	files = ParsePatch(diff)
+	if err := svc.ApplyPatch(files[0].Patch(), PatchDiscard, DiffOptions{}); err != nil {
+		t.Fatalf("ApplyPatch discard: %v", err)
+	}
+	if got := runGit(t, dir, nil, "diff"); got != "" {
+		t.Fatalf("expected no unstaged changes, got:\n%s", got)
+	}
+
+	diff, _, err = svc.WorktreeDiff(true, DiffOptions{})
+	if err != nil {
+		t.Fatalf("WorktreeDiff staged: %v", err)
+	}
+	files = ParsePatch(diff)
+	if err := svc.ApplyPatch(files[0].HunkPatch(0), PatchUnstage, DiffOptions{}); err != nil {
+		t.Fatalf("ApplyPatch unstage: %v", err)
+	}
+	if got := runGit(t, dir, nil, "diff", "--cached"); got != "" {
+		t.Fatalf("expected nothing staged, got:\n%s", got)
+	}
+	if got := runGit(t, dir, nil, "diff"); !strings.Contains(got, "+second change") {
+		t.Fatalf("expected the unstaged hunk in the working tree, got:\n%s", got)
+	}
+}
+
+func TestParsePatch_BlankContextLine(t *testing.T) {
+	// With diff.suppressBlankEmpty git writes empty context lines without
+	// their leading space.
+	diff := "diff --git a/a.txt b/a.txt\n" +
+		"--- a/a.txt\n" +
+		"+++ b/a.txt\n" +
+		"@@ -1,4 +1,4 @@\n" +
+		" one\n" +
+		"\n" +
+		"-three\n" +
+		"+THREE\n" +
+		" four\n" +
+		"\n" +
+		"diff --git a/b.txt b/b.txt\n" +
+		"--- a/b.txt\n" +
+		"+++ b/b.txt\n" +
+		"@@ -1 +1 @@\n" +
+		"-b\n" +
+		"+B\n"
+	files := ParsePatch(diff)
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2: %+v", len(files), files)
+	}
+	a := files[0]
+	if len(a.Hunks) != 1 || a.Hunks[0].Line != 4 || a.Hunks[0].End != 9 {
+		t.Fatalf("unexpected hunks %+v", a.Hunks)
+	}
+	if got := a.HunkAt(6); got != 0 {
+		t.Fatalf("HunkAt(6) = %d, want 0", got)
+	}
+	if got := PatchFileAt(files, 10); got != -1 {
+		t.Fatalf("PatchFileAt(10) = %d, want -1", got)
+	}
+	want := "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n" +
+		"@@ -1,4 +1,4 @@\n one\n \n-three\n+THREE\n four\n"
+	if got := a.HunkPatch(0); got != want {
+		t.Fatalf("HunkPatch(0) = %q, want %q", got, want)
+	}
+	if files[1].Path != "b.txt" || len(files[1].Hunks) != 1 || files[1].Hunks[0].End != 16 {
+		t.Fatalf("unexpected b.txt %+v", files[1])
+	}
+}
+
+func TestApplyPatch_SuppressBlankEmpty(t *testing.T) {
+	dir, _ := createTestRepo(t, 1)
+	runGit(t, dir, nil, "config", "diff.suppressBlankEmpty", "true")
+	svc, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	path := filepath.Join(dir, "blank.txt")
+	if err := os.WriteFile(path, []byte("one\n\nthree\nfour\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	runGit(t, dir, nil, "add", "blank.txt")
+	runGit(t, dir, nil, "commit", "-qm", "blank line", "--no-gpg-sign")
+	if err := os.WriteFile(path, []byte("one\n\nTHREE\nfour\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	diff, _, err := svc.WorktreeDiff(false, DiffOptions{})
+	if err != nil {
+		t.Fatalf("WorktreeDiff: %v", err)
+	}
+	files := ParsePatch(diff)
+	if len(files) != 1 || len(files[0].Hunks) != 1 {
+		t.Fatalf("expected one file with one hunk, got %+v", files)
+	}
+	if err := svc.ApplyPatch(files[0].HunkPatch(0), PatchStage, DiffOptions{}); err != nil {
+		t.Fatalf("ApplyPatch stage: %v", err)
+	}
+	if got := runGit(t, dir, nil, "diff"); got != "" {
+		t.Fatalf("expected the whole hunk to be staged, got:\n%s", got)
+	}
+}
@@ -1,7 +1,9 @@
 package git
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"strings"
 
 	gitbackend "github.com/thiagokokada/gitk-go/internal/git/backend"
@@ -36,7 +38,7 @@ func (s *Service) BranchLabels() (map[string][]string, error) {
 		return nil, err
 	}
 	if ok && headHash != "" {
-		label := "HEAD"
+		label := "HEAD (detached)"
 		if headName != "" && headName != "HEAD" {
 			label = fmt.Sprintf("HEAD -> %s", headName)
 		}
@@ -44,3 +46,75 @@ func (s *Service) BranchLabels() (map[string][]string, error) {
 	}
 	return labels, nil
 }
+
+// RefList holds the refs shown in the refs sidebar.
+type RefList struct {
+	// Refs is sorted by kind, then name.
+	Refs    []Ref
+	Stashes []Stash
+	// HeadName is the checked out branch, or "HEAD" when detached.
+	HeadName string
+	// HeadHash is empty on an unborn branch.
+	HeadHash string
+}
+
+// ListRefs returns the branches, remote branches, tags and stashes of the
+// repository.
+func (s *Service) ListRefs() (RefList, error) {
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return RefList{}, fmt.Errorf("repository root not set")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refs, err := s.backend.ListRefs()
+	if err != nil {
+		return RefList{}, err
+	}
+	refs = slices.Clone(refs)
+	slices.SortFunc(refs, func(a, b Ref) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), strings.Compare(a.Name, b.Name))
+	})
+	stashes, err := s.backend.ListStashes()
+	if err != nil {
+		return RefList{}, err
+	}
+	headHash, headName, ok, err := s.backend.HeadState()
+	if err != nil {
+		return RefList{}, err
+	}
+	if !ok || headName == "" {
+		headName = "HEAD"
+	}
+	return RefList{Refs: refs, Stashes: stashes, HeadName: headName, HeadHash: headHash}, nil
+}
+
+// ResolveRevision returns the hash of the commit named by rev, which may be a
+// (short) hash, a ref name or an expression such as "HEAD~40".
+func (s *Service) ResolveRevision(rev string) (string, error) {
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return "", fmt.Errorf("repository root not set")
+	}
+	rev = strings.TrimSpace(rev)
+	if rev == "" {
+		return "", fmt.Errorf("revision not specified")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.ResolveRevision(rev)
+}
+
+// LogContains reports whether the history selected by spec includes the
+// commit named by rev, without scanning the commits before it.
+func (s *Service) LogContains(spec LogSpec, rev string) (bool, error) {
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return false, fmt.Errorf("repository root not set")
+	}
+	rev = strings.TrimSpace(rev)
+	if rev == "" {
+		return false, fmt.Errorf("revision not specified")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.LogContains(spec, rev)
+}
@@ -0,0 +1,224 @@
+package git
+
+import (
+	"slices"
+	"strings"
+)
+
+// CommitLink is a related commit listed in a commit header.
+type CommitLink struct {
+	Hash string
+	// Subject is empty when the commit is not loaded.
+	Subject string
+}
+
+// CommitRelations are the commits and refs related to a commit, shown in its
+// header like gitk does.
+type CommitRelations struct {
+	Parents  []CommitLink
+	Children []CommitLink
+	// Branches lists the branches containing the commit.
+	Branches []string
+	// Follows lists the nearest tags among the ancestors of the commit and
+	// Precedes the nearest ones among its descendants.
+	Follows  []string
+	Precedes []string
+}
+
+// HeaderLink is the position of a parent or child hash in a header written
+// by FormatCommitHeaderWith. Line is 0-based; Start and End are byte offsets.
+type HeaderLink struct {
+	Line  int
+	Start int
+	End   int
+	Hash  string
+}
+
+const (
+	parentLabel = "Parent: "
+	childLabel  = "Child:  "
+)
+
+// CommitIndex indexes loaded entries by hash and by parent, so that the
+// relations of a commit can be computed without asking the backend. Since
+// the log lists children before their parents, the children of a loaded
+// commit are known as soon as it is added.
+type CommitIndex struct {
+	entries  map[string]*Entry
+	children map[string][]string
+}
+
+func NewCommitIndex() *CommitIndex {
+	return &CommitIndex{entries: map[string]*Entry{}, children: map[string][]string{}}
+}
+
+// Add indexes entries, in log order.
+func (ix *CommitIndex) Add(entries []*Entry) {
+	for _, entry := range entries {
+		if entry == nil || entry.Commit == nil {
+			continue
+		}
+		hash := entry.Commit.Hash
+		if _, ok := ix.entries[hash]; ok {
+			continue
+		}
+		ix.entries[hash] = entry
+		for _, parent := range entry.Commit.ParentHashes {
+			ix.children[parent] = append(ix.children[parent], hash)
+		}
+	}
+}
+
+// Relations returns the relations of c among the indexed commits. refs are
+// used for the branch and tag lines.
+func (ix *CommitIndex) Relations(c *Commit, refs []Ref) *CommitRelations {
+	branches := map[string][]string{}
+	tags := map[string][]string{}
+	for _, ref := range refs {
+		switch ref.Kind {
+		case RefKindBranch, RefKindRemoteBranch:
+			if !strings.HasSuffix(ref.Name, "/HEAD") {
+				branches[ref.Hash] = append(branches[ref.Hash], ref.Name)
+			}
+		case RefKindTag:
+			tags[ref.Hash] = append(tags[ref.Hash], ref.Name)
+		}
+	}
+
+	rel := &CommitRelations{}
+	for _, parent := range c.ParentHashes {
+		rel.Parents = append(rel.Parents, ix.link(parent))
+	}
+	for _, child := range ix.children[c.Hash] {
+		rel.Children = append(rel.Children, ix.link(child))
+	}
+
+	children := func(hash string) []string { return ix.children[hash] }
+	parents := func(hash string) []string {
+		if entry, ok := ix.entries[hash]; ok {
+			return entry.Commit.ParentHashes
+		}
+		return nil
+	}
+	walk(c.Hash, children, func(hash string) bool {
+		rel.Branches = append(rel.Branches, branches[hash]...)
+		return true
+	})
+	rel.Precedes = nearestTags(c.Hash, children, tags)
+	rel.Follows = nearestTags(c.Hash, parents, tags)
+	slices.Sort(rel.Branches)
+	return rel
+}
+
+func (ix *CommitIndex) link(hash string) CommitLink {
+	link := CommitLink{Hash: hash}
+	if entry, ok := ix.entries[hash]; ok {
+		link.Subject = strings.SplitN(strings.TrimSpace(entry.Commit.Message), "\n", 2)[0]
+	}
+	return link
+}
+
+// walk visits start and the commits reachable from it through next, breadth
+// first. visit returns false to stop walking past a commit.
+func walk(start string, next func(string) []string, visit func(string) bool) {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if !visit(hash) {
+			continue
+		}
+		for _, n := range next(hash) {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+}
+
+// nearestTags returns the tags of the first tagged commits reached from
+// start (excluded) through next.
+func nearestTags(start string, next func(string) []string, tags map[string][]string) []string {
+	var found []string
+	walk(start, next, func(hash string) bool {
+		if hash == start {
+			return true
+		}
+		if names := tags[hash]; len(names) > 0 {
+			found = append(found, names...)
+			return false
+		}
+		return true
+	})
+	slices.Sort(found)
+	return slices.Compact(found)
+}
+
+// FormatCommitHeaderWith is FormatCommitHeader with the parent, child,
+// branch and nearest tag lines of rel, if not nil.
+func FormatCommitHeaderWith(c *Commit, rel *CommitRelations) string {
+	if rel == nil {
+		return FormatCommitHeader(c)
+	}
+	header := FormatCommitHeader(c)
+	// The relations go between the signatures and the message.
+	head, message, _ := strings.Cut(header, "\n\n")
+	var b strings.Builder
+	b.WriteString(head)
+	b.WriteByte('\n')
+	writeLinks := func(label string, links []CommitLink) {
+		for _, link := range links {
+			b.WriteString(label)
+			b.WriteString(link.Hash)
+			if link.Subject != "" {
+				b.WriteString(" (")
+				b.WriteString(link.Subject)
+				b.WriteByte(')')
+			}
+			b.WriteByte('\n')
+		}
+	}
+	writeLinks(parentLabel, rel.Parents)
+	writeLinks(childLabel, rel.Children)
+	writeNames := func(label string, names []string) {
+		if len(names) > 0 {
+			b.WriteString(label)
+			b.WriteString(strings.Join(names, ", "))
+			b.WriteByte('\n')
+		}
+	}
+	writeNames("Branches: ", rel.Branches)
+	writeNames("Follows: ", rel.Follows)
+	writeNames("Precedes: ", rel.Precedes)
+	b.WriteByte('\n')
+	b.WriteString(message)
+	return b.String()
+}
+
+// CommitHeaderLinks returns the parent and child hashes in the header at the
+// start of text.
+func CommitHeaderLinks(text string) []HeaderLink {
+	var links []HeaderLink
+	for i, line := range strings.Split(text, "\n") {
+		if line == "" {
+			break
+		}
+		label := ""
+		switch {
+		case strings.HasPrefix(line, parentLabel):
+			label = parentLabel
+		case strings.HasPrefix(line, childLabel):
+			label = childLabel
+		default:
+			continue
+		}
+		hash, _, _ := strings.Cut(line[len(label):], " ")
+		if hash == "" {
+			continue
+		}
+		links = append(links, HeaderLink{Line: i, Start: len(label), End: len(label) + len(hash), Hash: hash})
+	}
+	return links
+}
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// relationsFixture builds the history
+//
+//	e (main)     f (topic)
+//	|  \        /
+//	d   c (v2) /
+//	 \ /      /
+//	  b ------
+//	  |
+//	  a (v1)
+func relationsFixture() (*CommitIndex, []Ref) {
+	commit := func(hash, msg string, parents ...string) *Entry {
+		return &Entry{Commit: &Commit{Hash: hash, Message: msg, ParentHashes: parents}}
+	}
+	ix := NewCommitIndex()
+	ix.Add([]*Entry{
+		commit("e", "merge c", "d", "c"),
+		commit("f", "topic work", "b"),
+		commit("d", "subject d\n\nbody", "b"),
+		commit("c", "subject c", "b"),
+	})
+	// Added in a later batch.
+	ix.Add([]*Entry{commit("b", "subject b", "a")})
+	refs := []Ref{
+		{Hash: "e", Kind: RefKindBranch, Name: "main"},
+		{Hash: "f", Kind: RefKindBranch, Name: "topic"},
+		{Hash: "e", Kind: RefKindRemoteBranch, Name: "origin/HEAD"},
+		{Hash: "c", Kind: RefKindTag, Name: "v2"},
+		{Hash: "a", Kind: RefKindTag, Name: "v1"},
+	}
+	return ix, refs
+}
+
+func TestCommitIndexRelations(t *testing.T) {
+	ix, refs := relationsFixture()
+	b := ix.entries["b"].Commit
+	rel := ix.Relations(b, refs)
+
+	if want := []CommitLink{{Hash: "a"}}; !slices.Equal(rel.Parents, want) {
+		t.Fatalf("Parents = %+v, want %+v", rel.Parents, want)
+	}
+	wantChildren := []CommitLink{{Hash: "f", Subject: "topic work"}, {Hash: "d", Subject: "subject d"}, {Hash: "c", Subject: "subject c"}}
+	if !slices.Equal(rel.Children, wantChildren) {
+		t.Fatalf("Children = %+v, want %+v", rel.Children, wantChildren)
+	}
+	if want := []string{"main", "topic"}; !slices.Equal(rel.Branches, want) {
+		t.Fatalf("Branches = %v, want %v", rel.Branches, want)
+	}
+	if want := []string{"v1"}; !slices.Equal(rel.Follows, want) {
+		t.Fatalf("Follows = %v, want %v", rel.Follows, want)
+	}
+	if want := []string{"v2"}; !slices.Equal(rel.Precedes, want) {
+		t.Fatalf("Precedes = %v, want %v", rel.Precedes, want)
+	}
+
+	rel = ix.Relations(ix.entries["c"].Commit, refs)
+	if len(rel.Precedes) != 0 || !slices.Equal(rel.Follows, []string{"v1"}) {
+		t.Fatalf("c: Follows = %v, Precedes = %v", rel.Follows, rel.Precedes)
+	}
+	if !slices.Equal(rel.Branches, []string{"main"}) {
+		t.Fatalf("c: Branches = %v, want [main]", rel.Branches)
+	}
+}
+
+func TestFormatCommitHeaderWithLinks(t *testing.T) {
+	ix, refs := relationsFixture()
+	c := ix.entries["d"].Commit
+	header := FormatCommitHeaderWith(c, ix.Relations(c, refs))
+
+	for _, want := range []string{
+		"\nParent: b (subject b)\n",
+		"\nChild:  e (merge c)\n",
+		"\nBranches: main\nFollows: v1\n\n    subject d\n",
+	} {
+		if !strings.Contains(header, want) {
+			t.Fatalf("header missing %q:\n%s", want, header)
+		}
+	}
+
+	links := CommitHeaderLinks(header)
+	want := []HeaderLink{
+		{Line: 3, Start: 8, End: 9, Hash: "b"},
+		{Line: 4, Start: 8, End: 9, Hash: "e"},
+	}
+	if !slices.Equal(links, want) {
+		t.Fatalf("links = %+v, want %+v", links, want)
+	}
+	if got := CommitHeaderLinks(FormatCommitHeader(c)); len(got) != 0 {
+		t.Fatalf("expected no links without relations, got %+v", got)
+	}
+}
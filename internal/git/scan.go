@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
+	"strings"
 
 	gitbackend "github.com/thiagokokada/gitk-go/internal/git/backend"
 )
@@ -11,6 +13,10 @@ import (
 type scanSession struct {
 	head     string
 	headName string
+	// tips fingerprints the refs when the spec has revision arguments, see
+	// refTipsLocked.
+	tips string
+	spec LogSpec
 
 	logStream gitbackend.LogStream
 
@@ -20,20 +26,52 @@ type scanSession struct {
 	returned  uint
 
 	graphBuilder   *graphBuilder
-	graphCache     map[string]string
+	graphCache     map[string]*GraphRow
 	graphProcessed int
 	graphColsMax   int
 	graphEOF       bool
 }
 
-func (s *Service) ensureScanSessionLocked(headHash, headName string) error {
-	if s.scan != nil && s.scan.head == headHash {
+func (s *Service) ensureScanSessionLocked(headHash, headName string, spec LogSpec) error {
+	tips, err := s.refTipsLocked(spec)
+	if err != nil {
+		return err
+	}
+	if s.scan != nil && s.scan.head == headHash && s.scan.tips == tips && s.scan.spec.Equal(spec) {
 		return nil
 	}
-	return s.resetScanLocked(headHash, headName)
+	return s.resetScanLocked(headHash, headName, tips, spec)
 }
 
-func (s *Service) resetScanLocked(headHash, headName string) error {
+// refTipsLocked fingerprints the refs and the stash that the revisions of
+// spec may name (e.g. --all or a branch), so that a session is restarted when
+// one of them moves. A walk of HEAD is tracked by its hash alone.
+func (s *Service) refTipsLocked(spec LogSpec) (string, error) {
+	if len(spec.Revisions) == 0 {
+		return "", nil
+	}
+	if s.backend == nil {
+		return "", fmt.Errorf("repository root not set")
+	}
+	refs, err := s.backend.ListRefs()
+	if err != nil {
+		return "", fmt.Errorf("list refs: %w", err)
+	}
+	stashes, err := s.backend.ListStashes()
+	if err != nil {
+		return "", fmt.Errorf("list stashes: %w", err)
+	}
+	var b strings.Builder
+	for _, ref := range refs {
+		fmt.Fprintf(&b, "%d %s %s\n", ref.Kind, ref.Name, ref.Hash)
+	}
+	if len(stashes) > 0 {
+		fmt.Fprintf(&b, "stash %s\n", stashes[0].Hash)
+	}
+	return b.String(), nil
+}
+
+func (s *Service) resetScanLocked(headHash, headName, tips string, spec LogSpec) error {
 	if s.scan != nil {
 		s.scan.close()
 		s.scan = nil
@@ -41,24 +79,42 @@ func (s *Service) resetScanLocked(headHash, headName string) error {
 	if s.backend == nil || s.backend.RepoPath() == "" {
 		return fmt.Errorf("repository root not set")
 	}
-	stream, err := s.backend.StartLogStream(headHash)
+	streamSpec := spec
+	if len(streamSpec.Revisions) == 0 {
+		// Pin the walk to the resolved HEAD so pagination stays consistent.
+		streamSpec.Revisions = []string{headHash}
+	}
+	stream, err := s.backend.StartLogStream(streamSpec)
 	if err != nil {
 		return err
 	}
 	s.scan = &scanSession{
 		head:       headHash,
 		headName:   headName,
+		tips:       tips,
+		spec:       cloneLogSpec(spec),
 		logStream:  stream,
 		graphEOF:   false,
 		exhausted:  false,
-		graphCache: make(map[string]string, DefaultBatch),
+		graphCache: make(map[string]*GraphRow, DefaultBatch),
 
 		graphBuilder: newGraphBuilder(s.graphMaxColumns),
 	}
-	slog.Debug("ScanCommits session initialized", slog.String("head", s.scan.headName))
+	slog.Debug("ScanCommits session initialized",
+		slog.String("head", s.scan.headName),
+		slog.String("spec", spec.String()),
+	)
 	return nil
 }
 
+func cloneLogSpec(spec LogSpec) LogSpec {
+	return LogSpec{
+		Revisions: slices.Clone(spec.Revisions),
+		Paths:     slices.Clone(spec.Paths),
+		Follow:    spec.Follow,
+	}
+}
+
 func (s *scanSession) close() {
 	if s.logStream != nil {
 		if err := s.logStream.Close(); err != nil {
@@ -125,7 +181,9 @@ func (s *scanSession) assignGraphStrings(entries []*Entry) {
 		return
 	}
 	for _, entry := range entries {
-		entry.Graph = s.graphCache[entry.Commit.Hash]
+		row := s.graphCache[entry.Commit.Hash]
+		entry.Graph = row.String()
+		entry.GraphRow = row
 	}
 }
 
@@ -140,9 +198,9 @@ func (s *scanSession) readNextCommit() (*Commit, error) {
 		}
 		return nil, err
 	}
-	line := s.graphBuilder.Line(commit)
+	row := s.graphBuilder.Row(commit)
 	s.graphProcessed++
-	s.graphCache[commit.Hash] = line
+	s.graphCache[commit.Hash] = row
 	if cols := len(s.graphBuilder.columns); cols > s.graphColsMax {
 		s.graphColsMax = cols
 	}
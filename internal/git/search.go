@@ -0,0 +1,25 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SearchCommits returns the set of commits selected by spec that match query.
+// Unlike the commit text filter it is not limited to the loaded commits.
+// Cancelling ctx stops the search.
+func (s *Service) SearchCommits(ctx context.Context, spec LogSpec, query SearchQuery) (map[string]bool, error) {
+	if strings.TrimSpace(query.Pattern) == "" {
+		return nil, fmt.Errorf("search pattern not specified")
+	}
+	hashes, err := s.backend.SearchCommits(ctx, spec, query)
+	if err != nil {
+		return nil, err
+	}
+	matches := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		matches[hash] = true
+	}
+	return matches, nil
+}
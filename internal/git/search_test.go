@@ -0,0 +1,31 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	gitbackend "github.com/thiagokokada/gitk-go/internal/git/backend"
+)
+
+func TestSearchCommits(t *testing.T) {
+	var gotQuery SearchQuery
+	svc := NewWithBackend(&fakeBackend{
+		searchCommitsFunc: func(spec gitbackend.LogSpec, query gitbackend.SearchQuery) ([]string, error) {
+			gotQuery = query
+			return []string{"a", "b"}, nil
+		},
+	})
+	matches, err := svc.SearchCommits(context.Background(), LogSpec{}, SearchQuery{Kind: SearchPickaxe, Pattern: "needle "})
+	if err != nil {
+		t.Fatalf("SearchCommits: %v", err)
+	}
+	if gotQuery.Pattern != "needle " || gotQuery.Kind != SearchPickaxe {
+		t.Fatalf("unexpected query: %+v", gotQuery)
+	}
+	if len(matches) != 2 || !matches["a"] || !matches["b"] {
+		t.Fatalf("unexpected matches: %v", matches)
+	}
+	if _, err := svc.SearchCommits(context.Background(), LogSpec{}, SearchQuery{Kind: SearchPaths, Pattern: " "}); err == nil {
+		t.Fatal("expected error for empty pattern")
+	}
+}
@@ -29,16 +29,76 @@ type Entry struct {
 	Commit     *Commit
 	Summary    string
 	SearchText string
-	Graph      string
+	// Graph is the commit line of the textual rendering of GraphRow; the
+	// connectors to the next row are GraphRow.Connectors.
+	Graph    string
+	GraphRow *GraphRow
 }
 
+// FileSection is the diff of one file, starting at Line of the diff text.
 type FileSection struct {
 	Path string
 	Line int
+	// Status is the change type, as in git diff --name-status: A, M, D, R, C
+	// or T. Rows added by the GUI leave it empty.
+	Status string
+	// OldPath is the source of a rename or copy.
+	OldPath string
+	// Added and Deleted count the changed lines, like git diff --numstat.
+	// Binary files have no line counts.
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// BackendKind selects how a Service reads the repository.
+type BackendKind uint8
+
+const (
+	// BackendCLI shells out to the git executable.
+	BackendCLI BackendKind = iota
+	// BackendNative reads objects and refs directly, without a git executable.
+	// It cannot show or modify local changes.
+	BackendNative
+)
+
+func (k BackendKind) String() string {
+	switch k {
+	case BackendCLI:
+		return "cli"
+	case BackendNative:
+		return "native"
+	default:
+		return fmt.Sprintf("BackendKind(%d)", uint8(k))
+	}
+}
+
+func BackendKindFromString(raw string) (BackendKind, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", BackendCLI.String():
+		return BackendCLI, nil
+	case BackendNative.String():
+		return BackendNative, nil
+	default:
+		return BackendCLI, fmt.Errorf("unknown backend %q (want %s or %s)", raw, BackendCLI, BackendNative)
+	}
 }
 
 func Open(repoPath string) (*Service, error) {
-	backend, err := gitbackend.OpenCLI(repoPath)
+	return OpenBackend(repoPath, BackendCLI)
+}
+
+func OpenBackend(repoPath string, kind BackendKind) (*Service, error) {
+	var (
+		backend gitbackend.Backend
+		err     error
+	)
+	switch kind {
+	case BackendNative:
+		backend, err = gitbackend.OpenNative(repoPath)
+	default:
+		backend, err = gitbackend.OpenCLI(repoPath)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +135,14 @@ func (s *Service) SetGraphMaxColumns(maxColumns int) {
 	}
 }
 
-func (s *Service) ScanCommits(skip, batch uint) ([]*Entry, string, bool, error) {
-	slog.Debug("ScanCommits start", slog.Uint64("skip", uint64(skip)), slog.Uint64("batch", uint64(batch)))
+// ScanCommits returns up to batch commits selected by spec, skipping the first
+// skip commits. An empty spec walks the history reachable from HEAD.
+func (s *Service) ScanCommits(spec LogSpec, skip, batch uint) ([]*Entry, string, bool, error) {
+	slog.Debug("ScanCommits start",
+		slog.Uint64("skip", uint64(skip)),
+		slog.Uint64("batch", uint64(batch)),
+		slog.String("spec", spec.String()),
+	)
 	startTotal := time.Now()
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -86,7 +152,7 @@ func (s *Service) ScanCommits(skip, batch uint) ([]*Entry, string, bool, error)
 	if err != nil {
 		return nil, "", false, fmt.Errorf("resolve HEAD: %w", err)
 	}
-	if !ok {
+	if !ok && len(spec.Revisions) == 0 {
 		if s.scan != nil {
 			s.scan.close()
 			s.scan = nil
@@ -96,13 +162,13 @@ func (s *Service) ScanCommits(skip, batch uint) ([]*Entry, string, bool, error)
 	headDur := time.Since(startHead)
 
 	startSession := time.Now()
-	if err := s.ensureScanSessionLocked(headHash, headName); err != nil {
+	if err := s.ensureScanSessionLocked(headHash, headName, spec); err != nil {
 		return nil, "", false, err
 	}
 	sessionDur := time.Since(startSession)
 	// If the caller requests a different position than the current session, reset and advance to skip.
 	if skip != s.scan.returned {
-		if err := s.alignSessionLocked(skip, headHash, headName); err != nil {
+		if err := s.alignSessionLocked(skip, headHash, headName, spec); err != nil {
 			if err == io.EOF {
 				return nil, s.scan.headName, false, nil
 			}
@@ -150,14 +216,15 @@ func (s *Service) ScanCommits(skip, batch uint) ([]*Entry, string, bool, error)
 	)
 	return entries, s.scan.headName, hasMore, nil
 }
-func (s *Service) alignSessionLocked(skip uint, headHash, headName string) error {
+
+func (s *Service) alignSessionLocked(skip uint, headHash, headName string, spec LogSpec) error {
 	start := time.Now()
 	slog.Debug("ScanCommits reset session",
 		slog.Uint64("requested_skip", uint64(skip)),
 		slog.Uint64("session_returned", uint64(s.scan.returned)),
 		slog.String("head", s.scan.headName),
 	)
-	if err := s.resetScanLocked(headHash, headName); err != nil {
+	if err := s.resetScanLocked(headHash, headName, s.scan.tips, spec); err != nil {
 		return err
 	}
 	if err := s.scan.discard(skip); err != nil {
@@ -254,83 +321,3 @@ func localDiffHeader(staged bool) string {
 	}
 	return "Local uncommitted changes, not checked in to index"
 }
-
-type graphBuilder struct {
-	columns    []string
-	maxColumns int
-}
-
-func newGraphBuilder(maxColumns int) *graphBuilder {
-	if maxColumns <= 0 {
-		maxColumns = DefaultGraphMaxColumns
-	}
-	return &graphBuilder{maxColumns: maxColumns}
-}
-
-func (g *graphBuilder) trim() {
-	if g.maxColumns <= 0 {
-		return
-	}
-	if len(g.columns) > g.maxColumns {
-		g.columns = g.columns[:g.maxColumns]
-	}
-}
-
-func (g *graphBuilder) Line(c *Commit) string {
-	idx := g.columnIndex(c.Hash)
-	if idx == -1 {
-		g.columns = append([]string{c.Hash}, g.columns...)
-		idx = 0
-	}
-	g.trim()
-	var b strings.Builder
-	for i := range g.columns {
-		if i == idx {
-			b.WriteString("*")
-		} else {
-			b.WriteString("|")
-		}
-		if i != len(g.columns)-1 {
-			b.WriteString(" ")
-		}
-	}
-	g.advance(idx, c.ParentHashes)
-	return b.String()
-}
-
-func (g *graphBuilder) columnIndex(hash string) int {
-	for i, h := range g.columns {
-		if h == hash {
-			return i
-		}
-	}
-	return -1
-}
-
-func (g *graphBuilder) advance(idx int, parents []string) {
-	if len(parents) == 0 {
-		g.columns = append(g.columns[:idx], g.columns[idx+1:]...)
-		return
-	}
-	primary := parents[0]
-	g.columns[idx] = primary
-	for i := 1; i < len(parents); i++ {
-		parent := parents[i]
-		g.removeColumn(parent)
-		pos := idx + i
-		if pos > len(g.columns) {
-			pos = len(g.columns)
-		}
-		g.columns = append(g.columns[:pos], append([]string{parent}, g.columns[pos:]...)...)
-	}
-	g.trim()
-}
-
-func (g *graphBuilder) removeColumn(hash string) {
-	for i, h := range g.columns {
-		if h == hash {
-			g.columns = append(g.columns[:i], g.columns[i+1:]...)
-			return
-		}
-	}
-}
@@ -62,6 +62,49 @@ func TestOpenResolvesWorkdirToRepoRoot(t *testing.T) {
 	}
 }
 
+func TestOpenBackendNative(t *testing.T) {
+	dir, hashes := createTestRepo(t, 3)
+	svc, err := OpenBackend(dir, BackendNative)
+	if err != nil {
+		t.Fatalf("OpenBackend: %v", err)
+	}
+	entries, head, hasMore, err := svc.ScanCommits(LogSpec{}, 0, 10)
+	if err != nil {
+		t.Fatalf("ScanCommits: %v", err)
+	}
+	if head != "main" || hasMore || len(entries) != len(hashes) {
+		t.Fatalf("unexpected scan: head=%q hasMore=%v entries=%d", head, hasMore, len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Commit.Hash != hashes[i] {
+			t.Fatalf("entry %d: got %s want %s", i, entry.Commit.Hash, hashes[i])
+		}
+	}
+	changes, err := svc.LocalChanges()
+	if err != nil {
+		t.Fatalf("LocalChanges: %v", err)
+	}
+	if changes.HasWorktree || changes.HasStaged {
+		t.Fatalf("expected no local changes from native backend, got %+v", changes)
+	}
+}
+
+func TestBackendKindFromString(t *testing.T) {
+	tests := map[string]BackendKind{"": BackendCLI, "cli": BackendCLI, " Native ": BackendNative}
+	for in, want := range tests {
+		got, err := BackendKindFromString(in)
+		if err != nil || got != want {
+			t.Fatalf("BackendKindFromString(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := BackendKindFromString("svn"); err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+	if got := BackendKind(7).String(); got != "BackendKind(7)" {
+		t.Fatalf("BackendKind(7).String() = %q", got)
+	}
+}
+
 func TestNewEntrySearchText(t *testing.T) {
 	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	commit := &Commit{
@@ -86,20 +129,89 @@ func TestGraphBuilderLine(t *testing.T) {
 	other := strings.Repeat("d", 40)
 
 	builder := newGraphBuilder(DefaultGraphMaxColumns)
-	lineHead := builder.Line(&Commit{Hash: head, ParentHashes: []string{parent, merge}})
-	if lineHead != "*" && lineHead != "* |" {
-		t.Fatalf("unexpected graph for merge head: %q", lineHead)
+	rowHead := builder.Row(&Commit{Hash: head, ParentHashes: []string{parent, merge}})
+	if line := rowHead.String(); line != "*" {
+		t.Fatalf("unexpected graph for merge head: %q", line)
+	}
+	if connectors := rowHead.Connectors(); connectors != `|\` {
+		t.Fatalf("unexpected connectors below merge head: %q", connectors)
 	}
-	lineParent := builder.Line(&Commit{Hash: parent, ParentHashes: []string{other}})
+	lineParent := builder.Row(&Commit{Hash: parent, ParentHashes: []string{other}}).String()
 	if lineParent != "* |" {
 		t.Fatalf("unexpected graph after shifting columns: %q", lineParent)
 	}
-	lineMerge := builder.Line(&Commit{Hash: merge})
+	lineMerge := builder.Row(&Commit{Hash: merge}).String()
 	if lineMerge != "| *" {
 		t.Fatalf("unexpected graph for secondary branch: %q", lineMerge)
 	}
 }
 
+func TestGraphBuilderEdges(t *testing.T) {
+	hash := func(c byte) string { return strings.Repeat(string(c), 40) }
+	// a (merge of b and c), b and c both fork from d, then d is the root.
+	builder := newGraphBuilder(DefaultGraphMaxColumns)
+	tests := []struct {
+		commit     *Commit
+		column     int
+		line       string
+		connectors string
+		edges      []GraphEdge
+	}{
+		{
+			commit:     &Commit{Hash: hash('a'), ParentHashes: []string{hash('b'), hash('c')}},
+			column:     0,
+			line:       "*",
+			connectors: `|\`,
+			edges: []GraphEdge{
+				{From: 0, To: 0, Kind: GraphEdgeContinue, Color: 0},
+				{From: 0, To: 1, Kind: GraphEdgeMerge, Color: 1},
+			},
+		},
+		{
+			commit: &Commit{Hash: hash('b'), ParentHashes: []string{hash('d')}},
+			column: 0,
+			line:   "* |",
+			edges: []GraphEdge{
+				{From: 1, To: 1, Kind: GraphEdgeContinue, Color: 1},
+				{From: 0, To: 0, Kind: GraphEdgeContinue, Color: 0},
+			},
+		},
+		{
+			commit:     &Commit{Hash: hash('c'), ParentHashes: []string{hash('d')}},
+			column:     1,
+			line:       "| *",
+			connectors: "|/",
+			edges: []GraphEdge{
+				{From: 0, To: 0, Kind: GraphEdgeContinue, Color: 0},
+				{From: 1, To: 0, Kind: GraphEdgeFork, Color: 1},
+			},
+		},
+		{
+			commit: &Commit{Hash: hash('d')},
+			column: 0,
+			line:   "*",
+		},
+	}
+	for _, tt := range tests {
+		row := builder.Row(tt.commit)
+		if row.Column != tt.column {
+			t.Fatalf("commit %s: column = %d, want %d", tt.commit.Hash[:1], row.Column, tt.column)
+		}
+		if got := row.String(); got != tt.line {
+			t.Fatalf("commit %s: line = %q, want %q", tt.commit.Hash[:1], got, tt.line)
+		}
+		if got := row.Connectors(); got != tt.connectors {
+			t.Fatalf("commit %s: connectors = %q, want %q", tt.commit.Hash[:1], got, tt.connectors)
+		}
+		if !slices.Equal(row.Edges, tt.edges) {
+			t.Fatalf("commit %s: edges = %+v, want %+v", tt.commit.Hash[:1], row.Edges, tt.edges)
+		}
+	}
+	if len(builder.columns) != 0 {
+		t.Fatalf("expected all lanes to end, got %v", builder.columns)
+	}
+}
+
 func TestGraphBuilderCapsColumns(t *testing.T) {
 	builder := newGraphBuilder(DefaultGraphMaxColumns)
 	builder.maxColumns = 50
@@ -111,7 +223,7 @@ func TestGraphBuilderCapsColumns(t *testing.T) {
 		if parent == parentBase {
 			parent = fmt.Sprintf("%040x", i+2000)
 		}
-		line := builder.Line(&Commit{Hash: hash, ParentHashes: []string{parent}})
+		line := builder.Row(&Commit{Hash: hash, ParentHashes: []string{parent}}).String()
 		if len(builder.columns) > builder.maxColumns {
 			t.Fatalf("columns grew beyond cap: len=%d cap=%d", len(builder.columns), builder.maxColumns)
 		}
@@ -198,7 +310,7 @@ func TestScanCommitsPaginationDoesNotSkipExtraCommit(t *testing.T) {
 		t.Fatalf("Open: %v", err)
 	}
 
-	entries1, _, more, err := svc.ScanCommits(0, 2)
+	entries1, _, more, err := svc.ScanCommits(LogSpec{}, 0, 2)
 	if err != nil {
 		t.Fatalf("ScanCommits(0): %v", err)
 	}
@@ -215,7 +327,7 @@ func TestScanCommitsPaginationDoesNotSkipExtraCommit(t *testing.T) {
 		t.Fatalf("expected graph strings to be populated")
 	}
 
-	entries2, _, more, err := svc.ScanCommits(2, 2)
+	entries2, _, more, err := svc.ScanCommits(LogSpec{}, 2, 2)
 	if err != nil {
 		t.Fatalf("ScanCommits(2): %v", err)
 	}
@@ -229,7 +341,7 @@ func TestScanCommitsPaginationDoesNotSkipExtraCommit(t *testing.T) {
 		t.Fatalf("unexpected second batch hashes: %s %s", entries2[0].Commit.Hash, entries2[1].Commit.Hash)
 	}
 
-	entries3, _, more, err := svc.ScanCommits(4, 2)
+	entries3, _, more, err := svc.ScanCommits(LogSpec{}, 4, 2)
 	if err != nil {
 		t.Fatalf("ScanCommits(4): %v", err)
 	}
@@ -250,14 +362,14 @@ func TestScanCommitsSkipMismatchResetsSession(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Open: %v", err)
 	}
-	entries1, _, _, err := svc.ScanCommits(0, 2)
+	entries1, _, _, err := svc.ScanCommits(LogSpec{}, 0, 2)
 	if err != nil {
 		t.Fatalf("ScanCommits(0): %v", err)
 	}
 	if len(entries1) != 2 {
 		t.Fatalf("expected 2 entries, got %d", len(entries1))
 	}
-	entries2, _, _, err := svc.ScanCommits(0, 2)
+	entries2, _, _, err := svc.ScanCommits(LogSpec{}, 0, 2)
 	if err != nil {
 		t.Fatalf("ScanCommits(0) second time: %v", err)
 	}
@@ -269,6 +381,83 @@ func TestScanCommitsSkipMismatchResetsSession(t *testing.T) {
 	}
 }
 
+func TestScanCommitsRestartsWhenRefsMove(t *testing.T) {
+	dir, hashes := createTestRepo(t, 3)
+	svc, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	spec := LogSpec{Revisions: []string{"--all"}}
+	entries, _, _, err := svc.ScanCommits(spec, 0, 1)
+	if err != nil {
+		t.Fatalf("ScanCommits(0): %v", err)
+	}
+	if len(entries) != 1 || entries[0].Commit.Hash != hashes[0] {
+		t.Fatalf("unexpected first batch %v", entries)
+	}
+
+	// Move another branch without touching HEAD.
+	when := time.Unix(100, 0).UTC().Format(time.RFC3339)
+	env := []string{"GIT_AUTHOR_DATE=" + when, "GIT_COMMITTER_DATE=" + when}
+	side := runGit(t, dir, env, "commit-tree", "-p", "HEAD", "-m", "side", "HEAD^{tree}")
+	runGit(t, dir, nil, "update-ref", "refs/heads/side", side)
+
+	entries, _, _, err = svc.ScanCommits(spec, 1, 10)
+	if err != nil {
+		t.Fatalf("ScanCommits(1): %v", err)
+	}
+	got := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		got = append(got, entry.Commit.Hash)
+	}
+	if want := hashes; !slices.Equal(got, want) {
+		t.Fatalf("hashes after the branch moved = %v, want %v", got, want)
+	}
+}
+
+func TestScanCommitsHonorsLogSpec(t *testing.T) {
+	dir, hashes := createTestRepo(t, 3)
+	if err := os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	runGit(t, dir, nil, "add", "other.txt")
+	when := time.Unix(10, 0).UTC().Format(time.RFC3339)
+	env := []string{"GIT_AUTHOR_DATE=" + when, "GIT_COMMITTER_DATE=" + when}
+	runGit(t, dir, env, "commit", "-m", "other", "--quiet", "--no-gpg-sign")
+	otherHash := runGit(t, dir, nil, "rev-parse", "HEAD")
+
+	svc, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	entries, _, more, err := svc.ScanCommits(LogSpec{Revisions: []string{hashes[1] + "..main"}}, 0, 10)
+	if err != nil {
+		t.Fatalf("ScanCommits(range): %v", err)
+	}
+	if more {
+		t.Fatalf("expected range to be exhausted")
+	}
+	gotHashes := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		gotHashes = append(gotHashes, entry.Commit.Hash)
+	}
+	if want := []string{otherHash, hashes[0]}; !slices.Equal(gotHashes, want) {
+		t.Fatalf("range hashes = %v, want %v", gotHashes, want)
+	}
+
+	entries, _, _, err = svc.ScanCommits(LogSpec{Paths: []string{"other.txt"}}, 0, 10)
+	if err != nil {
+		t.Fatalf("ScanCommits(paths): %v", err)
+	}
+	if len(entries) != 1 || entries[0].Commit.Hash != otherHash {
+		t.Fatalf("expected only %s for path-limited scan, got %d entries", otherHash, len(entries))
+	}
+	if parents := entries[0].Commit.ParentHashes; len(parents) != 0 {
+		t.Fatalf("expected parents to be rewritten away, got %v", parents)
+	}
+}
+
 func TestSetGraphMaxColumnsAppliesToSession(t *testing.T) {
 	dir, _ := createTestRepo(t, 3)
 	svc, err := Open(dir)
@@ -277,7 +466,7 @@ func TestSetGraphMaxColumnsAppliesToSession(t *testing.T) {
 	}
 
 	svc.SetGraphMaxColumns(50)
-	if _, _, _, err := svc.ScanCommits(0, 1); err != nil {
+	if _, _, _, err := svc.ScanCommits(LogSpec{}, 0, 1); err != nil {
 		t.Fatalf("ScanCommits: %v", err)
 	}
 	if svc.scan == nil || svc.scan.graphBuilder == nil {
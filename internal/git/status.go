@@ -1,6 +1,7 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -8,5 +9,10 @@ func (s *Service) LocalChanges() (LocalChanges, error) {
 	if s.backend == nil {
 		return LocalChanges{}, fmt.Errorf("repository root not set")
 	}
-	return s.backend.LocalChangesStatus()
+	changes, err := s.backend.LocalChangesStatus()
+	if errors.Is(err, errors.ErrUnsupported) {
+		// Backends without worktree support simply have no local changes to show.
+		return LocalChanges{}, nil
+	}
+	return changes, err
 }
@@ -2,6 +2,7 @@ package git
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -18,7 +19,7 @@ func TestLocalChanges_NoBackend(t *testing.T) {
 func TestLocalChanges_DelegatesToBackend(t *testing.T) {
 	t.Parallel()
 
-	want := LocalChanges{HasWorktree: true, HasStaged: false}
+	want := LocalChanges{HasWorktree: true, HasStaged: false, Conflicts: []string{"a.txt"}}
 	backend := &fakeBackend{
 		repoPath: "repo",
 		localChangesStatusFunc: func() (LocalChanges, error) {
@@ -31,7 +32,7 @@ func TestLocalChanges_DelegatesToBackend(t *testing.T) {
 	if err != nil {
 		t.Fatalf("LocalChanges: %v", err)
 	}
-	if got != want {
+	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("LocalChanges = %+v, want %+v", got, want)
 	}
 }
@@ -0,0 +1,35 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tree lists the files and directories of commitHash, directories before
+// their contents.
+func (s *Service) Tree(commitHash string) ([]TreeEntry, error) {
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return nil, fmt.Errorf("repository root not set")
+	}
+	commitHash = strings.TrimSpace(commitHash)
+	if commitHash == "" {
+		return nil, fmt.Errorf("commit not specified")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.ListTree(commitHash)
+}
+
+// Blob returns the contents of the file at path in commitHash.
+func (s *Service) Blob(commitHash, path string) ([]byte, error) {
+	if s.backend == nil || s.backend.RepoPath() == "" {
+		return nil, fmt.Errorf("repository root not set")
+	}
+	commitHash = strings.TrimSpace(commitHash)
+	if commitHash == "" || path == "" {
+		return nil, fmt.Errorf("commit or path not specified")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.ReadBlob(commitHash, path)
+}
@@ -0,0 +1,55 @@
+package git
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTree_DelegatesToBackend(t *testing.T) {
+	t.Parallel()
+
+	want := []TreeEntry{
+		{Path: "dir", Mode: "040000", Kind: TreeEntryTree, Hash: "t1"},
+		{Path: "dir/a.go", Mode: "100644", Kind: TreeEntryBlob, Hash: "b1"},
+	}
+	var gotHash string
+	backend := &fakeBackend{
+		repoPath: "repo",
+		listTreeFunc: func(commitHash string) ([]TreeEntry, error) {
+			gotHash = commitHash
+			return want, nil
+		},
+	}
+	got, err := NewWithBackend(backend).Tree(" abc ")
+	if err != nil {
+		t.Fatalf("Tree: %v", err)
+	}
+	if gotHash != "abc" || !slices.Equal(got, want) {
+		t.Fatalf("Tree = %+v for %q, want %+v for abc", got, gotHash, want)
+	}
+}
+
+func TestBlob_RequiresCommitAndPath(t *testing.T) {
+	t.Parallel()
+
+	backend := &fakeBackend{
+		repoPath: "repo",
+		readBlobFunc: func(commitHash, path string) ([]byte, error) {
+			return []byte(commitHash + ":" + path), nil
+		},
+	}
+	svc := NewWithBackend(backend)
+	if _, err := svc.Blob("", "a.go"); err == nil {
+		t.Fatal("expected error without commit")
+	}
+	if _, err := svc.Blob("abc", ""); err == nil {
+		t.Fatal("expected error without path")
+	}
+	got, err := svc.Blob("abc", "dir/a.go")
+	if err != nil {
+		t.Fatalf("Blob: %v", err)
+	}
+	if string(got) != "abc:dir/a.go" {
+		t.Fatalf("Blob = %q", got)
+	}
+}
@@ -5,3 +5,58 @@ import gitbackend "github.com/thiagokokada/gitk-go/internal/git/backend"
 type Signature = gitbackend.Signature
 type Commit = gitbackend.Commit
 type LocalChanges = gitbackend.LocalChanges
+type Ref = gitbackend.Ref
+type RefKind = gitbackend.RefKind
+type Stash = gitbackend.Stash
+type LogSpec = gitbackend.LogSpec
+type SearchKind = gitbackend.SearchKind
+type SearchQuery = gitbackend.SearchQuery
+type DiffOptions = gitbackend.DiffOptions
+type DiffWhitespace = gitbackend.DiffWhitespace
+type DiffAlgorithm = gitbackend.DiffAlgorithm
+type TreeEntry = gitbackend.TreeEntry
+type TreeEntryKind = gitbackend.TreeEntryKind
+type BlameChunk = gitbackend.BlameChunk
+type BlameStream = gitbackend.BlameStream
+type ResetMode = gitbackend.ResetMode
+type ConflictError = gitbackend.ConflictError
+
+const (
+	RefKindBranch       = gitbackend.RefKindBranch
+	RefKindRemoteBranch = gitbackend.RefKindRemoteBranch
+	RefKindTag          = gitbackend.RefKindTag
+)
+
+const (
+	TreeEntryBlob      = gitbackend.TreeEntryBlob
+	TreeEntryTree      = gitbackend.TreeEntryTree
+	TreeEntrySubmodule = gitbackend.TreeEntrySubmodule
+)
+
+const (
+	SearchPickaxe      = gitbackend.SearchPickaxe
+	SearchChangedLines = gitbackend.SearchChangedLines
+	SearchPaths        = gitbackend.SearchPaths
+)
+
+const (
+	WhitespaceShow         = gitbackend.WhitespaceShow
+	WhitespaceIgnoreAll    = gitbackend.WhitespaceIgnoreAll
+	WhitespaceIgnoreChange = gitbackend.WhitespaceIgnoreChange
+)
+
+const (
+	DiffAlgorithmDefault   = gitbackend.DiffAlgorithmDefault
+	DiffAlgorithmMyers     = gitbackend.DiffAlgorithmMyers
+	DiffAlgorithmMinimal   = gitbackend.DiffAlgorithmMinimal
+	DiffAlgorithmPatience  = gitbackend.DiffAlgorithmPatience
+	DiffAlgorithmHistogram = gitbackend.DiffAlgorithmHistogram
+)
+
+const (
+	ResetSoft  = gitbackend.ResetSoft
+	ResetMixed = gitbackend.ResetMixed
+	ResetHard  = gitbackend.ResetHard
+)
+
+const NoContext = gitbackend.NoContext
@@ -5,11 +5,11 @@ import (
 	"strings"
 )
 
-func (s *Service) WorktreeDiff(staged bool) (string, []FileSection, error) {
+func (s *Service) WorktreeDiff(staged bool, opts DiffOptions) (string, []FileSection, error) {
 	if s.backend == nil || s.backend.RepoPath() == "" {
 		return "", nil, fmt.Errorf("repository root not set")
 	}
-	diffText, err := s.backend.WorktreeDiffText(staged)
+	diffText, err := s.backend.WorktreeDiffText(staged, opts)
 	if err != nil {
 		return "", nil, err
 	}
@@ -17,17 +17,6 @@ func (s *Service) WorktreeDiff(staged bool) (string, []FileSection, error) {
 		return "", nil, nil
 	}
 
-	header := localDiffHeader(staged)
-	if !strings.HasSuffix(header, "\n") {
-		header += "\n"
-	}
-	var b strings.Builder
-	b.WriteString(header)
-	b.WriteString(diffText)
-	if !strings.HasSuffix(diffText, "\n") {
-		b.WriteByte('\n')
-	}
-	lineOffset := strings.Count(header, "\n")
-	sections := parseGitDiffSections(diffText, lineOffset)
-	return b.String(), sections, nil
+	text, sections := joinDiff(localDiffHeader(staged), diffText)
+	return text, sections, nil
 }
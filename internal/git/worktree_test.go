@@ -13,7 +13,7 @@ func TestWorktreeDiff_Empty(t *testing.T) {
 	}
 	svc := NewWithBackend(backend)
 
-	diff, sections, err := svc.WorktreeDiff(false)
+	diff, sections, err := svc.WorktreeDiff(false, DiffOptions{})
 	if err != nil {
 		t.Fatalf("WorktreeDiff: %v", err)
 	}
@@ -37,10 +37,14 @@ func TestWorktreeDiff_PrependsHeaderAndOffsetsSections(t *testing.T) {
 	}
 	svc := NewWithBackend(backend)
 
-	diff, sections, err := svc.WorktreeDiff(true)
+	opts := DiffOptions{Whitespace: WhitespaceIgnoreAll, Context: 8}
+	diff, sections, err := svc.WorktreeDiff(true, opts)
 	if err != nil {
 		t.Fatalf("WorktreeDiff: %v", err)
 	}
+	if backend.lastDiffOptions != opts {
+		t.Fatalf("backend options = %+v, want %+v", backend.lastDiffOptions, opts)
+	}
 	if diff == "" {
 		t.Fatalf("expected diff output")
 	}
@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/thiagokokada/gitk-go/internal/debounce"
 	"github.com/thiagokokada/gitk-go/internal/git"
+	"github.com/thiagokokada/gitk-go/internal/gui/selection"
 	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
 
 	. "modernc.org/tk9.0"
@@ -33,7 +35,12 @@ const (
 
 // RunConfig describes the parameters that control the GUI runtime.
 type RunConfig struct {
-	RepoPath        string
+	RepoPath string
+	// Revisions and Paths select the displayed commits like gitk's
+	// "[revisions] [-- paths]" arguments. Both empty means HEAD.
+	Revisions       []string
+	Paths           []string
+	Backend         git.BackendKind
 	Batch           uint
 	GraphMaxColumns uint
 	GraphCanvas     bool
@@ -50,7 +57,7 @@ func Run(cfg RunConfig) error {
 	if err := InitializeExtension("eval"); err != nil && err != AlreadyInitialized {
 		return fmt.Errorf("init eval extension: %v", err)
 	}
-	svc, err := git.Open(cfg.RepoPath)
+	svc, err := git.OpenBackend(cfg.RepoPath, cfg.Backend)
 	if err != nil {
 		return err
 	}
@@ -63,6 +70,8 @@ func Run(cfg RunConfig) error {
 		svc: svc,
 		cfg: controllerConfig{
 			batch:               cfg.Batch,
+			logSpec:             git.LogSpec{Revisions: cfg.Revisions, Paths: cfg.Paths},
+			backend:             cfg.Backend,
 			graphCanvas:         cfg.GraphCanvas,
 			autoReloadRequested: cfg.AutoReload,
 			syntaxHighlight:     cfg.SyntaxHighlight,
@@ -98,6 +107,7 @@ func (a *Controller) run() error {
 	}
 	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
 	applyAppIcon()
+	a.loadLayout()
 	a.buildUI()
 	a.initAutoReload(a.cfg.autoReloadRequested)
 	a.showInitialLoadingRow()
@@ -106,7 +116,12 @@ func (a *Controller) run() error {
 	a.reloadCommitsAsync()
 	App.WmTitle("gitk-go")
 	App.SetResizable(true, true)
-	App.Center().Wait()
+	WmProtocol(App, "WM_DELETE_WINDOW", a.quit)
+	if a.restoreGeometry() {
+		App.Wait()
+	} else {
+		App.Center().Wait()
+	}
 	return nil
 }
 
@@ -142,8 +157,13 @@ func (a *Controller) refreshLocalChangesAsync(prefetch bool) {
 
 func (a *Controller) applyLocalChangeStatus(status git.LocalChanges, repoReady bool, prefetch bool) {
 	actions := a.state.tree.localChangePlan(repoReady, prefetch, status)
+	conflictsChanged := !slices.Equal(a.state.tree.conflicts, status.Conflicts)
+	a.state.tree.conflicts = status.Conflicts
 	a.setLocalRowVisibility(false, actions.showUnstaged)
 	a.setLocalRowVisibility(true, actions.showStaged)
+	if conflictsChanged {
+		a.updateLocalRowLabel()
+	}
 	if actions.resetUnstaged {
 		a.resetLocalDiffState(false)
 	}
@@ -159,25 +179,32 @@ func (a *Controller) applyLocalChangeStatus(status git.LocalChanges, repoReady b
 }
 
 func (a *Controller) showCommitDetails(entry *git.Entry, index int) {
-	header := git.FormatCommitHeader(entry.Commit)
+	a.state.diff.compare = compareTarget{}
+	rel := a.commitRelations(entry.Commit)
+	header := git.FormatCommitHeaderWith(entry.Commit, rel)
 	hash := entry.Commit.Hash
 	a.state.selection.SetCommit(entry, index)
+	a.state.history.Visit(hash)
+	a.updateHistoryButtons()
+	a.updateMergeDiffSelector(entry.Commit)
+	a.browseCommit(hash)
 	a.setFileSections(nil)
 	a.writeDetailText(header+"\nLoading diff...", false)
-	a.scheduleDiffLoad(entry, hash)
+	a.scheduleDiffLoad(entry, hash, rel)
 }
 
 func (a *Controller) showLocalChanges(staged bool) {
 	a.cancelPendingDiffLoad()
+	a.state.diff.compare = compareTarget{}
+	a.updateMergeDiffSelector(nil)
+	a.browseCommit("")
 	a.state.selection.SetLocal(staged)
+	a.state.diff.scroll = ""
 	a.renderLocalChanges(staged, true)
 }
 
 func (a *Controller) renderLocalChanges(staged bool, requestReload bool) {
-	header := localUnstagedLabel
-	if staged {
-		header = localStagedLabel
-	}
+	header := localRowLabel(staged, a.state.tree.conflicts)
 	snap := a.snapshotLocalDiff(staged)
 	if requestReload && snap.ready {
 		a.presentLocalDiff(header, snap)
@@ -211,6 +238,7 @@ func (a *Controller) presentLocalDiff(header string, snap localDiffSnapshot) {
 	diff, sections := prepareDiffDisplay(diff, snap.sections)
 	a.writeDetailText(diff, len(sections) > 0)
 	a.setFileSections(sections)
+	a.restoreLocalDiffScroll()
 }
 
 func (a *Controller) snapshotLocalDiff(staged bool) localDiffSnapshot {
@@ -231,14 +259,14 @@ func (a *Controller) ensureLocalDiffLoading(staged bool, force bool) {
 	if !started {
 		return
 	}
-	go a.computeLocalDiff(staged, gen)
+	go a.computeLocalDiff(staged, gen, a.state.diff.options)
 }
 
-func (a *Controller) computeLocalDiff(staged bool, gen int) {
+func (a *Controller) computeLocalDiff(staged bool, gen int, opts git.DiffOptions) {
 	if a.svc == nil {
 		return
 	}
-	diff, sections, err := a.svc.WorktreeDiff(staged)
+	diff, sections, err := a.svc.WorktreeDiff(staged, opts)
 	state := a.localDiffState(staged, true)
 	state.Lock()
 	defer state.Unlock()
@@ -290,15 +318,16 @@ func (a *Controller) onLocalDiffLoaded(staged bool) {
 	a.renderLocalChanges(staged, false)
 }
 
-func (a *Controller) populateDiff(entry *git.Entry, hash string) {
-	diff, sections, err := a.svc.Diff(entry.Commit)
+func (a *Controller) populateDiff(entry *git.Entry, hash string, rel *git.CommitRelations, mode git.MergeDiffMode, opts git.DiffOptions) {
+	diff, sections, err := a.svc.Diff(entry.Commit, rel, mode, opts)
 	if err != nil {
 		diff = fmt.Sprintf("Unable to compute diff: %v", err)
 	}
 	diff, sections = prepareDiffDisplay(diff, sections)
 	highlight := len(sections) > 0
 	PostEvent(func() {
-		if a.currentSelection() != hash {
+		// A file picked in the tree browser replaces the patch.
+		if a.currentSelection() != hash || a.state.browse.shown != "" {
 			return
 		}
 		a.writeDetailText(diff, highlight)
@@ -306,7 +335,7 @@ func (a *Controller) populateDiff(entry *git.Entry, hash string) {
 	}, false)
 }
 
-func (a *Controller) scheduleDiffLoad(entry *git.Entry, hash string) {
+func (a *Controller) scheduleDiffLoad(entry *git.Entry, hash string, rel *git.CommitRelations) {
 	if entry == nil {
 		return
 	}
@@ -316,6 +345,9 @@ func (a *Controller) scheduleDiffLoad(entry *git.Entry, hash string) {
 		defer a.state.diff.mu.Unlock()
 		a.state.diff.pendingDiff = entry
 		a.state.diff.pendingHash = hash
+		a.state.diff.pendingRel = rel
+		a.state.diff.pendingMode = a.state.diff.mergeMode
+		a.state.diff.pendingOpts = a.state.diff.options
 		return debounce.Ensure(&a.state.diff.debouncer, diffDebounceDelay, func() {
 			a.flushDiffDebounce()
 		})
@@ -324,19 +356,23 @@ func (a *Controller) scheduleDiffLoad(entry *git.Entry, hash string) {
 }
 
 func (a *Controller) flushDiffDebounce() {
-	entry, hash := func() (*git.Entry, string) {
+	entry, hash, rel, mode, opts := func() (*git.Entry, string, *git.CommitRelations, git.MergeDiffMode, git.DiffOptions) {
 		a.state.diff.mu.Lock()
 		defer a.state.diff.mu.Unlock()
 		pending := a.state.diff.pendingDiff
 		pendingHash := a.state.diff.pendingHash
+		pendingRel := a.state.diff.pendingRel
+		pendingMode := a.state.diff.pendingMode
+		pendingOpts := a.state.diff.pendingOpts
 		a.state.diff.pendingDiff = nil
 		a.state.diff.pendingHash = ""
-		return pending, pendingHash
+		a.state.diff.pendingRel = nil
+		return pending, pendingHash, pendingRel, pendingMode, pendingOpts
 	}()
 	if entry == nil {
 		return
 	}
-	go a.populateDiff(entry, hash)
+	go a.populateDiff(entry, hash, rel, mode, opts)
 }
 
 func (a *Controller) cancelPendingDiffLoad() {
@@ -349,6 +385,35 @@ func (a *Controller) cancelPendingDiffLoad() {
 	a.state.diff.debouncer = nil
 	a.state.diff.pendingDiff = nil
 	a.state.diff.pendingHash = ""
+	a.state.diff.pendingRel = nil
+}
+
+// restartCommitList drops the loaded commits and loads them again from the
+// start, e.g. after HEAD moved or a.cfg.logSpec changed.
+func (a *Controller) restartCommitList(status string) {
+	a.cancelPendingDiffLoad()
+	a.repo.headRef = ""
+	a.data.commits = nil
+	a.data.index = nil
+	a.data.visible = nil
+	a.state.tree = treeState{marked: a.state.tree.marked}
+	// A pending jump was only checked against the previous view.
+	a.state.jump = jumpState{revision: a.state.jump.revision, seq: a.state.jump.seq + 1}
+	a.state.localDiff = localDiffCache{}
+	a.state.selection = selection.State{}
+
+	a.clearFileList()
+	a.resetFileTree()
+	a.setFileSections(nil)
+	a.setLocalRowVisibility(false, false)
+	a.setLocalRowVisibility(true, false)
+
+	a.clearTreeRows()
+	a.clearDetailText("Select a commit to view its details.")
+	a.showInitialLoadingRow()
+	a.setStatus(status)
+	a.refreshLocalChangesAsync(true)
+	a.reloadCommitsAsync()
 }
 
 func (a *Controller) reloadCommitsAsync() {
@@ -356,21 +421,29 @@ func (a *Controller) reloadCommitsAsync() {
 		return
 	}
 	a.state.tree.loadingBatch = true
+	spec := a.cfg.logSpec
 	slog.Debug("reloadCommitsAsync start",
 		slog.Uint64("batch", uint64(a.cfg.batch)),
 		slog.String("filter", a.state.filter.value),
 	)
 	go func() {
-		entries, head, hasMore, err := a.svc.ScanCommits(0, a.cfg.batch)
+		entries, head, hasMore, err := a.svc.ScanCommits(spec, 0, a.cfg.batch)
 		PostEvent(func() {
+			if !spec.Equal(a.cfg.logSpec) {
+				// The commit list was restarted for another spec meanwhile.
+				return
+			}
 			a.state.tree.loadingBatch = false
 			if err != nil {
 				slog.Error("failed to reload commits", slog.Any("error", err))
+				a.state.jump.hash = ""
 				a.setStatus(fmt.Sprintf("Failed to reload commits: %v", err))
 				return
 			}
 			a.data.commits = entries
 			a.data.visible = entries
+			a.data.index = git.NewCommitIndex()
+			a.data.index.Add(entries)
 			a.repo.headRef = head
 			a.state.tree.hasMore = hasMore
 			slog.Debug("reloadCommitsAsync loaded",
@@ -381,9 +454,13 @@ func (a *Controller) reloadCommitsAsync() {
 			if err := a.loadBranchLabels(); err != nil {
 				slog.Error("failed to refresh branch labels", slog.Any("error", err))
 			}
+			a.refreshRefsAsync()
+			a.resetFilterSearches()
 			a.applyFilterContent(a.state.filter.value)
 			a.refreshLocalChangesAsync(true)
 			a.setStatus(a.statusSummary())
+			a.rerunSearch()
+			a.continuePendingJump()
 		}, false)
 	}()
 }
@@ -399,12 +476,18 @@ func (a *Controller) loadMoreCommitsAsync(prefetch bool) {
 		slog.Bool("prefetch", prefetch),
 		slog.String("filter", a.state.filter.value),
 	)
+	spec := a.cfg.logSpec
 	go func(skipCount uint, background bool) {
-		entries, _, hasMore, err := a.svc.ScanCommits(skipCount, a.cfg.batch)
+		entries, _, hasMore, err := a.svc.ScanCommits(spec, skipCount, a.cfg.batch)
 		PostEvent(func() {
+			if !spec.Equal(a.cfg.logSpec) {
+				return
+			}
 			a.state.tree.loadingBatch = false
 			if err != nil {
 				slog.Error("failed to load more commits", slog.Any("error", err))
+				a.state.filter.search.pendingStep = 0
+				a.state.jump.hash = ""
 				if !background {
 					a.setStatus(fmt.Sprintf("Failed to load more commits: %v", err))
 				}
@@ -415,9 +498,14 @@ func (a *Controller) loadMoreCommitsAsync(prefetch bool) {
 				if !background {
 					a.setStatus("No more commits available.")
 				}
+				a.continuePendingSearch()
+				a.continuePendingJump()
 				return
 			}
 			a.data.commits = append(a.data.commits, entries...)
+			if a.data.index != nil {
+				a.data.index.Add(entries)
+			}
 			a.state.tree.hasMore = hasMore
 			slog.Debug("loadMoreCommitsAsync loaded",
 				slog.Int("added", len(entries)),
@@ -431,6 +519,8 @@ func (a *Controller) loadMoreCommitsAsync(prefetch bool) {
 			a.applyFilterContent(a.state.filter.value)
 			a.refreshLocalChangesAsync(false)
 			a.setStatus(a.statusSummary())
+			a.continuePendingSearch()
+			a.continuePendingJump()
 			if background && a.state.tree.hasMore {
 				go a.loadMoreCommitsAsync(true)
 			}
@@ -444,6 +534,17 @@ func (a *Controller) clearDetailText(msg string) {
 }
 
 func (a *Controller) writeDetailText(content string, highlightDiff bool) {
+	a.state.diff.content = content
+	a.state.diff.highlight = highlightDiff
+	a.state.diff.sections = nil
+	a.state.diff.links = git.CommitHeaderLinks(content)
+	a.clearSyntaxHighlight()
+	if a.state.diff.sideBySide && highlightDiff {
+		a.writeSplitText(content)
+		a.tagCommitLinks()
+		return
+	}
+	a.showSplitDiff(false)
 	a.ui.diffDetail.Configure(State(NORMAL))
 	a.ui.diffDetail.Delete("1.0", END)
 	a.ui.diffDetail.Insert("1.0", content)
@@ -453,25 +554,33 @@ func (a *Controller) writeDetailText(content string, highlightDiff bool) {
 		a.ui.diffDetail.TagRemove("diffAdd", "1.0", END)
 		a.ui.diffDetail.TagRemove("diffDel", "1.0", END)
 		a.ui.diffDetail.TagRemove("diffHeader", "1.0", END)
+		a.ui.diffDetail.TagRemove("diffAddWord", "1.0", END)
+		a.ui.diffDetail.TagRemove("diffDelWord", "1.0", END)
 	}
 	if a.cfg.syntaxHighlight && highlightDiff {
 		a.applySyntaxHighlight(content)
-	} else {
-		a.clearSyntaxHighlight()
 	}
 	a.ui.diffDetail.Configure(State("disabled"))
+	a.tagCommitLinks()
 }
 
 func (a *Controller) highlightDiffLines(content string) {
 	a.ui.diffDetail.TagRemove("diffAdd", "1.0", END)
 	a.ui.diffDetail.TagRemove("diffDel", "1.0", END)
 	a.ui.diffDetail.TagRemove("diffHeader", "1.0", END)
+	a.ui.diffDetail.TagRemove("diffAddWord", "1.0", END)
+	a.ui.diffDetail.TagRemove("diffDelWord", "1.0", END)
 	lines := strings.Split(content, "\n")
+	cols := 1
 	for i, line := range lines {
 		if len(line) == 0 {
 			continue
 		}
-		tag := diffLineTag(line)
+		if n, ok := diffHunkColumns(line); ok {
+			cols = n
+			continue
+		}
+		tag := diffLineTag(line, cols)
 		if tag == "" {
 			continue
 		}
@@ -483,14 +592,112 @@ func (a *Controller) highlightDiffLines(content string) {
 		}
 		a.ui.diffDetail.TagAdd(tag, start, end)
 	}
+	for _, span := range wordDiffSpans(content) {
+		start := fmt.Sprintf("%d.%d", span.line+1, span.start)
+		end := fmt.Sprintf("%d.%d", span.line+1, span.end)
+		a.ui.diffDetail.TagAdd(span.tag, start, end)
+	}
+}
+
+// writeSplitText renders content in the side-by-side view.
+func (a *Controller) writeSplitText(content string) {
+	a.showSplitDiff(true)
+	split := buildSideBySide(content)
+	a.state.diff.split = split
+	for _, right := range []bool{false, true} {
+		text := a.ui.diffLeft
+		if right {
+			text = a.ui.diffRight
+		}
+		text.Configure(State(NORMAL))
+		text.Delete("1.0", END)
+		text.Insert("1.0", split.text(right))
+		for i, row := range split.rows {
+			cell := row.left
+			if right {
+				cell = row.right
+			}
+			if cell.tag == "" {
+				continue
+			}
+			lineNo := i + 1
+			text.TagAdd(cell.tag, fmt.Sprintf("%d.0", lineNo), fmt.Sprintf("%d.0", lineNo+1))
+		}
+	}
+	for _, span := range wordDiffSpans(content) {
+		text := a.ui.diffLeft
+		if span.tag == "diffAddWord" {
+			text = a.ui.diffRight
+		}
+		// Split cells have no +/- marker.
+		lineNo := split.lineMap[span.line] + 1
+		text.TagAdd(span.tag, fmt.Sprintf("%d.%d", lineNo, span.start-1), fmt.Sprintf("%d.%d", lineNo, span.end-1))
+	}
+	a.ui.diffLeft.Configure(State("disabled"))
+	a.ui.diffRight.Configure(State("disabled"))
+	if a.cfg.syntaxHighlight {
+		a.applySplitSyntaxHighlight(split)
+	}
+}
+
+func (a *Controller) showSplitDiff(split bool) {
+	if a.state.diff.splitShown == split {
+		return
+	}
+	a.state.diff.splitShown = split
+	if split {
+		GridRemove(a.ui.diffUnified.Window)
+		Grid(a.ui.diffSplit)
+	} else {
+		GridRemove(a.ui.diffSplit.Window)
+		Grid(a.ui.diffUnified)
+	}
+}
+
+// diffView returns the text widget driving the diff scroll position.
+func (a *Controller) diffView() *TextWidget {
+	if a.state.diff.splitShown {
+		return a.ui.diffLeft
+	}
+	return a.ui.diffDetail
+}
+
+// diffTexts returns every text widget that can display a diff.
+func (a *Controller) diffTexts() []*TextWidget {
+	return []*TextWidget{a.ui.diffDetail, a.ui.diffLeft, a.ui.diffRight}
+}
+
+func (a *Controller) toggleSideBySide() {
+	a.state.diff.sideBySide = !a.state.diff.sideBySide
+	a.updateSideBySideMenuLabel()
+	if !a.state.diff.highlight {
+		return
+	}
+	selected := a.selectedFileIndex()
+	sections := a.state.diff.sections
+	a.writeDetailText(a.state.diff.content, true)
+	a.setFileSections(sections)
+	if selected > 0 && selected < len(a.state.diff.fileSections) {
+		a.setFileListSelection(selected)
+		a.state.diff.skipNextSync = true
+		a.scrollDiffToLine(a.state.diff.fileSections[selected].Line)
+	}
 }
 
 func (a *Controller) copyDetailSelection(stripMarkers bool) {
-	text, err := tkutil.Eval("%s get sel.first sel.last", a.ui.diffDetail)
-	if err != nil || text == "" {
+	var text string
+	for _, widget := range a.diffTexts() {
+		if sel := tkutil.EvalOrEmpty("%s tag ranges sel", widget); sel == "" {
+			continue
+		}
+		text = tkutil.EvalOrEmpty("%s get sel.first sel.last", widget)
+		break
+	}
+	if text == "" {
 		return
 	}
-	if stripMarkers {
+	// The side-by-side view has no +/- markers to strip.
+	if stripMarkers && !a.state.diff.splitShown {
 		lines := strings.Split(text, "\n")
 		filtered := make([]string, 0, len(lines))
 		for _, line := range lines {
@@ -514,24 +721,27 @@ func (a *Controller) copyDetailSelection(stripMarkers bool) {
 }
 
 func (a *Controller) setFileSections(sections []git.FileSection) {
+	a.state.diff.sections = sections
+	if a.state.diff.splitShown {
+		sections = a.state.diff.split.remapSections(sections)
+	}
 	// Keep a virtual "Commit" row so users can jump back to the header quickly.
 	augmented := make([]git.FileSection, 0, len(sections)+1)
 	augmented = append(augmented, git.FileSection{Path: "Commit", Line: 1})
 	augmented = append(augmented, sections...)
 	a.state.diff.fileSections = augmented
-	a.ui.diffFileList.Configure(State("normal"))
-	a.ui.diffFileList.Delete(0, END)
-	if len(augmented) == 0 {
-		a.ui.diffFileList.Insert(END, "(no files)")
-		a.ui.diffFileList.Configure(State("disabled"))
-		return
+	a.clearFileList()
+	for idx, sec := range augmented {
+		values := []string{"", sec.Path, "", ""}
+		if idx > 0 {
+			values = fileRowValues(sec)
+		}
+		a.ui.diffFileList.Insert("", "end", Id(fileRowID(idx)), Values(values))
 	}
-	for _, sec := range augmented {
-		a.ui.diffFileList.Insert(END, sec.Path)
+	a.orderFileRows()
+	if !a.state.browse.active {
+		a.ui.diffFileSummary.Configure(Txt(fileListSummary(sections)))
 	}
-	a.ui.diffFileList.SelectionClear(0, END)
-	a.ui.diffFileList.Activate(0)
-	a.ui.diffFileList.Configure(State("normal"))
 	a.syncFileSelectionToDiff()
 }
 
@@ -542,11 +752,7 @@ func (a *Controller) onFileSelectionChanged() {
 	if len(a.state.diff.fileSections) == 0 {
 		return
 	}
-	selection := a.ui.diffFileList.Curselection()
-	if len(selection) == 0 {
-		return
-	}
-	idx := selection[0]
+	idx := a.selectedFileIndex()
 	if idx < 0 || idx >= len(a.state.diff.fileSections) {
 		return
 	}
@@ -560,7 +766,7 @@ func (a *Controller) scrollDiffToLine(line int) {
 	}
 	totalLines := a.textLineCount()
 	if totalLines <= 1 {
-		a.ui.diffDetail.Yviewmoveto(0)
+		a.diffView().Yviewmoveto(0)
 		return
 	}
 	fraction := float64(line-1) / float64(totalLines-1)
@@ -570,11 +776,11 @@ func (a *Controller) scrollDiffToLine(line int) {
 	if fraction > 1 {
 		fraction = 1
 	}
-	a.ui.diffDetail.Yviewmoveto(fraction)
+	a.diffView().Yviewmoveto(fraction)
 }
 
 func (a *Controller) textLineCount() int {
-	index := a.ui.diffDetail.Index(END)
+	index := a.diffView().Index(END)
 	parts := strings.SplitN(index, ".", 2)
 	if len(parts) == 0 {
 		return 0
@@ -603,9 +809,12 @@ func (a *Controller) setStatus(msg string) {
 func (a *Controller) statusSummary() string {
 	total := len(a.data.commits)
 	visible := len(a.data.visible)
-	head := a.repo.headRef
-	if head == "" {
-		head = "HEAD"
+	head := "HEAD"
+	if a.repo.headRef != "" {
+		head = headDisplayName(a.repo.headRef)
+	}
+	if spec := a.cfg.logSpec.String(); spec != "" {
+		head = spec
 	}
 	filterDesc := strings.TrimSpace(a.state.filter.value)
 	path := a.repo.path
@@ -616,9 +825,18 @@ func (a *Controller) statusSummary() string {
 	if a.state.tree.hasMore {
 		base += " (more available)"
 	}
+	if search := a.searchSummary(); search != "" {
+		return fmt.Sprintf("%s — %s", search, base)
+	}
 	if filterDesc == "" {
 		return base
 	}
+	if err := a.state.filter.err; err != nil {
+		return fmt.Sprintf("Invalid filter: %v — %s", err, base)
+	}
+	if err := a.state.filter.searchErr; err != nil {
+		return fmt.Sprintf("Filter search failed: %v — %s", err, base)
+	}
 	return fmt.Sprintf("Filter %q — %s", filterDesc, base)
 }
 
@@ -630,7 +848,7 @@ func (a *Controller) syncFileSelectionToDiff() {
 		return
 	}
 	line := func() int {
-		index := a.ui.diffDetail.Index("@0,0")
+		index := a.diffView().Index("@0,0")
 		parts := strings.SplitN(index, ".", 2)
 		if len(parts) == 0 {
 			return 0
@@ -651,15 +869,14 @@ func (a *Controller) setFileListSelection(idx int) {
 	if idx < 0 || idx >= len(a.state.diff.fileSections) {
 		return
 	}
-	current := a.ui.diffFileList.Curselection()
-	if len(current) > 0 && current[0] == idx {
+	if a.selectedFileIndex() == idx {
 		return
 	}
 	a.state.diff.suppressFileSelection = true
-	a.ui.diffFileList.SelectionClear(0, END)
-	a.ui.diffFileList.SelectionSet(idx)
-	a.ui.diffFileList.Activate(idx)
-	a.ui.diffFileList.See(idx)
+	id := fileRowID(idx)
+	a.ui.diffFileList.Selection("set", id)
+	a.ui.diffFileList.Focus(id)
+	a.ui.diffFileList.See(id)
 	PostEvent(func() {
 		a.state.diff.suppressFileSelection = false
 	}, false)
@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -34,6 +35,11 @@ func TestCommitListColumns(t *testing.T) {
 	if when != "2025-01-02 09:30" {
 		t.Fatalf("unexpected date column: %q", when)
 	}
+
+	commit.Path = "old name.go"
+	if msg, _, _ := commitListColumns(entry); msg != "abcdef1  Subject line  (old name.go)" {
+		t.Fatalf("expected the followed file name, got %q", msg)
+	}
 }
 
 func TestFormatGraphValue(t *testing.T) {
@@ -56,11 +62,11 @@ func TestFilterEntries(t *testing.T) {
 		{SearchText: "hello world"},
 		{SearchText: "feature branch"},
 	}
-	filtered := filterEntries(entries, "HELLO")
+	filtered := filterEntries(entries, mustParseFilterQuery(t, "HELLO"), nil, nil)
 	if len(filtered) != 1 || filtered[0] != entries[0] {
 		t.Fatalf("expected first entry match, got %#v", filtered)
 	}
-	filtered = filterEntries(entries, " ")
+	filtered = filterEntries(entries, mustParseFilterQuery(t, " "), nil, nil)
 	if len(filtered) != len(entries) {
 		t.Fatalf("expected no filtering on blank query")
 	}
@@ -97,6 +103,21 @@ func TestStatusSummary(t *testing.T) {
 	}
 }
 
+func TestStatusSummaryFilterSearchFailed(t *testing.T) {
+	ctrl := &Controller{
+		repo: controllerRepo{path: "/repo/path", headRef: "main"},
+		state: controllerState{
+			filter: filterState{
+				value:     "path:docs",
+				searchErr: errors.New("path:docs: boom"),
+			},
+		},
+	}
+	if summary := ctrl.statusSummary(); !strings.HasPrefix(summary, "Filter search failed: path:docs: boom") {
+		t.Fatalf("unexpected summary: %s", summary)
+	}
+}
+
 func TestBuildTreeRows(t *testing.T) {
 	now := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
 	entry1 := &git.Entry{
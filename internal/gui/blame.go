@@ -0,0 +1,361 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+// blameAuthorWidth is how many characters of the author name the blame
+// annotation shows.
+const blameAuthorWidth = 14
+
+// blameAnnotationWidth is the width of "hash author date " before each line.
+const blameAnnotationWidth = 7 + 1 + blameAuthorWidth + 1 + 10 + 1
+
+// blameAgeLimits split commit ages into the buckets colored by the blame
+// view, newest first. Older commits fall into a last bucket.
+var blameAgeLimits = []time.Duration{
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+	90 * 24 * time.Hour,
+	180 * 24 * time.Hour,
+	365 * 24 * time.Hour,
+	2 * 365 * 24 * time.Hour,
+	5 * 365 * 24 * time.Hour,
+}
+
+// blameAnnotation formats the hash, author and date of chunk to
+// blameAnnotationWidth characters.
+func blameAnnotation(chunk *git.BlameChunk) string {
+	author := []rune(chunk.Author.Name)
+	if len(author) > blameAuthorWidth {
+		author = append(author[:blameAuthorWidth-1], '…')
+	}
+	date := strings.Repeat(" ", 10)
+	if !chunk.Author.When.IsZero() {
+		date = chunk.Author.When.Format(time.DateOnly)
+	}
+	return fmt.Sprintf("%-7.7s %-*s %s ", chunk.Hash, blameAuthorWidth, string(author), date)
+}
+
+// blameAgeBucket returns the index in blameAgeLimits of the first limit
+// above the age of when, or len(blameAgeLimits) when it is older.
+func blameAgeBucket(when, now time.Time) int {
+	age := now.Sub(when)
+	for i, limit := range blameAgeLimits {
+		if age < limit {
+			return i
+		}
+	}
+	return len(blameAgeLimits)
+}
+
+// blendColor mixes the "#rrggbb" colors from and to, t ranging from 0 (from)
+// to 1 (to).
+func blendColor(from, to string, t float64) string {
+	parse := func(c string) (rgb [3]int) {
+		if len(c) != 7 || c[0] != '#' {
+			return rgb
+		}
+		for i := range rgb {
+			n, _ := strconv.ParseUint(c[1+2*i:3+2*i], 16, 8)
+			rgb[i] = int(n)
+		}
+		return rgb
+	}
+	a, b := parse(from), parse(to)
+	var out [3]int
+	for i := range out {
+		out[i] = a[i] + int(float64(b[i]-a[i])*t+0.5)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", out[0], out[1], out[2])
+}
+
+func blameAgeTag(bucket int) string {
+	return "blameAge" + strconv.Itoa(bucket)
+}
+
+// blameTarget is a file at a commit. The zero value blames nothing.
+type blameTarget struct {
+	commit string
+	path   string
+}
+
+// diffBlameTargets returns the versions of the file of sec in the diff of
+// commit shown in mode that can be blamed: before the change, in the parent,
+// and after it, in commit.
+func diffBlameTargets(commit *git.Commit, mode git.MergeDiffMode, sec git.FileSection) (parent, self blameTarget) {
+	if commit == nil || sec.Path == "" {
+		return parent, self
+	}
+	if sec.Status != "D" {
+		self = blameTarget{commit: commit.Hash, path: sec.Path}
+	}
+	// The combined diff blames the first parent.
+	n := max(mode.Parent(), 1)
+	if sec.Status != "A" && n <= len(commit.ParentHashes) {
+		path := sec.Path
+		if sec.OldPath != "" {
+			path = sec.OldPath
+		}
+		parent = blameTarget{commit: commit.ParentHashes[n-1], path: path}
+	}
+	return parent, self
+}
+
+// updateDiffFileItems enables the blame and history entries of the diff
+// context menu for the file under the pointer in text.
+func (a *Controller) updateDiffFileItems(text *TextWidget, e *Event) {
+	diff := &a.state.diff
+	diff.blameParent, diff.blameCommit = blameTarget{}, blameTarget{}
+	diff.historyPath = ""
+	var commit *git.Commit
+	if idx := a.state.selection.CommitIndex(a.data.visible); idx >= 0 && a.data.visible[idx] != nil {
+		commit = a.data.visible[idx].Commit
+	}
+	var line int
+	if _, err := fmt.Sscanf(text.Index(fmt.Sprintf("@%d,%d", e.X, e.Y)), "%d.", &line); err == nil && a.state.browse.shown == "" {
+		if idx := fileSectionIndexForLine(diff.fileSections, line); idx > 0 {
+			diff.blameParent, diff.blameCommit = diffBlameTargets(commit, diff.mergeMode, diff.fileSections[idx])
+			diff.historyPath = diff.fileSections[idx].Path
+		}
+	}
+	state := func(enabled bool) string {
+		if !enabled {
+			return "disabled"
+		}
+		return "normal"
+	}
+	menu := a.ui.diffContextMenu
+	menu.EntryConfigure(a.ui.diffMenuItems["blameParent"], State(state(diff.blameParent.commit != "")))
+	menu.EntryConfigure(a.ui.diffMenuItems["blameCommit"], State(state(diff.blameCommit.commit != "")))
+	menu.EntryConfigure(a.ui.diffMenuItems["history"], State(state(diff.historyPath != "")))
+}
+
+func (a *Controller) blameDiffContextFile(parent bool) {
+	target := a.state.diff.blameCommit
+	if parent {
+		target = a.state.diff.blameParent
+	}
+	a.openBlame(target.commit, target.path)
+}
+
+// blameView is an open blame window.
+type blameView struct {
+	text   *TextWidget
+	status *TLabelWidget
+	// hashes holds the commit blamed for each line, indexed from 0.
+	hashes  []string
+	blamed  int
+	closed  bool
+	started time.Time
+}
+
+// openBlame shows which commit last changed each line of the file at path
+// in commit, in a new window.
+func (a *Controller) openBlame(commit, path string) {
+	if a.svc == nil || commit == "" || path == "" {
+		return
+	}
+	window := App.Toplevel()
+	window.WmTitle(fmt.Sprintf("Blame: %s at %s", path, shortHash(commit)))
+	GridRowConfigure(window.Window, 0, Weight(1))
+	GridColumnConfigure(window.Window, 0, Weight(1))
+	view := &blameView{started: time.Now()}
+	yScroll := window.TScrollbar()
+	xScroll := window.TScrollbar(Orient(HORIZONTAL))
+	view.text = window.Text(
+		Wrap(NONE),
+		Font(CourierFont(), 11),
+		Tabs("1c"),
+		Width(120),
+		Height(40),
+		Yscrollcommand(func(e *Event) { e.ScrollSet(yScroll) }),
+		Xscrollcommand(func(e *Event) { e.ScrollSet(xScroll) }),
+	)
+	yScroll.Configure(Command(func(e *Event) { e.Yview(view.text) }))
+	xScroll.Configure(Command(func(e *Event) { e.Xview(view.text) }))
+	view.status = window.TLabel(Anchor(W), Txt(fmt.Sprintf("Loading %s...", path)))
+	Grid(view.text, Row(0), Column(0), Sticky(NEWS))
+	Grid(yScroll, Row(0), Column(1), Sticky(NS))
+	Grid(xScroll, Row(1), Column(0), Sticky(WE))
+	Grid(view.status, Row(2), Column(0), Columnspan(2), Sticky(WE), Padx("4p"), Pady("2p"))
+	a.configureBlameTags(view.text)
+	view.text.Configure(State("disabled"))
+	Bind(view.text, "<ButtonRelease-1>", Command(func(e *Event) {
+		if hash := view.hashAt(e); hash != "" {
+			a.jumpToCommit(hash, shortHash(hash))
+		}
+	}))
+	Bind(window.Window, "<KeyPress-Escape>", Command(func() { Destroy(window.Window) }))
+
+	svc := a.svc
+	streams := make(chan git.BlameStream, 1)
+	// <Destroy> also fires for every child of the window.
+	Bind(window.Window, "<Destroy>", Command(func() {
+		if view.closed {
+			return
+		}
+		view.closed = true
+		// Closing the stream stops the blame still running in the background.
+		go func() {
+			if stream := <-streams; stream != nil {
+				_ = stream.Close()
+			}
+		}()
+	}))
+
+	go func() {
+		data, err := svc.Blob(commit, path)
+		if err != nil {
+			streams <- nil
+			PostEvent(func() { view.setStatus(fmt.Sprintf("Unable to read %s: %v", path, err)) }, false)
+			return
+		}
+		if isBinaryContent(data) {
+			streams <- nil
+			PostEvent(func() { view.setStatus(fmt.Sprintf("%s is a binary file.", path)) }, false)
+			return
+		}
+		stream, err := svc.Blame(commit, path)
+		streams <- stream
+		PostEvent(func() { a.writeBlameText(view, path, string(data)) }, false)
+		if err != nil {
+			PostEvent(func() { view.setStatus(fmt.Sprintf("Unable to blame %s: %v", path, err)) }, false)
+			return
+		}
+		for {
+			chunk, err := stream.Next()
+			if err != nil {
+				PostEvent(func() { view.finish(err) }, false)
+				return
+			}
+			PostEvent(func() { a.applyBlameChunk(view, chunk) }, false)
+		}
+	}()
+}
+
+func (a *Controller) configureBlameTags(text *TextWidget) {
+	palette := a.theme.palette
+	newest, oldest := palette.BlameNew, palette.BlameOld
+	if newest == "" || oldest == "" {
+		newest, oldest = lightPalette.BlameNew, lightPalette.BlameOld
+	}
+	for bucket := range len(blameAgeLimits) + 1 {
+		t := float64(bucket) / float64(len(blameAgeLimits))
+		text.TagConfigure(blameAgeTag(bucket), Background(blendColor(newest, oldest, t)))
+	}
+	lineNumbers := palette.LineNumber
+	if lineNumbers == "" {
+		lineNumbers = lightPalette.LineNumber
+	}
+	text.TagConfigure(lineNumberTag, Foreground(lineNumbers))
+}
+
+// writeBlameText fills the blame window with the file, leaving room for the
+// annotations.
+func (a *Controller) writeBlameText(view *blameView, path, content string) {
+	if view.closed {
+		return
+	}
+	numbered, gutter := numberLines(content)
+	lines := strings.Split(numbered, "\n")
+	view.hashes = make([]string, len(lines))
+	pad := strings.Repeat(" ", blameAnnotationWidth)
+	for i := range lines {
+		lines[i] = pad + lines[i]
+	}
+	text := view.text
+	text.Configure(State(NORMAL))
+	text.Insert("1.0", strings.Join(lines, "\n"))
+	for i := range lines {
+		text.TagAdd(lineNumberTag, textIndex(i, blameAnnotationWidth), textIndex(i, blameAnnotationWidth+gutter))
+	}
+	if a.cfg.syntaxHighlight {
+		style := styleForPalette(a.theme.palette)
+		lexer := lexerForPath(path)
+		for i, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+			a.highlightCodeLine(text, lexer, style, line, i+1, blameAnnotationWidth+gutter)
+		}
+		// The syntax tags are configured on the diff views only.
+		for color, tag := range a.state.diff.syntaxTags {
+			text.TagConfigure(tag, Foreground(color))
+		}
+	}
+	text.Configure(State("disabled"))
+	view.setStatus(fmt.Sprintf("Blaming %d lines...", len(lines)))
+}
+
+// applyBlameChunk annotates the lines of chunk.
+func (a *Controller) applyBlameChunk(view *blameView, chunk *git.BlameChunk) {
+	if view.closed || view.hashes == nil {
+		return
+	}
+	annotation := blameAnnotation(chunk)
+	tag := blameAgeTag(blameAgeBucket(chunk.Author.When, view.started))
+	width := utf8.RuneCountInString(annotation)
+	text := view.text
+	text.Configure(State(NORMAL))
+	for line := chunk.FinalLine - 1; line < chunk.FinalLine-1+chunk.Lines && line < len(view.hashes); line++ {
+		if line < 0 {
+			continue
+		}
+		if view.hashes[line] == "" {
+			view.blamed++
+		}
+		view.hashes[line] = chunk.Hash
+		text.Delete(textIndex(line, 0), textIndex(line, blameAnnotationWidth))
+		text.Insert(textIndex(line, 0), annotation)
+		text.TagAdd(tag, textIndex(line, 0), textIndex(line, width-1))
+	}
+	text.Configure(State("disabled"))
+	view.setStatus(fmt.Sprintf("Blamed %d of %d lines...", view.blamed, len(view.hashes)))
+}
+
+// finish reports the end of the blame stream, err being io.EOF when it
+// completed.
+func (view *blameView) finish(err error) {
+	if view.closed {
+		return
+	}
+	if !errors.Is(err, io.EOF) {
+		view.setStatus(fmt.Sprintf("Blame failed: %v", err))
+		return
+	}
+	view.setStatus(fmt.Sprintf("Blamed %d lines. Click an annotation to select its commit.", len(view.hashes)))
+}
+
+func (view *blameView) setStatus(msg string) {
+	if !view.closed {
+		view.status.Configure(Txt(msg))
+	}
+}
+
+// hashAt returns the commit annotating the line clicked in the blame view,
+// when the click is on the annotation.
+func (view *blameView) hashAt(e *Event) string {
+	if e == nil || strings.TrimSpace(tkutil.EvalOrEmpty("%s tag ranges sel", view.text)) != "" {
+		return ""
+	}
+	var line, col int
+	if _, err := fmt.Sscanf(view.text.Index(fmt.Sprintf("@%d,%d", e.X, e.Y)), "%d.%d", &line, &col); err != nil {
+		slog.Debug("blame index", slog.Any("error", err))
+		return ""
+	}
+	if line < 1 || line > len(view.hashes) || col >= blameAnnotationWidth-1 {
+		return ""
+	}
+	return view.hashes[line-1]
+}
@@ -0,0 +1,107 @@
+package gui
+
+import (
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestBlameAnnotation(t *testing.T) {
+	when := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		chunk git.BlameChunk
+		want  string
+	}{
+		{
+			git.BlameChunk{Hash: "0123456789abcdef", Author: git.Signature{Name: "Alice", When: when}},
+			"0123456 Alice          2024-03-05 ",
+		},
+		{
+			git.BlameChunk{Hash: "0123456789abcdef", Author: git.Signature{Name: "Bartholomew Longname", When: when}},
+			"0123456 Bartholomew L… 2024-03-05 ",
+		},
+		{
+			git.BlameChunk{Hash: "0123456789abcdef"},
+			"0123456                           ",
+		},
+	}
+	for _, tt := range tests {
+		got := blameAnnotation(&tt.chunk)
+		if got != tt.want {
+			t.Fatalf("blameAnnotation(%+v) = %q, want %q", tt.chunk, got, tt.want)
+		}
+		if n := utf8.RuneCountInString(got); n != blameAnnotationWidth {
+			t.Fatalf("annotation %q is %d characters wide, want %d", got, n, blameAnnotationWidth)
+		}
+	}
+}
+
+func TestBlameAgeBucket(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		age  time.Duration
+		want int
+	}{
+		{time.Hour, 0},
+		{3 * 24 * time.Hour, 1},
+		{100 * 24 * time.Hour, 4},
+		{10 * 365 * 24 * time.Hour, len(blameAgeLimits)},
+	}
+	for _, tt := range tests {
+		if got := blameAgeBucket(now.Add(-tt.age), now); got != tt.want {
+			t.Fatalf("blameAgeBucket(%v old) = %d, want %d", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestBlendColor(t *testing.T) {
+	tests := []struct {
+		t    float64
+		want string
+	}{
+		{0, "#000000"},
+		{0.5, "#80407f"},
+		{1, "#ff80fe"},
+	}
+	for _, tt := range tests {
+		if got := blendColor("#000000", "#ff80fe", tt.t); got != tt.want {
+			t.Fatalf("blendColor(%v) = %s, want %s", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestDiffBlameTargets(t *testing.T) {
+	commit := &git.Commit{Hash: "c", ParentHashes: []string{"p1", "p2"}}
+	tests := []struct {
+		name         string
+		mode         git.MergeDiffMode
+		sec          git.FileSection
+		parent, self blameTarget
+	}{
+		{"modified", git.MergeDiffFirstParent, git.FileSection{Path: "a.go", Status: "M"},
+			blameTarget{"p1", "a.go"}, blameTarget{"c", "a.go"}},
+		{"added", git.MergeDiffFirstParent, git.FileSection{Path: "a.go", Status: "A"},
+			blameTarget{}, blameTarget{"c", "a.go"}},
+		{"deleted", git.MergeDiffFirstParent, git.FileSection{Path: "a.go", Status: "D"},
+			blameTarget{"p1", "a.go"}, blameTarget{}},
+		{"renamed", git.MergeDiffFirstParent, git.FileSection{Path: "new.go", OldPath: "old.go", Status: "R"},
+			blameTarget{"p1", "old.go"}, blameTarget{"c", "new.go"}},
+		{"second parent", git.MergeDiffParent(2), git.FileSection{Path: "a.go", Status: "M"},
+			blameTarget{"p2", "a.go"}, blameTarget{"c", "a.go"}},
+		{"combined", git.MergeDiffCombined, git.FileSection{Path: "a.go", Status: "M"},
+			blameTarget{"p1", "a.go"}, blameTarget{"c", "a.go"}},
+		{"missing parent", git.MergeDiffParent(3), git.FileSection{Path: "a.go", Status: "M"},
+			blameTarget{}, blameTarget{"c", "a.go"}},
+	}
+	for _, tt := range tests {
+		parent, self := diffBlameTargets(commit, tt.mode, tt.sec)
+		if parent != tt.parent || self != tt.self {
+			t.Fatalf("%s: got (%+v, %+v), want (%+v, %+v)", tt.name, parent, self, tt.parent, tt.self)
+		}
+	}
+	if parent, self := diffBlameTargets(nil, git.MergeDiffFirstParent, git.FileSection{Path: "a.go"}); parent != (blameTarget{}) || self != (blameTarget{}) {
+		t.Fatal("expected no targets without a commit")
+	}
+}
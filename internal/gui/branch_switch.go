@@ -6,7 +6,7 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/thiagokokada/gitk-go/internal/gui/selection"
+	"github.com/thiagokokada/gitk-go/internal/git"
 	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
 	. "modernc.org/tk9.0"
 )
@@ -56,6 +56,16 @@ func buildBranchChoices(branches []string, current string) []branchChoice {
 	return choices
 }
 
+// headDisplayName names the checked out branch head, as reported by
+// LocalBranchNames, spelling out a detached HEAD.
+func headDisplayName(head string) string {
+	head = strings.TrimSpace(head)
+	if head == "" || head == "HEAD" {
+		return "detached HEAD"
+	}
+	return head
+}
+
 func filterBranchChoices(choices []branchChoice, query string) []branchChoice {
 	q := strings.ToLower(strings.TrimSpace(query))
 	if q == "" {
@@ -92,7 +102,7 @@ func (a *Controller) promptBranchSwitch() {
 		)
 		return
 	}
-	if len(branches) == 0 {
+	if len(branches) == 0 && headDisplayName(head) != "detached HEAD" {
 		MessageBox(
 			Parent(App),
 			Title("Switch Branch"),
@@ -125,12 +135,19 @@ func (a *Controller) showBranchSwitchDialog(branches []string, current string) {
 	GridColumnConfigure(frame.Window, 0, Weight(1))
 	GridRowConfigure(frame.Window, 2, Weight(1))
 
-	currentLabel := strings.TrimSpace(current)
-	if currentLabel == "" || currentLabel == "HEAD" {
-		currentLabel = "detached HEAD"
-	}
-	header := frame.TLabel(Txt(fmt.Sprintf("Current: %s", currentLabel)), Anchor(W))
+	currentLabel := headDisplayName(current)
+	header := frame.TFrame()
 	Grid(header, Row(0), Column(0), Sticky(WE), Pady("0 8p"))
+	GridColumnConfigure(header.Window, 0, Weight(1))
+	Grid(header.TLabel(Txt(fmt.Sprintf("Current: %s", currentLabel)), Anchor(W)), Row(0), Column(0), Sticky(WE))
+	if currentLabel == "detached HEAD" {
+		// Commits made on a detached HEAD are lost once another branch is
+		// checked out, unless a branch keeps them.
+		Grid(header.TButton(Txt("Create Branch from HEAD..."), Command(func() {
+			Destroy(dialog.Window)
+			a.promptCreateBranch("HEAD", true)
+		})), Row(0), Column(1), Sticky(E))
+	}
 
 	filter := frame.TEntry(Width(48), Textvariable(""))
 	Grid(filter, Row(1), Column(0), Sticky(WE), Pady("0 8p"))
@@ -219,6 +236,36 @@ func (a *Controller) applyBranchChoiceSelection(dialog *ToplevelWidget, list *Li
 	a.switchBranchAsync(branch)
 }
 
+// checkoutDetachedAsync checks out the commit named by rev, detaching HEAD.
+// label names it in messages.
+func (a *Controller) checkoutDetachedAsync(rev, label string) {
+	rev = strings.TrimSpace(rev)
+	if a.svc == nil || rev == "" {
+		return
+	}
+	a.setStatus(fmt.Sprintf("Checking out %s...", label))
+	done := fmt.Sprintf("Checked out %s, HEAD is now detached.", label)
+	a.runRefAction("Check Out", done, "Unable to check out", true, func(svc *git.Service) error {
+		return svc.SwitchDetached(rev)
+	})
+}
+
+// promptTrackingBranch asks for the name of a local branch tracking the
+// remote branch remote, such as "origin/main", to create and check out.
+func (a *Controller) promptTrackingBranch(remote string) {
+	if a.svc == nil || remote == "" {
+		return
+	}
+	_, branch, _ := strings.Cut(remote, "/")
+	d := a.newRefDialog("Check Out Remote Branch", fmt.Sprintf("New local branch tracking %s:", remote), branch)
+	d.finish("Check Out", func(name string) {
+		done := fmt.Sprintf("Switched to %s, tracking %s.", name, remote)
+		a.runRefAction("Check Out Remote Branch", done, "Unable to create the tracking branch", true, func(svc *git.Service) error {
+			return svc.CreateTrackingBranch(name, remote)
+		})
+	})
+}
+
 func (a *Controller) switchBranchAsync(branch string) {
 	if a.svc == nil {
 		return
@@ -243,27 +290,7 @@ func (a *Controller) switchBranchAsync(branch string) {
 				return
 			}
 
-			a.cancelPendingDiffLoad()
-			a.repo.headRef = ""
-			a.data.commits = nil
-			a.data.visible = nil
-			a.state.tree = treeState{}
-			a.state.localDiff = localDiffCache{}
-			a.state.selection = selection.State{}
-
-			if a.ui.diffFileList != nil {
-				a.ui.diffFileList.Delete(0, END)
-			}
-			a.setFileSections(nil)
-			a.setLocalRowVisibility(false, false)
-			a.setLocalRowVisibility(true, false)
-
-			a.clearTreeRows()
-			a.clearDetailText("Select a commit to view its details.")
-			a.showInitialLoadingRow()
-			a.setStatus("Loading commits...")
-			a.refreshLocalChangesAsync(true)
-			a.reloadCommitsAsync()
+			a.restartCommitList("Loading commits...")
 		}, false)
 	}()
 }
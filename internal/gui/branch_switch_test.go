@@ -33,3 +33,16 @@ func TestFilterBranchChoices_CaseInsensitiveSubstring(t *testing.T) {
 		t.Fatalf("filter result = %#v, want bugfix/Crash", got)
 	}
 }
+
+func TestHeadDisplayName(t *testing.T) {
+	for head, want := range map[string]string{
+		"main":   "main",
+		" main ": "main",
+		"HEAD":   "detached HEAD",
+		"":       "detached HEAD",
+	} {
+		if got := headDisplayName(head); got != want {
+			t.Fatalf("headDisplayName(%q) = %q, want %q", head, got, want)
+		}
+	}
+}
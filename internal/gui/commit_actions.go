@@ -0,0 +1,130 @@
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+
+	. "modernc.org/tk9.0"
+)
+
+// mainlineParent is the parent a merge commit is cherry-picked and reverted
+// against, or 0 for other commits.
+func mainlineParent(commit *git.Commit) int {
+	if commit != nil && len(commit.ParentHashes) > 1 {
+		return 1
+	}
+	return 0
+}
+
+// commitLabel names commit in dialogs by its short hash and subject.
+func commitLabel(commit *git.Commit) string {
+	subject := strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0]
+	if subject == "" {
+		return shortHash(commit.Hash)
+	}
+	return fmt.Sprintf("%s \"%s\"", shortHash(commit.Hash), subject)
+}
+
+// resetMessage asks for confirmation before a reset of the current branch
+// to target, explaining what mode keeps.
+func resetMessage(mode git.ResetMode, target string) string {
+	msg := fmt.Sprintf("Reset the current branch to %s?\n\n", target)
+	switch mode {
+	case git.ResetSoft:
+		return msg + "The index and the working tree are kept, with the changes of the later commits staged."
+	case git.ResetMixed:
+		return msg + "The working tree is kept, with the changes of the later commits unstaged."
+	default:
+		return msg + "The index and the working tree are reset, discarding the changes of the later commits."
+	}
+}
+
+// localChangesWarning describes the local changes a hard reset would
+// discard, or returns "" when there are none. statusErr is the error of
+// reading them, which is treated as unknown changes.
+func localChangesWarning(changes git.LocalChanges, statusErr error) string {
+	switch {
+	case statusErr != nil:
+		return fmt.Sprintf("Unable to check for uncommitted changes: %v\n\nReset anyway, discarding any of them?", statusErr)
+	case len(changes.Conflicts) > 0:
+		return "There are unresolved conflicts, which a hard reset discards.\n\nReset anyway?"
+	case changes.HasStaged || changes.HasWorktree:
+		return "There are uncommitted changes, which a hard reset discards and cannot be undone.\n\nReset anyway?"
+	}
+	return ""
+}
+
+func (a *Controller) cherryPickContextCommit() {
+	commit := a.contextCommit()
+	if a.svc == nil || commit == nil {
+		return
+	}
+	label := commitLabel(commit)
+	if !a.confirm("Cherry-pick", fmt.Sprintf("Cherry-pick %s onto HEAD?", label)) {
+		return
+	}
+	hash, mainline := commit.Hash, mainlineParent(commit)
+	a.runRefAction("Cherry-pick", fmt.Sprintf("Cherry-picked %s.", shortHash(hash)), "Unable to cherry-pick the commit", true, func(svc *git.Service) error {
+		return svc.CherryPick(hash, mainline)
+	})
+}
+
+func (a *Controller) revertContextCommit() {
+	commit := a.contextCommit()
+	if a.svc == nil || commit == nil {
+		return
+	}
+	label := commitLabel(commit)
+	if !a.confirm("Revert", fmt.Sprintf("Commit the reverse of %s on top of HEAD?", label)) {
+		return
+	}
+	hash, mainline := commit.Hash, mainlineParent(commit)
+	a.runRefAction("Revert", fmt.Sprintf("Reverted %s.", shortHash(hash)), "Unable to revert the commit", true, func(svc *git.Service) error {
+		return svc.Revert(hash, mainline)
+	})
+}
+
+// resetToContextCommit resets the current branch to the commit under the
+// tree context menu. A hard reset asks again when it would discard local
+// changes.
+func (a *Controller) resetToContextCommit(mode git.ResetMode) {
+	commit := a.contextCommit()
+	if a.svc == nil || commit == nil {
+		return
+	}
+	hash, label := commit.Hash, commitLabel(commit)
+	svc := a.svc
+	go func() {
+		var (
+			changes git.LocalChanges
+			err     error
+		)
+		if mode == git.ResetHard {
+			changes, err = svc.LocalChanges()
+		}
+		PostEvent(func() {
+			if svc != a.svc {
+				return
+			}
+			title := fmt.Sprintf("Reset (%s)", mode)
+			if !a.confirm(title, resetMessage(mode, label)) {
+				return
+			}
+			if warning := localChangesWarning(changes, err); warning != "" {
+				if err != nil {
+					slog.Error("local changes before reset", slog.Any("error", err))
+				}
+				if !a.confirm(title, warning) {
+					return
+				}
+			}
+			done := fmt.Sprintf("Reset the current branch to %s (%s).", shortHash(hash), mode)
+			a.runRefAction(title, done, "Unable to reset", true, func(svc *git.Service) error {
+				return svc.Reset(hash, mode)
+			})
+		}, false)
+	}()
+}
@@ -0,0 +1,77 @@
+package gui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestMainlineParent(t *testing.T) {
+	if got := mainlineParent(&git.Commit{ParentHashes: []string{"a"}}); got != 0 {
+		t.Fatalf("mainlineParent(commit) = %d, want 0", got)
+	}
+	if got := mainlineParent(&git.Commit{ParentHashes: []string{"a", "b"}}); got != 1 {
+		t.Fatalf("mainlineParent(merge) = %d, want 1", got)
+	}
+}
+
+func TestCommitLabel(t *testing.T) {
+	commit := &git.Commit{Hash: "0123456789abcdef", Message: "Fix the parser\n\nDetails."}
+	if got, want := commitLabel(commit), `0123456 "Fix the parser"`; got != want {
+		t.Fatalf("commitLabel() = %q, want %q", got, want)
+	}
+	if got := commitLabel(&git.Commit{Hash: "0123456789abcdef"}); got != "0123456" {
+		t.Fatalf("commitLabel() without message = %q", got)
+	}
+}
+
+func TestResetMessage(t *testing.T) {
+	for mode, want := range map[git.ResetMode]string{
+		git.ResetSoft:  "changes of the later commits staged",
+		git.ResetMixed: "later commits unstaged",
+		git.ResetHard:  "discarding the changes",
+	} {
+		got := resetMessage(mode, "0123456")
+		if !strings.HasPrefix(got, "Reset the current branch to 0123456?") || !strings.Contains(got, want) {
+			t.Fatalf("resetMessage(%v) = %q, want it to mention %q", mode, got, want)
+		}
+	}
+}
+
+func TestLocalChangesWarning(t *testing.T) {
+	if got := localChangesWarning(git.LocalChanges{}, nil); got != "" {
+		t.Fatalf("expected no warning for a clean tree, got %q", got)
+	}
+	for _, tt := range []struct {
+		changes git.LocalChanges
+		err     error
+		want    string
+	}{
+		{changes: git.LocalChanges{HasWorktree: true}, want: "uncommitted changes"},
+		{changes: git.LocalChanges{HasStaged: true}, want: "uncommitted changes"},
+		{changes: git.LocalChanges{HasWorktree: true, HasStaged: true, Conflicts: []string{"a.txt"}}, want: "unresolved conflicts"},
+		{err: errors.New("boom"), want: "boom"},
+	} {
+		if got := localChangesWarning(tt.changes, tt.err); !strings.Contains(got, tt.want) {
+			t.Fatalf("localChangesWarning(%+v, %v) = %q, want it to mention %q", tt.changes, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestLocalRowLabel(t *testing.T) {
+	if got := localRowLabel(false, nil); got != localUnstagedLabel {
+		t.Fatalf("localRowLabel(unstaged) = %q", got)
+	}
+	if got := localRowLabel(true, []string{"a.txt"}); got != localStagedLabel {
+		t.Fatalf("expected the staged row to ignore conflicts, got %q", got)
+	}
+	if got, want := localRowLabel(false, []string{"a.txt", "b.txt"}), localUnstagedLabel+" (conflicts in a.txt, b.txt)"; got != want {
+		t.Fatalf("localRowLabel() = %q, want %q", got, want)
+	}
+	got := localRowLabel(false, []string{"a", "b", "c", "d", "e"})
+	if want := localUnstagedLabel + " (conflicts in a, b, c and 2 more)"; got != want {
+		t.Fatalf("localRowLabel() = %q, want %q", got, want)
+	}
+}
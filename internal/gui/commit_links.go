@@ -0,0 +1,87 @@
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+const commitLinkTag = "commitLink"
+
+// commitRelations returns the parents, children, branches and nearest tags of
+// c among the loaded commits.
+func (a *Controller) commitRelations(c *git.Commit) *git.CommitRelations {
+	if a.data.index == nil {
+		return nil
+	}
+	return a.data.index.Relations(c, a.state.refs.refs)
+}
+
+// bindCommitLinks styles the parent and child hashes of the commit header in
+// text as links that select the commit they name.
+func (a *Controller) bindCommitLinks(text *TextWidget, color string) {
+	text.TagConfigure(commitLinkTag, Foreground(color), Underline(true))
+	tkutil.MustEval(`
+		%[1]s tag bind %[2]s <Enter> {%[1]s configure -cursor hand2}
+		%[1]s tag bind %[2]s <Leave> {%[1]s configure -cursor {}}
+	`, text, commitLinkTag)
+	Bind(text, "<ButtonRelease-1>", Command(func(e *Event) {
+		if hash := a.commitLinkAt(text, e); hash != "" {
+			a.jumpToCommit(hash, shortHash(hash))
+		}
+	}))
+}
+
+// tagCommitLinks marks the links of the rendered content.
+func (a *Controller) tagCommitLinks() {
+	for _, link := range a.state.diff.links {
+		if !a.state.diff.splitShown {
+			a.ui.diffDetail.TagAdd(commitLinkTag, textIndex(link.Line, link.Start), textIndex(link.Line, link.End))
+			continue
+		}
+		row := a.state.diff.split.lineMap[link.Line]
+		for _, text := range []*TextWidget{a.ui.diffLeft, a.ui.diffRight} {
+			text.TagAdd(commitLinkTag, textIndex(row, link.Start), textIndex(row, link.End))
+		}
+	}
+}
+
+// commitLinkAt returns the hash of the link clicked in text, if any. Clicks
+// ending a text selection are ignored.
+func (a *Controller) commitLinkAt(text *TextWidget, e *Event) string {
+	if e == nil || len(a.state.diff.links) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(tkutil.EvalOrEmpty("%s tag ranges sel", text)) != "" {
+		return ""
+	}
+	at := fmt.Sprintf("@%d,%d", e.X, e.Y)
+	if !strings.Contains(" "+tkutil.EvalOrEmpty("%s tag names %s", text, at)+" ", " "+commitLinkTag+" ") {
+		return ""
+	}
+	var line, col int
+	if _, err := fmt.Sscanf(tkutil.EvalOrEmpty("%s index %s", text, at), "%d.%d", &line, &col); err != nil {
+		slog.Debug("commit link index", slog.Any("error", err))
+		return ""
+	}
+	for _, link := range a.state.diff.links {
+		row := link.Line
+		if a.state.diff.splitShown {
+			row = a.state.diff.split.lineMap[link.Line]
+		}
+		if row+1 == line && col >= link.Start && col < link.End {
+			return link.Hash
+		}
+	}
+	return ""
+}
+
+// textIndex converts a 0-based line and a column to a Tk text index.
+func textIndex(line, col int) string {
+	return fmt.Sprintf("%d.%d", line+1, col)
+}
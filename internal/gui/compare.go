@@ -0,0 +1,132 @@
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+// markedRowTag highlights the marked commit in the commit list.
+const markedRowTag = "markedRow"
+
+// showCompareDiff shows the changes from commit from to commit to in the diff
+// view, clearing the commit selection. The labels name them in messages.
+func (a *Controller) showCompareDiff(from, to, fromLabel, toLabel string) {
+	if a.svc == nil || from == "" || to == "" {
+		return
+	}
+	svc := a.svc
+	a.cancelPendingDiffLoad()
+	a.updateMergeDiffSelector(nil)
+	a.browseCommit("")
+	for _, id := range a.ui.treeView.Selection("") {
+		a.ui.treeView.Selection("remove", id)
+	}
+	a.state.selection.Clear()
+	a.state.diff.compareGen++
+	a.state.diff.compare = compareTarget{from: from, to: to, fromLabel: fromLabel, toLabel: toLabel}
+	gen := a.state.diff.compareGen
+	opts := a.state.diff.options
+	a.clearDetailText(fmt.Sprintf("Comparing %s with %s...", fromLabel, toLabel))
+	go func() {
+		diff, sections, err := svc.CompareDiff(from, to, opts)
+		if err != nil {
+			diff = fmt.Sprintf("Unable to compare %s with %s: %v", fromLabel, toLabel, err)
+		}
+		diff, sections = prepareDiffDisplay(diff, sections)
+		PostEvent(func() {
+			if svc != a.svc || gen != a.state.diff.compareGen || a.currentSelection() != "" {
+				return
+			}
+			a.writeDetailText(diff, len(sections) > 0)
+			a.setFileSections(sections)
+			a.setStatus(fmt.Sprintf("Showing changes from %s to %s.", fromLabel, toLabel))
+		}, false)
+	}()
+}
+
+// markContextCommit marks the commit under the tree context menu, so that
+// other commits can be diffed against it.
+func (a *Controller) markContextCommit() {
+	commit := a.contextCommit()
+	if commit == nil {
+		return
+	}
+	a.setMarkedCommit(commit.Hash)
+	a.setStatus(fmt.Sprintf("Marked %s.", shortHash(commit.Hash)))
+}
+
+func (a *Controller) clearMarkedCommit() {
+	if a.state.tree.marked == "" {
+		return
+	}
+	a.setMarkedCommit("")
+	a.setStatus("Cleared the marked commit.")
+}
+
+func (a *Controller) setMarkedCommit(hash string) {
+	a.state.tree.marked = hash
+	a.tagMarkedRow()
+	a.scheduleGraphCanvasDraw()
+}
+
+// diffContextWithMarked diffs the commit under the tree context menu with
+// the marked one. reverse diffs from the marked commit instead.
+func (a *Controller) diffContextWithMarked(reverse bool) {
+	commit := a.contextCommit()
+	marked := a.state.tree.marked
+	if commit == nil || marked == "" {
+		return
+	}
+	from, to := commit.Hash, marked
+	fromLabel, toLabel := shortHash(from), "marked "+shortHash(to)
+	if reverse {
+		from, to = to, from
+		fromLabel, toLabel = toLabel, fromLabel
+	}
+	a.showCompareDiff(from, to, fromLabel, toLabel)
+}
+
+// updateMarkMenuItems enables the diff entries of the tree context menu
+// when a commit other than the one under the menu is marked.
+func (a *Controller) updateMarkMenuItems() {
+	commit := a.contextCommit()
+	marked := a.state.tree.marked
+	diff := "disabled"
+	if commit != nil && marked != "" && commit.Hash != marked {
+		diff = "normal"
+	}
+	unmark := "disabled"
+	if marked != "" {
+		unmark = "normal"
+	}
+	menu := a.ui.treeContextMenu
+	menu.EntryConfigure(a.ui.treeMenuItems["toMarked"], State(diff))
+	menu.EntryConfigure(a.ui.treeMenuItems["fromMarked"], State(diff))
+	menu.EntryConfigure(a.ui.treeMenuItems["unmark"], State(unmark))
+}
+
+// tagMarkedRow moves the marked row highlight to the row of the marked
+// commit, if it is visible.
+func (a *Controller) tagMarkedRow() {
+	if a.ui.treeView == nil {
+		return
+	}
+	if _, err := tkutil.Eval("%s tag remove %s", a.ui.treeView, markedRowTag); err != nil {
+		slog.Debug("clear marked row", slog.Any("error", err))
+	}
+	if a.state.tree.marked == "" {
+		return
+	}
+	idx := entryIndex(a.data.visible, a.state.tree.marked)
+	if idx < 0 {
+		return
+	}
+	if _, err := tkutil.Eval("%s tag add %s %s", a.ui.treeView, markedRowTag, strconv.Itoa(idx)); err != nil {
+		slog.Debug("tag marked row", slog.Any("error", err))
+	}
+}
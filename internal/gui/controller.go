@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"github.com/thiagokokada/gitk-go/internal/config"
 	"github.com/thiagokokada/gitk-go/internal/git"
 	"github.com/thiagokokada/gitk-go/internal/gui/selection"
 )
@@ -20,10 +21,14 @@ type Controller struct {
 
 type controllerConfig struct {
 	batch               uint
+	logSpec             git.LogSpec
+	backend             git.BackendKind
 	graphCanvas         bool
 	autoReloadRequested bool
 	syntaxHighlight     bool
 	verbose             bool
+	// layout is the window layout saved by the previous session.
+	layout config.Layout
 }
 
 type controllerRepo struct {
@@ -39,14 +44,21 @@ type controllerTheme struct {
 type controllerData struct {
 	commits []*git.Entry
 	visible []*git.Entry
+	// index links the loaded commits to their children.
+	index *git.CommitIndex
 }
 
 type controllerState struct {
-	tree      treeState
-	diff      diffState
-	filter    filterState
-	localDiff localDiffCache
-	scroll    scrollState
-	selection selection.State
-	watch     autoReloadState
+	tree        treeState
+	diff        diffState
+	filter      filterState
+	refs        refsState
+	browse      browseState
+	fileHistory fileHistoryState
+	jump        jumpState
+	localDiff   localDiffCache
+	scroll      scrollState
+	selection   selection.State
+	history     selection.History
+	watch       autoReloadState
 }
@@ -20,13 +20,46 @@ func fileSectionIndexForLine(sections []git.FileSection, line int) int {
 	return target
 }
 
-func diffLineTag(line string) string {
+// isDiffFileHeader reports whether line starts the diff of a file, either in
+// a regular diff or in the combined diff of a merge.
+func isDiffFileHeader(line string) bool {
+	return strings.HasPrefix(line, "diff --git ") ||
+		strings.HasPrefix(line, "diff --cc ") ||
+		strings.HasPrefix(line, "diff --combined ")
+}
+
+// diffHunkColumns returns the number of marker columns of the lines in the
+// hunk started by line: one in regular diffs and one per parent in combined
+// diffs, whose hunk headers have as many "@" as parents plus one.
+func diffHunkColumns(line string) (int, bool) {
+	n := len(line) - len(strings.TrimLeft(line, "@"))
+	if n < 2 {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// diffLineTag returns the tag of a diff line whose hunk has cols marker
+// columns. Lines of combined diffs count as added when added relative to any
+// parent, and as removed when missing from the result.
+func diffLineTag(line string, cols int) string {
+	cols = max(cols, 1)
 	switch {
-	case strings.HasPrefix(line, "diff --git"):
+	case strings.HasPrefix(line, "diff --git"), strings.HasPrefix(line, "diff --cc"), strings.HasPrefix(line, "diff --combined"):
 		return "diffHeader"
-	case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+	case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+		return ""
+	case len(line) < cols:
+		return ""
+	}
+	markers := line[:cols]
+	if strings.Trim(markers, "+- ") != "" {
+		return ""
+	}
+	switch {
+	case strings.Contains(markers, "+"):
 		return "diffAdd"
-	case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
+	case strings.Contains(markers, "-"):
 		return "diffDel"
 	default:
 		return ""
@@ -49,7 +82,7 @@ func prepareDiffDisplay(content string, sections []git.FileSection) (string, []g
 			newSections[nextSection].Line = lineNo + extraLines
 			nextSection++
 		}
-		if strings.HasPrefix(line, "diff --git ") && b.Len() > 0 {
+		if isDiffFileHeader(line) && b.Len() > 0 {
 			b.WriteString("\n")
 			extraLines++
 		}
@@ -66,6 +99,14 @@ func prepareDiffDisplay(content string, sections []git.FileSection) (string, []g
 }
 
 func diffPathFromLine(line string) (string, bool) {
+	for _, prefix := range []string{"diff --cc ", "diff --combined "} {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			if tokens := diffLineTokens(strings.TrimSpace(rest)); len(tokens) > 0 {
+				return tokens[0], true
+			}
+			return "", true
+		}
+	}
 	const prefix = "diff --git "
 	if !strings.HasPrefix(line, prefix) {
 		return "", false
@@ -129,20 +170,18 @@ func normalizeDiffPath(token string) string {
 	return token
 }
 
-func diffLineCode(line string) (string, int, bool) {
-	if line == "" {
+// diffLineCode returns the code of a diff line whose hunk has cols marker
+// columns, along with its offset in line.
+func diffLineCode(line string, cols int) (string, int, bool) {
+	cols = max(cols, 1)
+	if len(line) < cols {
 		return "", 0, false
 	}
-	switch line[0] {
-	case '+', '-', ' ':
-		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
-			return "", 0, false
-		}
-		if strings.HasPrefix(line, "\\ ") {
-			return "", 0, false
-		}
-		return line[1:], 1, true
-	default:
+	if strings.Trim(line[:cols], "+- ") != "" {
+		return "", 0, false
+	}
+	if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
 		return "", 0, false
 	}
+	return line[cols:], cols, true
 }
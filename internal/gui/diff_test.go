@@ -36,6 +36,7 @@ func TestFileSectionIndexForLine(t *testing.T) {
 func TestDiffLineTag(t *testing.T) {
 	tests := []struct {
 		line string
+		cols int
 		want string
 	}{
 		{line: "", want: ""},
@@ -45,10 +46,37 @@ func TestDiffLineTag(t *testing.T) {
 		{line: "-removed", want: "diffDel"},
 		{line: "--- a/file", want: ""},
 		{line: " context", want: ""},
+		{line: "diff --cc file", cols: 2, want: "diffHeader"},
+		{line: "++resolved", cols: 2, want: "diffAdd"},
+		{line: " +from first", cols: 2, want: "diffAdd"},
+		{line: "- ours", cols: 2, want: "diffDel"},
+		{line: "  context", cols: 2, want: ""},
+		{line: "+++ b/file", cols: 2, want: ""},
+		{line: "x-", cols: 2, want: ""},
 	}
 	for _, tc := range tests {
-		if got := diffLineTag(tc.line); got != tc.want {
-			t.Fatalf("line=%q: want %q, got %q", tc.line, tc.want, got)
+		if got := diffLineTag(tc.line, tc.cols); got != tc.want {
+			t.Fatalf("line=%q cols=%d: want %q, got %q", tc.line, tc.cols, tc.want, got)
+		}
+	}
+}
+
+func TestDiffHunkColumns(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   int
+		wantOK bool
+	}{
+		{line: "@@ -1 +1 @@", want: 1, wantOK: true},
+		{line: "@@@ -1 -1 +1 @@@", want: 2, wantOK: true},
+		{line: "@@@@ -1 -1 -1 +1 @@@@", want: 3, wantOK: true},
+		{line: "@ x", wantOK: false},
+		{line: "+@@", wantOK: false},
+	}
+	for _, tc := range tests {
+		got, ok := diffHunkColumns(tc.line)
+		if ok != tc.wantOK || got != tc.want {
+			t.Fatalf("line=%q: want (%d,%v), got (%d,%v)", tc.line, tc.want, tc.wantOK, got, ok)
 		}
 	}
 }
@@ -140,6 +168,7 @@ func TestDiffPathFromLine(t *testing.T) {
 		{line: "diff --git ", want: "", wantOK: true},
 		{line: "diff --git a/foo b/foo", want: "foo", wantOK: true},
 		{line: "diff --git \"a/foo bar\" \"b/foo bar\"", want: "foo bar", wantOK: true},
+		{line: "diff --cc foo", want: "foo", wantOK: true},
 	}
 	for _, tc := range tests {
 		got, ok := diffPathFromLine(tc.line)
@@ -155,6 +184,7 @@ func TestDiffPathFromLine(t *testing.T) {
 func TestDiffLineCode(t *testing.T) {
 	tests := []struct {
 		line      string
+		cols      int
 		wantCode  string
 		wantOff   int
 		wantMatch bool
@@ -167,9 +197,12 @@ func TestDiffLineCode(t *testing.T) {
 		{line: "+++ b/x", wantMatch: false},
 		{line: "--- a/x", wantMatch: false},
 		{line: "\\ No newline at end of file", wantMatch: false},
+		{line: "++foo", cols: 2, wantCode: "foo", wantOff: 2, wantMatch: true},
+		{line: "  bar", cols: 2, wantCode: "bar", wantOff: 2, wantMatch: true},
+		{line: "+", cols: 2, wantMatch: false},
 	}
 	for _, tc := range tests {
-		code, off, ok := diffLineCode(tc.line)
+		code, off, ok := diffLineCode(tc.line, tc.cols)
 		if ok != tc.wantMatch {
 			t.Fatalf("line=%q: want ok=%v, got %v", tc.line, tc.wantMatch, ok)
 		}
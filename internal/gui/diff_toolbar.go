@@ -0,0 +1,270 @@
+package gui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+
+	. "modernc.org/tk9.0"
+)
+
+const (
+	mergeDiffFirstParentLabel = "First parent"
+	mergeDiffCombinedLabel    = "Combined (conflicts only)"
+)
+
+// whitespaceLabels are indexed by git.DiffWhitespace.
+var whitespaceLabels = []string{"Show all", "Ignore all (-w)", "Ignore amount (-b)"}
+
+var (
+	renameLabels    = []string{"Default", "Off", "90%", "75%", "50%", "25%"}
+	copyLabels      = []string{"Off", "90%", "75%", "50%", "25%"}
+	algorithmLabels = []string{"Default", "Myers", "Minimal", "Patience", "Histogram"}
+)
+
+// maxDiffContext bounds the context lines spinner.
+const maxDiffContext = 999
+
+// diffOptionsForm holds the values of the diff option controls.
+type diffOptionsForm struct {
+	whitespace string
+	blankLines bool
+	context    string
+	renames    string
+	copies     string
+	algorithm  string
+}
+
+func newDiffOptionsForm(opts git.DiffOptions) diffOptionsForm {
+	form := diffOptionsForm{
+		whitespace: whitespaceLabels[0],
+		blankLines: opts.IgnoreBlankLines,
+		context:    "3",
+		renames:    renameLabels[0],
+		copies:     copyLabels[0],
+		algorithm:  algorithmLabels[0],
+	}
+	if int(opts.Whitespace) < len(whitespaceLabels) {
+		form.whitespace = whitespaceLabels[opts.Whitespace]
+	}
+	switch {
+	case opts.Context < 0:
+		form.context = "0"
+	case opts.Context > 0:
+		form.context = strconv.Itoa(opts.Context)
+	}
+	switch {
+	case opts.RenameThreshold < 0:
+		form.renames = "Off"
+	case opts.RenameThreshold > 0:
+		form.renames = fmt.Sprintf("%d%%", opts.RenameThreshold)
+	}
+	if opts.CopyThreshold > 0 {
+		form.copies = fmt.Sprintf("%d%%", opts.CopyThreshold)
+	}
+	if opts.Algorithm != git.DiffAlgorithmDefault {
+		form.algorithm = strings.ToUpper(string(opts.Algorithm[:1])) + string(opts.Algorithm[1:])
+	}
+	return form
+}
+
+// options returns the diff options selected in f. Unknown values keep the
+// defaults.
+func (f diffOptionsForm) options() git.DiffOptions {
+	var opts git.DiffOptions
+	for i, label := range whitespaceLabels {
+		if label == f.whitespace {
+			opts.Whitespace = git.DiffWhitespace(i)
+		}
+	}
+	opts.IgnoreBlankLines = f.blankLines
+	if n, err := strconv.Atoi(strings.TrimSpace(f.context)); err == nil && n >= 0 {
+		switch {
+		case n == 0:
+			opts.Context = git.NoContext
+		case n != 3:
+			opts.Context = min(n, maxDiffContext)
+		}
+	}
+	percent := func(label string) int {
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(label), "%"))
+		if err != nil || n <= 0 {
+			return 0
+		}
+		return min(n, 100)
+	}
+	if f.renames == "Off" {
+		opts.RenameThreshold = -1
+	} else {
+		opts.RenameThreshold = percent(f.renames)
+	}
+	opts.CopyThreshold = percent(f.copies)
+	if f.algorithm != algorithmLabels[0] && slices.Contains(algorithmLabels, f.algorithm) {
+		opts.Algorithm = git.DiffAlgorithm(strings.ToLower(f.algorithm))
+	}
+	return opts
+}
+
+// mergeDiffLabels returns the merge diff modes offered for a merge with
+// parents parents.
+func mergeDiffLabels(parents int) []string {
+	labels := []string{mergeDiffFirstParentLabel, mergeDiffCombinedLabel}
+	for n := 2; n <= parents; n++ {
+		labels = append(labels, mergeDiffModeLabel(git.MergeDiffParent(n)))
+	}
+	return labels
+}
+
+func mergeDiffModeLabel(mode git.MergeDiffMode) string {
+	switch parent := mode.Parent(); parent {
+	case 0:
+		return mergeDiffCombinedLabel
+	case 1:
+		return mergeDiffFirstParentLabel
+	default:
+		return fmt.Sprintf("Parent %d", parent)
+	}
+}
+
+func mergeDiffModeFromLabel(label string) git.MergeDiffMode {
+	switch label {
+	case mergeDiffCombinedLabel:
+		return git.MergeDiffCombined
+	case mergeDiffFirstParentLabel:
+		return git.MergeDiffFirstParent
+	}
+	var n int
+	if _, err := fmt.Sscanf(label, "Parent %d", &n); err == nil {
+		return git.MergeDiffParent(n)
+	}
+	return git.MergeDiffFirstParent
+}
+
+// buildDiffToolbar creates the row of diff options above the diff pane.
+func (a *Controller) buildDiffToolbar(parent *TFrameWidget) *TFrameWidget {
+	toolbar := parent.TFrame()
+	form := newDiffOptionsForm(a.state.diff.options)
+	col := 0
+	add := func(label string, w Widget) {
+		pad := "12p 4p"
+		if col == 0 {
+			pad = "0 4p"
+		}
+		Grid(toolbar.TLabel(Txt(label)), Row(0), Column(col), Sticky(W), Padx(pad))
+		Grid(w, Row(0), Column(col+1), Sticky(W))
+		col += 2
+	}
+	combobox := func(values []string, width int, value string) *TComboboxWidget {
+		cb := toolbar.TCombobox(Values(values), State("readonly"), Width(width), Textvariable(value))
+		Bind(cb, "<<ComboboxSelected>>", Command(a.onDiffOptionsChanged))
+		return cb
+	}
+
+	a.ui.mergeDiffMode = toolbar.TCombobox(
+		Values(mergeDiffLabels(2)),
+		State("disabled"),
+		Width(24),
+		Textvariable(mergeDiffModeLabel(a.state.diff.mergeMode)),
+	)
+	Bind(a.ui.mergeDiffMode, "<<ComboboxSelected>>", Command(a.onMergeDiffModeChanged))
+	add("Merge diff:", a.ui.mergeDiffMode)
+
+	a.ui.diffWhitespace = combobox(whitespaceLabels, 18, form.whitespace)
+	add("Whitespace:", a.ui.diffWhitespace)
+	a.ui.diffBlankLines = toolbar.TCheckbutton(
+		Txt("Ignore blank lines"),
+		Variable(form.blankLines),
+		Command(a.onDiffOptionsChanged),
+	)
+	Grid(a.ui.diffBlankLines, Row(0), Column(col), Sticky(W), Padx("8p 0"))
+	col++
+
+	a.ui.diffContext = toolbar.TSpinbox(
+		From(0),
+		To(maxDiffContext),
+		Increment(1),
+		Width(4),
+		Textvariable(form.context),
+		Command(a.onDiffOptionsChanged),
+	)
+	Bind(a.ui.diffContext, "<KeyPress-Return>", Command(a.onDiffOptionsChanged))
+	Bind(a.ui.diffContext, "<FocusOut>", Command(a.onDiffOptionsChanged))
+	add("Context:", a.ui.diffContext)
+
+	a.ui.diffRenames = combobox(renameLabels, 8, form.renames)
+	add("Renames:", a.ui.diffRenames)
+	a.ui.diffCopies = combobox(copyLabels, 6, form.copies)
+	add("Copies:", a.ui.diffCopies)
+	a.ui.diffAlgorithm = combobox(algorithmLabels, 10, form.algorithm)
+	add("Algorithm:", a.ui.diffAlgorithm)
+	return toolbar
+}
+
+// onDiffOptionsChanged applies the diff option controls, redrawing the
+// shown diff when they changed.
+func (a *Controller) onDiffOptionsChanged() {
+	form := diffOptionsForm{
+		whitespace: a.ui.diffWhitespace.Textvariable(),
+		blankLines: a.ui.diffBlankLines.Variable() == "1",
+		context:    a.ui.diffContext.Textvariable(),
+		renames:    a.ui.diffRenames.Textvariable(),
+		copies:     a.ui.diffCopies.Textvariable(),
+		algorithm:  a.ui.diffAlgorithm.Textvariable(),
+	}
+	opts := form.options()
+	if opts == a.state.diff.options {
+		return
+	}
+	a.state.diff.options = opts
+	a.resetLocalDiffState(false)
+	a.resetLocalDiffState(true)
+	a.refreshDiffView()
+}
+
+// refreshDiffView recomputes the diff currently shown: the selected commit,
+// the selected local changes or the comparison of two commits.
+func (a *Controller) refreshDiffView() {
+	if idx := a.state.selection.CommitIndex(a.data.visible); idx >= 0 {
+		if entry := a.data.visible[idx]; entry != nil && entry.Commit != nil {
+			a.showCommitDetails(entry, idx)
+		}
+		return
+	}
+	if staged, ok := a.state.selection.Local(); ok {
+		a.renderLocalChanges(staged, true)
+		return
+	}
+	if c := a.state.diff.compare; c.from != "" {
+		a.showCompareDiff(c.from, c.to, c.fromLabel, c.toLabel)
+	}
+}
+
+// updateMergeDiffSelector offers the merge diff modes of commit, disabling
+// the selector when commit is not a merge.
+func (a *Controller) updateMergeDiffSelector(commit *git.Commit) {
+	if a.ui.mergeDiffMode == nil {
+		return
+	}
+	if commit == nil || len(commit.ParentHashes) < 2 {
+		a.ui.mergeDiffMode.Configure(State("disabled"))
+		return
+	}
+	labels := mergeDiffLabels(len(commit.ParentHashes))
+	label := mergeDiffModeLabel(a.state.diff.mergeMode)
+	if a.state.diff.mergeMode.Parent() > len(commit.ParentHashes) {
+		label = mergeDiffFirstParentLabel
+	}
+	a.ui.mergeDiffMode.Configure(Values(labels), State("readonly"), Textvariable(label))
+}
+
+func (a *Controller) onMergeDiffModeChanged() {
+	mode := mergeDiffModeFromLabel(strings.TrimSpace(a.ui.mergeDiffMode.Textvariable()))
+	if mode == a.state.diff.mergeMode {
+		return
+	}
+	a.state.diff.mergeMode = mode
+	a.refreshDiffView()
+}
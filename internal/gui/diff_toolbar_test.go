@@ -0,0 +1,53 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestMergeDiffModeLabels(t *testing.T) {
+	labels := mergeDiffLabels(3)
+	want := []string{mergeDiffFirstParentLabel, mergeDiffCombinedLabel, "Parent 2", "Parent 3"}
+	if len(labels) != len(want) {
+		t.Fatalf("labels = %v, want %v", labels, want)
+	}
+	for i, label := range labels {
+		if label != want[i] {
+			t.Fatalf("labels = %v, want %v", labels, want)
+		}
+		mode := mergeDiffModeFromLabel(label)
+		if got := mergeDiffModeLabel(mode); got != label {
+			t.Fatalf("round trip of %q gave %q", label, got)
+		}
+	}
+	if got := mergeDiffModeFromLabel("bogus"); got != git.MergeDiffFirstParent {
+		t.Fatalf("unknown label gave %v", got)
+	}
+}
+
+func TestDiffOptionsForm(t *testing.T) {
+	tests := []git.DiffOptions{
+		{},
+		{Whitespace: git.WhitespaceIgnoreAll, IgnoreBlankLines: true},
+		{Whitespace: git.WhitespaceIgnoreChange, Context: 10},
+		{Context: git.NoContext, RenameThreshold: -1},
+		{RenameThreshold: 75, CopyThreshold: 50, Algorithm: git.DiffAlgorithmHistogram},
+	}
+	for _, opts := range tests {
+		if got := newDiffOptionsForm(opts).options(); got != opts {
+			t.Fatalf("round trip of %+v gave %+v", opts, got)
+		}
+	}
+
+	form := newDiffOptionsForm(git.DiffOptions{})
+	form.context = "3"
+	if got := form.options(); got.Context != 0 {
+		t.Fatalf("default context gave Context=%d, want 0", got.Context)
+	}
+	form.context = "abc"
+	form.algorithm = "Unknown"
+	if got := form.options(); got != (git.DiffOptions{}) {
+		t.Fatalf("invalid values gave %+v, want defaults", got)
+	}
+}
@@ -0,0 +1,101 @@
+package gui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+
+	. "modernc.org/tk9.0"
+)
+
+// fileHistorySpec returns the log spec following the history of path across
+// renames, from the revisions selected by spec.
+func fileHistorySpec(spec git.LogSpec, path string) git.LogSpec {
+	return git.LogSpec{
+		Revisions: slices.Clone(spec.Revisions),
+		Paths:     []string{path},
+		Follow:    true,
+	}
+}
+
+// buildFileHistoryBanner creates the banner shown above the commit list
+// while it holds the history of a single file.
+func (a *Controller) buildFileHistoryBanner(parent *TFrameWidget) *TFrameWidget {
+	banner := parent.TFrame(Padding("4p"))
+	GridColumnConfigure(banner.Window, 0, Weight(1))
+	a.ui.fileHistoryLabel = banner.TLabel(Anchor(W))
+	Grid(a.ui.fileHistoryLabel, Row(0), Column(0), Sticky(WE))
+	Grid(banner.TButton(Txt("Back to full history"), Command(a.showFullHistory)), Row(0), Column(1), Sticky(E))
+	a.ui.fileHistoryBanner = banner
+	return banner
+}
+
+// showFileHistory restarts the commit list with the commits that touched
+// path, following its renames.
+func (a *Controller) showFileHistory(path string) {
+	path = strings.TrimSpace(path)
+	if a.svc == nil || path == "" {
+		return
+	}
+	hist := &a.state.fileHistory
+	if hist.path == path {
+		return
+	}
+	if hist.path == "" {
+		hist.previous = a.cfg.logSpec
+	}
+	hist.path = path
+	a.cfg.logSpec = fileHistorySpec(hist.previous, path)
+	a.updateFileHistoryBanner()
+	a.restartCommitList(fmt.Sprintf("Loading history of %s...", path))
+}
+
+// showFullHistory leaves the file history, going back to the commits shown
+// before it.
+func (a *Controller) showFullHistory() {
+	hist := &a.state.fileHistory
+	if hist.path == "" {
+		return
+	}
+	a.cfg.logSpec = hist.previous
+	*hist = fileHistoryState{}
+	a.updateFileHistoryBanner()
+	a.restartCommitList("Loading commits...")
+}
+
+func (a *Controller) updateFileHistoryBanner() {
+	if a.ui.fileHistoryBanner == nil {
+		return
+	}
+	path := a.state.fileHistory.path
+	if path == "" {
+		GridRemove(a.ui.fileHistoryBanner.Window)
+		return
+	}
+	a.ui.fileHistoryLabel.Configure(Txt(fmt.Sprintf("History of %s, following renames", path)))
+	Grid(a.ui.fileHistoryBanner)
+}
+
+// showFileListMenu opens the context menu of the changed file under the
+// pointer.
+func (a *Controller) showFileListMenu(e *Event) {
+	if e == nil {
+		return
+	}
+	id := strings.TrimSpace(a.ui.diffFileList.IdentifyItem(e.X, e.Y))
+	idx := fileRowIndex(id)
+	// Row 0 is the commit message.
+	if idx <= 0 || idx >= len(a.state.diff.fileSections) {
+		return
+	}
+	a.ui.diffFileList.Selection("set", id)
+	a.ui.diffFileList.Focus(id)
+	a.state.diff.historyPath = a.state.diff.fileSections[idx].Path
+	Popup(a.ui.fileListMenu.Window, e.XRoot, e.YRoot, nil)
+}
+
+func (a *Controller) showContextFileHistory() {
+	a.showFileHistory(a.state.diff.historyPath)
+}
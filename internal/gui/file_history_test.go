@@ -0,0 +1,20 @@
+package gui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestFileHistorySpec(t *testing.T) {
+	prev := git.LogSpec{Revisions: []string{"--all"}, Paths: []string{"src"}}
+	spec := fileHistorySpec(prev, "src/main.go")
+	if !spec.Follow || !slices.Equal(spec.Paths, []string{"src/main.go"}) || !slices.Equal(spec.Revisions, []string{"--all"}) {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+	spec.Revisions[0] = "HEAD"
+	if prev.Revisions[0] != "--all" {
+		t.Fatal("expected the revisions to be copied")
+	}
+}
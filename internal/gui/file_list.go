@@ -0,0 +1,252 @@
+package gui
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+// Columns of the changed files table. fileColumnNone keeps the diff order.
+const (
+	fileColumnNone    = ""
+	fileColumnStatus  = "status"
+	fileColumnPath    = "path"
+	fileColumnAdded   = "added"
+	fileColumnDeleted = "deleted"
+)
+
+var fileColumnHeadings = map[string]string{
+	fileColumnStatus:  "Type",
+	fileColumnPath:    "File",
+	fileColumnAdded:   "Added",
+	fileColumnDeleted: "Removed",
+}
+
+// fileSortState is the column the changed files table is sorted by.
+type fileSortState struct {
+	column string
+	desc   bool
+}
+
+// toggle sorts by column, reversing the order when it is already sorted by
+// it.
+func (s fileSortState) toggle(column string) fileSortState {
+	if s.column == column {
+		return fileSortState{column: column, desc: !s.desc}
+	}
+	return fileSortState{column: column}
+}
+
+// fileRowID is the table row of a.state.diff.fileSections[idx].
+func fileRowID(idx int) string {
+	return "file:" + strconv.Itoa(idx)
+}
+
+func fileRowIndex(id string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(id, "file:"))
+	if err != nil || !strings.HasPrefix(id, "file:") {
+		return -1
+	}
+	return n
+}
+
+// fileRowValues returns the cells of a changed file row.
+func fileRowValues(sec git.FileSection) []string {
+	path := sec.Path
+	if sec.OldPath != "" && sec.OldPath != sec.Path {
+		path = sec.OldPath + " → " + sec.Path
+	}
+	added, deleted := "+"+strconv.Itoa(sec.Added), "-"+strconv.Itoa(sec.Deleted)
+	if sec.Binary {
+		added, deleted = "bin", ""
+	}
+	return []string{sec.Status, path, added, deleted}
+}
+
+// sortFileSections returns the indexes of sections in the order of sort.
+// Ties keep the diff order.
+func sortFileSections(sections []git.FileSection, sort fileSortState) []int {
+	order := make([]int, len(sections))
+	for i := range order {
+		order[i] = i
+	}
+	var compare func(a, b git.FileSection) int
+	switch sort.column {
+	case fileColumnStatus:
+		compare = func(a, b git.FileSection) int { return cmp.Compare(a.Status, b.Status) }
+	case fileColumnPath:
+		compare = func(a, b git.FileSection) int { return cmp.Compare(a.Path, b.Path) }
+	case fileColumnAdded:
+		compare = func(a, b git.FileSection) int { return cmp.Compare(a.Added, b.Added) }
+	case fileColumnDeleted:
+		compare = func(a, b git.FileSection) int { return cmp.Compare(a.Deleted, b.Deleted) }
+	default:
+		return order
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		c := compare(sections[i], sections[j])
+		if sort.desc {
+			c = -c
+		}
+		return c
+	})
+	return order
+}
+
+// fileListSummary totals sections like git diff --shortstat.
+func fileListSummary(sections []git.FileSection) string {
+	if len(sections) == 0 {
+		return ""
+	}
+	added, deleted, binary := 0, 0, 0
+	for _, sec := range sections {
+		added += sec.Added
+		deleted += sec.Deleted
+		if sec.Binary {
+			binary++
+		}
+	}
+	plural := func(n int, one, many string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, one)
+		}
+		return fmt.Sprintf("%d %s", n, many)
+	}
+	parts := []string{plural(len(sections), "file changed", "files changed")}
+	if added > 0 {
+		parts = append(parts, plural(added, "insertion(+)", "insertions(+)"))
+	}
+	if deleted > 0 {
+		parts = append(parts, plural(deleted, "deletion(-)", "deletions(-)"))
+	}
+	if binary > 0 {
+		parts = append(parts, fmt.Sprintf("%d binary", binary))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buildFileList creates the file panel inside parent: the mode selector and
+// summary, the changed files table and the tree browser.
+func (a *Controller) buildFileList(parent *TFrameWidget) {
+	GridRowConfigure(parent.Window, 1, Weight(1))
+	GridColumnConfigure(parent.Window, 0, Weight(1))
+	header := parent.TFrame()
+	GridColumnConfigure(header.Window, 2, Weight(1))
+	a.ui.fileMode = header.TCombobox(
+		Values([]string{filePanelPatch, filePanelTree}),
+		State("readonly"),
+		Width(6),
+		Textvariable(filePanelPatch),
+	)
+	Bind(a.ui.fileMode, "<<ComboboxSelected>>", Command(a.onFilePanelModeChanged))
+	a.ui.diffFileSummary = header.TLabel(Anchor(W))
+	Grid(header.TLabel(Txt("Show:")), Row(0), Column(0), Sticky(W), Padx("0 4p"))
+	Grid(a.ui.fileMode, Row(0), Column(1), Sticky(W))
+	Grid(a.ui.diffFileSummary, Row(0), Column(2), Sticky(WE), Padx("8p 0"))
+	Grid(header, Row(0), Column(0), Sticky(WE), Pady("0 4p"))
+
+	a.ui.fileTable = parent.TFrame()
+	GridRowConfigure(a.ui.fileTable.Window, 0, Weight(1))
+	GridColumnConfigure(a.ui.fileTable.Window, 0, Weight(1))
+	scroll := a.ui.fileTable.TScrollbar()
+	a.ui.diffFileList = a.ui.fileTable.TTreeview(
+		Show("headings"),
+		Columns(strings.Join([]string{fileColumnStatus, fileColumnPath, fileColumnAdded, fileColumnDeleted}, " ")),
+		Selectmode("browse"),
+		Yscrollcommand(func(e *Event) { e.ScrollSet(scroll) }),
+	)
+	list := a.ui.diffFileList
+	list.Column(fileColumnStatus, Width(40), Anchor(CENTER), Stretch(false))
+	list.Column(fileColumnPath, Width(240))
+	list.Column(fileColumnAdded, Width(60), Anchor(E), Stretch(false))
+	list.Column(fileColumnDeleted, Width(60), Anchor(E), Stretch(false))
+	for column := range fileColumnHeadings {
+		list.Heading(column, Command(func() { a.sortFileList(column) }))
+	}
+	a.updateFileListHeadings()
+	Grid(list, Row(0), Column(0), Sticky(NEWS))
+	Grid(scroll, Row(0), Column(1), Sticky(NS))
+	scroll.Configure(Command(func(e *Event) { e.Yview(list) }))
+	Bind(list, "<<TreeviewSelect>>", Command(a.onFileSelectionChanged))
+	a.ui.fileListMenu = App.Menu(Tearoff(false))
+	a.ui.fileListMenu.AddCommand(Lbl("Show history of this file"), Command(a.showContextFileHistory))
+	handler := func(e *Event) { a.showFileListMenu(e) }
+	Bind(list, "<Button-2>", Command(handler))
+	Bind(list, "<Button-3>", Command(handler))
+	Grid(a.ui.fileTable, Row(1), Column(0), Sticky(NEWS))
+
+	a.buildFileTree(parent)
+	Grid(a.ui.fileTreeFrame, Row(1), Column(0), Sticky(NEWS))
+	GridRemove(a.ui.fileTreeFrame.Window)
+}
+
+// sortFileList sorts the changed files table by column.
+func (a *Controller) sortFileList(column string) {
+	a.state.diff.fileSort = a.state.diff.fileSort.toggle(column)
+	a.updateFileListHeadings()
+	a.orderFileRows()
+}
+
+func (a *Controller) updateFileListHeadings() {
+	sort := a.state.diff.fileSort
+	for column, heading := range fileColumnHeadings {
+		if column == sort.column {
+			if sort.desc {
+				heading += " ▼"
+			} else {
+				heading += " ▲"
+			}
+		}
+		a.ui.diffFileList.Heading(column, Txt(heading))
+	}
+}
+
+// orderFileRows moves the file rows into the sort order. The commit row
+// stays first.
+func (a *Controller) orderFileRows() {
+	sections := a.state.diff.fileSections
+	if len(sections) < 2 {
+		return
+	}
+	for pos, idx := range sortFileSections(sections[1:], a.state.diff.fileSort) {
+		if _, err := tkutil.Eval("%s move %s {} %d", a.ui.diffFileList, fileRowID(idx+1), pos+1); err != nil {
+			slog.Debug("sort changed files", slog.Any("error", err))
+			return
+		}
+	}
+}
+
+// clearFileList removes all rows and the summary of the changed files table.
+func (a *Controller) clearFileList() {
+	if a.ui.diffFileList == nil {
+		return
+	}
+	if children := a.ui.diffFileList.Children(""); len(children) > 0 {
+		args := make([]any, len(children))
+		for i, child := range children {
+			args[i] = child
+		}
+		a.ui.diffFileList.Delete(args...)
+	}
+	if !a.state.browse.active {
+		a.ui.diffFileSummary.Configure(Txt(""))
+	}
+}
+
+// selectedFileIndex returns the index in a.state.diff.fileSections of the
+// selected row, or -1.
+func (a *Controller) selectedFileIndex() int {
+	sel := a.ui.diffFileList.Selection("")
+	if len(sel) == 0 {
+		return -1
+	}
+	return fileRowIndex(sel[0])
+}
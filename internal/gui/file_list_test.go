@@ -0,0 +1,85 @@
+package gui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestFileRowValues(t *testing.T) {
+	tests := []struct {
+		sec  git.FileSection
+		want []string
+	}{
+		{git.FileSection{Path: "a.go", Status: "M", Added: 3, Deleted: 1}, []string{"M", "a.go", "+3", "-1"}},
+		{git.FileSection{Path: "new.go", OldPath: "old.go", Status: "R"}, []string{"R", "old.go → new.go", "+0", "-0"}},
+		{git.FileSection{Path: "logo.png", Status: "A", Binary: true}, []string{"A", "logo.png", "bin", ""}},
+	}
+	for _, tt := range tests {
+		if got := fileRowValues(tt.sec); !slices.Equal(got, tt.want) {
+			t.Fatalf("fileRowValues(%+v) = %q, want %q", tt.sec, got, tt.want)
+		}
+	}
+}
+
+func TestSortFileSections(t *testing.T) {
+	sections := []git.FileSection{
+		{Path: "b.go", Status: "M", Added: 5, Deleted: 1},
+		{Path: "a.go", Status: "A", Added: 1},
+		{Path: "c.go", Status: "M", Added: 5, Deleted: 7},
+	}
+	tests := []struct {
+		sort fileSortState
+		want []int
+	}{
+		{fileSortState{}, []int{0, 1, 2}},
+		{fileSortState{column: fileColumnPath}, []int{1, 0, 2}},
+		{fileSortState{column: fileColumnStatus}, []int{1, 0, 2}},
+		{fileSortState{column: fileColumnAdded, desc: true}, []int{0, 2, 1}},
+		{fileSortState{column: fileColumnDeleted, desc: true}, []int{2, 0, 1}},
+	}
+	for _, tt := range tests {
+		if got := sortFileSections(sections, tt.sort); !slices.Equal(got, tt.want) {
+			t.Fatalf("sortFileSections(%+v) = %v, want %v", tt.sort, got, tt.want)
+		}
+	}
+}
+
+func TestFileSortToggle(t *testing.T) {
+	sort := fileSortState{}.toggle(fileColumnPath)
+	if sort != (fileSortState{column: fileColumnPath}) {
+		t.Fatalf("first toggle = %+v", sort)
+	}
+	if sort = sort.toggle(fileColumnPath); !sort.desc {
+		t.Fatalf("second toggle = %+v, want descending", sort)
+	}
+	if sort = sort.toggle(fileColumnAdded); sort != (fileSortState{column: fileColumnAdded}) {
+		t.Fatalf("other column = %+v", sort)
+	}
+}
+
+func TestFileListSummary(t *testing.T) {
+	tests := []struct {
+		sections []git.FileSection
+		want     string
+	}{
+		{nil, ""},
+		{[]git.FileSection{{Added: 1}}, "1 file changed, 1 insertion(+)"},
+		{[]git.FileSection{{Added: 2, Deleted: 1}, {Binary: true}}, "2 files changed, 2 insertions(+), 1 deletion(-), 1 binary"},
+	}
+	for _, tt := range tests {
+		if got := fileListSummary(tt.sections); got != tt.want {
+			t.Fatalf("fileListSummary(%+v) = %q, want %q", tt.sections, got, tt.want)
+		}
+	}
+}
+
+func TestFileRowIndex(t *testing.T) {
+	if got := fileRowIndex(fileRowID(4)); got != 4 {
+		t.Fatalf("fileRowIndex = %d, want 4", got)
+	}
+	if got := fileRowIndex("ref:1"); got != -1 {
+		t.Fatalf("fileRowIndex of foreign id = %d, want -1", got)
+	}
+}
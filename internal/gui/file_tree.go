@@ -0,0 +1,360 @@
+package gui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+
+	. "modernc.org/tk9.0"
+)
+
+// Modes of the file panel, like gitk's Patch and Tree views.
+const (
+	filePanelPatch = "Patch"
+	filePanelTree  = "Tree"
+)
+
+// lineNumberTag dims the line numbers of a file shown from the tree browser.
+const lineNumberTag = "lineNumber"
+
+// binaryCheckBytes is how much of a file is searched for a NUL byte to tell
+// binary files apart, as git does.
+const binaryCheckBytes = 8000
+
+// fileTreeItem is a row of the tree browser.
+type fileTreeItem struct {
+	id     string
+	parent string
+	label  string
+	entry  git.TreeEntry
+}
+
+// fileTreeItems lays out entries, which list directories before their
+// contents, as nested rows. Directory labels end with a slash.
+func fileTreeItems(entries []git.TreeEntry) []fileTreeItem {
+	dirs := map[string]string{}
+	items := make([]fileTreeItem, 0, len(entries))
+	for i, entry := range entries {
+		dir, name := path.Split(entry.Path)
+		item := fileTreeItem{
+			id:     "tree:" + strconv.Itoa(i),
+			parent: dirs[strings.TrimSuffix(dir, "/")],
+			label:  name,
+			entry:  entry,
+		}
+		switch entry.Kind {
+		case git.TreeEntryTree:
+			item.label += "/"
+			dirs[entry.Path] = item.id
+		case git.TreeEntrySubmodule:
+			item.label += " (submodule)"
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// numberLines prefixes every line of content with its number and returns the
+// width of the prefix.
+func numberLines(content string) (string, int) {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	width := len(strconv.Itoa(len(lines)))
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%*d  %s", width, i+1, line)
+	}
+	return b.String(), width + 2
+}
+
+func isBinaryContent(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), binaryCheckBytes)], 0) >= 0
+}
+
+// buildFileTree creates the tree browser inside parent. It is gridded by the
+// caller.
+func (a *Controller) buildFileTree(parent *TFrameWidget) {
+	a.ui.fileTreeFrame = parent.TFrame()
+	frame := a.ui.fileTreeFrame
+	GridRowConfigure(frame.Window, 0, Weight(1))
+	GridColumnConfigure(frame.Window, 0, Weight(1))
+	scroll := frame.TScrollbar()
+	a.ui.fileTree = frame.TTreeview(
+		Show("tree"),
+		Selectmode("browse"),
+		Yscrollcommand(func(e *Event) { e.ScrollSet(scroll) }),
+	)
+	Grid(a.ui.fileTree, Row(0), Column(0), Sticky(NEWS))
+	Grid(scroll, Row(0), Column(1), Sticky(NS))
+	scroll.Configure(Command(func(e *Event) { e.Yview(a.ui.fileTree) }))
+	Bind(a.ui.fileTree, "<<TreeviewSelect>>", Command(a.onFileTreeSelected))
+
+	menu := App.Menu(Tearoff(false))
+	a.ui.fileMenuItems = map[string]*MenuItem{
+		"save": menu.AddCommand(Lbl("Save As..."), Command(a.saveContextFile)),
+		"copy": menu.AddCommand(Lbl("Copy Path"), Command(a.copyContextFilePath)),
+	}
+	a.ui.fileMenuItems["blame"] = menu.AddCommand(Lbl("Blame"), Command(a.blameContextFile))
+	a.ui.fileTreeMenu = menu
+	handler := func(e *Event) { a.showFileTreeMenu(e) }
+	Bind(a.ui.fileTree, "<Button-2>", Command(handler))
+	Bind(a.ui.fileTree, "<Button-3>", Command(handler))
+}
+
+func (a *Controller) onFilePanelModeChanged() {
+	tree := strings.TrimSpace(a.ui.fileMode.Textvariable()) == filePanelTree
+	if tree == a.state.browse.active {
+		return
+	}
+	a.state.browse.active = tree
+	if tree {
+		GridRemove(a.ui.fileTable.Window)
+		Grid(a.ui.fileTreeFrame)
+		a.loadFileTree()
+		return
+	}
+	GridRemove(a.ui.fileTreeFrame.Window)
+	Grid(a.ui.fileTable)
+	a.ui.diffFileSummary.Configure(Txt(fileListSummary(a.state.diff.sections)))
+	// Bring back the patch in place of a file shown from the tree.
+	if a.state.browse.shown != "" {
+		a.state.browse.shown = ""
+		a.refreshDiffView()
+	}
+}
+
+// browseCommit sets the commit listed by the tree browser. An empty hash
+// leaves it empty, as local changes and comparisons have no single tree.
+func (a *Controller) browseCommit(hash string) {
+	a.state.browse.commit = hash
+	a.state.browse.shown = ""
+	a.loadFileTree()
+}
+
+// loadFileTree lists the tree of the browsed commit when the tree browser is
+// shown and lists another commit.
+func (a *Controller) loadFileTree() {
+	browse := &a.state.browse
+	if !browse.active || a.ui.fileTree == nil || browse.commit == browse.loaded {
+		return
+	}
+	a.clearFileTree()
+	browse.loaded = browse.commit
+	browse.gen++
+	if browse.commit == "" || a.svc == nil {
+		a.ui.diffFileSummary.Configure(Txt("Select a commit to browse its files."))
+		return
+	}
+	svc, hash, gen := a.svc, browse.commit, browse.gen
+	a.ui.diffFileSummary.Configure(Txt(fmt.Sprintf("Loading files of %s...", shortHash(hash))))
+	go func() {
+		entries, err := svc.Tree(hash)
+		PostEvent(func() {
+			if svc != a.svc || gen != a.state.browse.gen {
+				return
+			}
+			if err != nil {
+				a.ui.diffFileSummary.Configure(Txt(fmt.Sprintf("Unable to list files: %v", err)))
+				return
+			}
+			a.populateFileTree(entries)
+		}, false)
+	}()
+}
+
+func (a *Controller) populateFileTree(entries []git.TreeEntry) {
+	items := fileTreeItems(entries)
+	files := 0
+	for _, item := range items {
+		a.ui.fileTree.Insert(item.parent, "end", Id(item.id), Txt(item.label))
+		if item.entry.Kind != git.TreeEntryTree {
+			files++
+		}
+	}
+	a.state.browse.items = items
+	noun := "files"
+	if files == 1 {
+		noun = "file"
+	}
+	a.ui.diffFileSummary.Configure(Txt(fmt.Sprintf("%d %s at %s", files, noun, shortHash(a.state.browse.loaded))))
+}
+
+func (a *Controller) clearFileTree() {
+	if children := a.ui.fileTree.Children(""); len(children) > 0 {
+		args := make([]any, len(children))
+		for i, child := range children {
+			args[i] = child
+		}
+		a.ui.fileTree.Delete(args...)
+	}
+	a.state.browse.items = nil
+	a.state.browse.contextItem = ""
+}
+
+// resetFileTree forgets the listed tree, keeping the panel mode.
+func (a *Controller) resetFileTree() {
+	if a.ui.fileTree != nil {
+		a.clearFileTree()
+	}
+	a.state.browse = browseState{active: a.state.browse.active, gen: a.state.browse.gen + 1}
+	if a.state.browse.active && a.ui.diffFileSummary != nil {
+		a.ui.diffFileSummary.Configure(Txt("Select a commit to browse its files."))
+	}
+}
+
+func (a *Controller) fileTreeItem(id string) *fileTreeItem {
+	for i := range a.state.browse.items {
+		if a.state.browse.items[i].id == id {
+			return &a.state.browse.items[i]
+		}
+	}
+	return nil
+}
+
+func (a *Controller) onFileTreeSelected() {
+	sel := a.ui.fileTree.Selection("")
+	if len(sel) == 0 {
+		return
+	}
+	item := a.fileTreeItem(sel[0])
+	if item == nil || item.entry.Kind != git.TreeEntryBlob {
+		return
+	}
+	a.showTreeFile(item.entry.Path)
+}
+
+// showTreeFile shows the contents of the file at path in the browsed commit
+// in the detail pane.
+func (a *Controller) showTreeFile(filePath string) {
+	hash := a.state.browse.loaded
+	if a.svc == nil || hash == "" || filePath == a.state.browse.shown {
+		return
+	}
+	svc := a.svc
+	a.state.browse.shown = filePath
+	a.state.browse.fileGen++
+	gen := a.state.browse.fileGen
+	go func() {
+		data, err := svc.Blob(hash, filePath)
+		PostEvent(func() {
+			if svc != a.svc || gen != a.state.browse.fileGen || hash != a.state.browse.loaded {
+				return
+			}
+			if err != nil {
+				a.clearDetailText(fmt.Sprintf("Unable to read %s: %v", filePath, err))
+				return
+			}
+			a.writeFileText(filePath, data)
+			a.setStatus(fmt.Sprintf("Showing %s at %s.", filePath, shortHash(hash)))
+		}, false)
+	}()
+}
+
+// writeFileText shows the contents of a file with line numbers, highlighting
+// its syntax when enabled.
+func (a *Controller) writeFileText(filePath string, data []byte) {
+	if isBinaryContent(data) {
+		a.clearDetailText(fmt.Sprintf("%s\n\nBinary file, %d bytes.", filePath, len(data)))
+		return
+	}
+	content := string(data)
+	text, gutter := numberLines(content)
+	a.clearDetailText(text)
+	detail := a.ui.diffDetail
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		detail.TagAdd(lineNumberTag, fmt.Sprintf("%d.0", i+1), fmt.Sprintf("%d.%d", i+1, gutter))
+	}
+	if !a.cfg.syntaxHighlight {
+		return
+	}
+	style := styleForPalette(a.theme.palette)
+	lexer := lexerForPath(filePath)
+	for i, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		a.highlightCodeLine(detail, lexer, style, line, i+1, gutter)
+	}
+}
+
+func (a *Controller) showFileTreeMenu(e *Event) {
+	if e == nil {
+		return
+	}
+	id := strings.TrimSpace(a.ui.fileTree.IdentifyItem(e.X, e.Y))
+	item := a.fileTreeItem(id)
+	if item == nil {
+		return
+	}
+	a.ui.fileTree.Selection("set", id)
+	a.ui.fileTree.Focus(id)
+	a.state.browse.contextItem = id
+	save := "normal"
+	if item.entry.Kind != git.TreeEntryBlob {
+		save = "disabled"
+	}
+	a.ui.fileTreeMenu.EntryConfigure(a.ui.fileMenuItems["save"], State(save))
+	a.ui.fileTreeMenu.EntryConfigure(a.ui.fileMenuItems["blame"], State(save))
+	Popup(a.ui.fileTreeMenu.Window, e.XRoot, e.YRoot, nil)
+}
+
+func (a *Controller) copyContextFilePath() {
+	item := a.fileTreeItem(a.state.browse.contextItem)
+	if item == nil {
+		return
+	}
+	ClipboardClear()
+	ClipboardAppend(item.entry.Path)
+	a.setStatus(fmt.Sprintf("Copied %s to clipboard.", item.entry.Path))
+}
+
+// saveContextFile writes the file under the tree browser context menu, as
+// of the browsed commit, to a path picked by the user.
+func (a *Controller) saveContextFile() {
+	item := a.fileTreeItem(a.state.browse.contextItem)
+	hash := a.state.browse.loaded
+	if item == nil || item.entry.Kind != git.TreeEntryBlob || a.svc == nil || hash == "" {
+		return
+	}
+	target := GetSaveFile(Parent(App), Title("Save File"), Initialfile(path.Base(item.entry.Path)))
+	if target == "" {
+		return
+	}
+	svc, entry := a.svc, item.entry
+	perm := os.FileMode(0o644)
+	if entry.Mode == "100755" {
+		perm = 0o755
+	}
+	go func() {
+		data, err := svc.Blob(hash, entry.Path)
+		if err == nil {
+			err = os.WriteFile(target, data, perm)
+		}
+		PostEvent(func() {
+			if err != nil {
+				MessageBox(
+					Parent(App),
+					Title("Save File"),
+					Icon("error"),
+					Msg(fmt.Sprintf("Unable to save %s:\n\n%v", entry.Path, err)),
+					Type("ok"),
+				)
+				return
+			}
+			a.setStatus(fmt.Sprintf("Saved %s at %s to %s.", entry.Path, shortHash(hash), target))
+		}, false)
+	}()
+}
+
+func (a *Controller) blameContextFile() {
+	item := a.fileTreeItem(a.state.browse.contextItem)
+	if item == nil || item.entry.Kind != git.TreeEntryBlob {
+		return
+	}
+	a.openBlame(a.state.browse.loaded, item.entry.Path)
+}
@@ -0,0 +1,63 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestFileTreeItems(t *testing.T) {
+	entries := []git.TreeEntry{
+		{Path: "README.md", Kind: git.TreeEntryBlob},
+		{Path: "cmd", Kind: git.TreeEntryTree},
+		{Path: "cmd/app", Kind: git.TreeEntryTree},
+		{Path: "cmd/app/main.go", Kind: git.TreeEntryBlob},
+		{Path: "vendor", Kind: git.TreeEntrySubmodule},
+	}
+	want := []struct{ id, parent, label string }{
+		{"tree:0", "", "README.md"},
+		{"tree:1", "", "cmd/"},
+		{"tree:2", "tree:1", "app/"},
+		{"tree:3", "tree:2", "main.go"},
+		{"tree:4", "", "vendor (submodule)"},
+	}
+	items := fileTreeItems(entries)
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(items), len(want))
+	}
+	for i, item := range items {
+		if item.id != want[i].id || item.parent != want[i].parent || item.label != want[i].label {
+			t.Fatalf("item %d = %+v, want %+v", i, item, want[i])
+		}
+		if item.entry != entries[i] {
+			t.Fatalf("item %d entry = %+v, want %+v", i, item.entry, entries[i])
+		}
+	}
+}
+
+func TestNumberLines(t *testing.T) {
+	content := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	got, gutter := numberLines(content)
+	if gutter != 4 {
+		t.Fatalf("gutter = %d, want 4", gutter)
+	}
+	want := " 1  a\n 2  b\n 3  c\n 4  d\n 5  e\n 6  f\n 7  g\n 8  h\n 9  i\n10  j"
+	if got != want {
+		t.Fatalf("numberLines = %q, want %q", got, want)
+	}
+	if got, _ := numberLines("no newline"); got != "1  no newline" {
+		t.Fatalf("numberLines without trailing newline = %q", got)
+	}
+}
+
+func TestIsBinaryContent(t *testing.T) {
+	if isBinaryContent([]byte("plain text\n")) {
+		t.Fatal("text reported as binary")
+	}
+	if !isBinaryContent([]byte("PNG\x00\x01")) {
+		t.Fatal("NUL byte not reported as binary")
+	}
+	if isBinaryContent(nil) {
+		t.Fatal("empty file reported as binary")
+	}
+}
@@ -1,11 +1,15 @@
 package gui
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/thiagokokada/gitk-go/internal/debounce"
+	"github.com/thiagokokada/gitk-go/internal/git"
 	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
 
 	. "modernc.org/tk9.0"
@@ -20,7 +24,106 @@ func (a *Controller) applyFilter(raw string) {
 
 func (a *Controller) applyFilterState(raw string) {
 	a.state.filter.value = raw
-	a.data.visible = filterEntries(a.data.commits, raw)
+	a.state.filter.searchErr = nil
+	if a.state.filter.mode != filterModeText {
+		// Diff and path searches highlight matches instead of filtering.
+		a.state.filter.keepSearches(nil)
+		a.data.visible = a.data.commits
+		return
+	}
+	query, err := parseFilterQuery(raw)
+	a.state.filter.err = err
+	if err != nil {
+		// Keep showing everything until the query is fixed; the status bar
+		// explains what is wrong.
+		a.data.visible = a.data.commits
+		return
+	}
+	a.startFilterSearches(query)
+	a.state.filter.searchErr = query.searchError(a.state.filter.failed)
+	a.data.visible = filterEntries(a.data.commits, query, a.state.filter.pushed, a.state.filter.failed)
+}
+
+// startFilterSearches runs the backend searches a text mode query needs
+// (path: and content: terms) that are neither cached, failed nor running, and
+// cancels those of terms no longer in the query. The filter is reapplied as
+// each completes.
+func (a *Controller) startFilterSearches(query filterQuery) {
+	searches := query.searches()
+	a.state.filter.keepSearches(searches)
+	for _, search := range searches {
+		if _, ok := a.state.filter.pushed[search]; ok || a.state.filter.pushing[search] != nil {
+			continue
+		}
+		if _, failed := a.state.filter.failed[search]; failed {
+			continue
+		}
+		if a.state.filter.pushing == nil {
+			a.state.filter.pushing = map[git.SearchQuery]*filterSearch{}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		job := &filterSearch{cancel: cancel}
+		a.state.filter.pushing[search] = job
+		spec := a.cfg.logSpec
+		svc := a.svc
+		go func() {
+			defer cancel()
+			matches, err := svc.SearchCommits(ctx, spec, search)
+			PostEvent(func() {
+				// Searches cancelled or dropped by a reload are no longer in
+				// pushing.
+				if a.state.filter.pushing[search] != job {
+					return
+				}
+				delete(a.state.filter.pushing, search)
+				if err != nil {
+					// Record the failure so the term matches nothing instead
+					// of being retried on every refresh.
+					slog.Error("filter search", slog.Any("error", err))
+					if a.state.filter.failed == nil {
+						a.state.filter.failed = map[git.SearchQuery]error{}
+					}
+					a.state.filter.failed[search] = err
+				} else {
+					if a.state.filter.pushed == nil {
+						a.state.filter.pushed = map[git.SearchQuery]map[string]bool{}
+					}
+					a.state.filter.pushed[search] = matches
+				}
+				if a.state.filter.mode == filterModeText {
+					a.applyFilterContent(a.state.filter.value)
+				}
+			}, false)
+		}()
+	}
+}
+
+// resetFilterSearches cancels the backend searches and drops their cached
+// results, which are stale once the commit list is reloaded.
+func (a *Controller) resetFilterSearches() {
+	a.state.filter.keepSearches(nil)
+}
+
+// keepSearches cancels the backend searches in flight and forgets the results
+// and failures of those not in searches, so terms edited out of the query
+// neither keep running nor pile up.
+func (f *filterState) keepSearches(searches []git.SearchQuery) {
+	for search, job := range f.pushing {
+		if !slices.Contains(searches, search) {
+			job.cancel()
+			delete(f.pushing, search)
+		}
+	}
+	for search := range f.pushed {
+		if !slices.Contains(searches, search) {
+			delete(f.pushed, search)
+		}
+	}
+	for search := range f.failed {
+		if !slices.Contains(searches, search) {
+			delete(f.failed, search)
+		}
+	}
 }
 
 func (a *Controller) applyFilterImmediate(raw string) {
@@ -35,22 +138,23 @@ func (a *Controller) applyFilterContent(raw string) {
 	a.insertLocalRows()
 	rows := buildTreeRows(a.data.visible, a.state.tree.branchLabels, a.cfg.graphCanvas)
 	for _, row := range rows {
-		graph := row.Graph
-		if a.cfg.graphCanvas {
-			// Keep the graph column data-less; the canvas overlay renders the graph.
-			graph = ""
+		opts := []Opt{Id(row.ID), Values(a.commitRowValues(row))}
+		if a.state.filter.search.matches[row.Hash] {
+			opts = append(opts, Tags("searchMatch"))
 		}
-		vals := []string{graph, row.Commit, row.Author, row.Date}
-		a.ui.treeView.Insert("", "end", Id(row.ID), Values(vals))
+		a.ui.treeView.Insert("", "end", opts...)
 	}
 	if a.state.tree.hasMore && len(a.data.visible) > 0 {
 		vals := []string{"", "There are more commits...", "", ""}
 		a.ui.treeView.Insert("", "end", Id(moreIndicatorID), Values(vals))
 	}
+	a.tagMarkedRow()
 
 	if len(a.data.visible) == 0 {
 		if len(a.data.commits) == 0 {
 			a.clearDetailText("Repository has no commits yet.")
+		} else if err := a.state.filter.searchErr; err != nil {
+			a.clearDetailText(fmt.Sprintf("The filter search failed, so no commits match: %v", err))
 		} else {
 			a.clearDetailText("No commits match the current filter.")
 		}
@@ -77,6 +181,16 @@ func (a *Controller) applyFilterContent(raw string) {
 	a.scheduleGraphCanvasDraw()
 }
 
+// commitRowValues returns the cells of the commit list row of row.
+func (a *Controller) commitRowValues(row treeRow) []string {
+	graph := row.Graph
+	if a.cfg.graphCanvas {
+		// Keep the graph column data-less; the canvas overlay renders the graph.
+		graph = ""
+	}
+	return []string{graph, row.Commit, row.Author, row.Date}
+}
+
 func (a *Controller) storeScrollState() {
 	a.state.scroll.total = a.treeChildCount()
 	if a.state.scroll.total > 0 {
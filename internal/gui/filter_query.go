@@ -0,0 +1,320 @@
+package gui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+// queryField is what a filter term matches against.
+type queryField int
+
+const (
+	// fieldText matches bare words against Entry.SearchText.
+	fieldText queryField = iota
+	fieldAuthor
+	fieldCommitter
+	fieldMessage
+	fieldHash
+	fieldSince
+	fieldUntil
+	fieldMerge
+	// fieldPath and fieldContent need the backend; see filterQuery.searches.
+	fieldPath
+	fieldContent
+)
+
+// queryFieldNames are the keys of the fields in messages; fieldText has none.
+var queryFieldNames = []string{
+	fieldText:      "",
+	fieldAuthor:    "author",
+	fieldCommitter: "committer",
+	fieldMessage:   "msg",
+	fieldHash:      "hash",
+	fieldSince:     "since",
+	fieldUntil:     "until",
+	fieldMerge:     "merge",
+	fieldPath:      "path",
+	fieldContent:   "content",
+}
+
+func (f queryField) String() string {
+	return queryFieldNames[f]
+}
+
+var queryFieldKeys = map[string]queryField{
+	"author":    fieldAuthor,
+	"committer": fieldCommitter,
+	"msg":       fieldMessage,
+	"message":   fieldMessage,
+	"hash":      fieldHash,
+	"since":     fieldSince,
+	"until":     fieldUntil,
+	"merge":     fieldMerge,
+	"path":      fieldPath,
+	"content":   fieldContent,
+}
+
+// queryTerm is one condition of a filter query. Exactly one of text, re,
+// when, merge or search is meaningful depending on field.
+type queryTerm struct {
+	field  queryField
+	negate bool
+	text   string
+	re     *regexp.Regexp
+	when   time.Time
+	merge  bool
+	search git.SearchQuery
+}
+
+// filterQuery is a parsed filter such as
+// `author:alice since:2024-01-01 path:internal/git msg:/fix(es)?/ -wip`.
+// All terms must match; a leading "-" negates a term.
+type filterQuery struct {
+	terms []queryTerm
+}
+
+func (q filterQuery) empty() bool {
+	return len(q.terms) == 0
+}
+
+// searches lists the backend searches the query depends on.
+func (q filterQuery) searches() []git.SearchQuery {
+	var out []git.SearchQuery
+	for _, term := range q.terms {
+		if term.field != fieldPath && term.field != fieldContent {
+			continue
+		}
+		if !containsSearch(out, term.search) {
+			out = append(out, term.search)
+		}
+	}
+	return out
+}
+
+func containsSearch(list []git.SearchQuery, q git.SearchQuery) bool {
+	for _, item := range list {
+		if item == q {
+			return true
+		}
+	}
+	return false
+}
+
+// searchError returns the error of the first term whose backend search is in
+// failed, naming the term, or nil.
+func (q filterQuery) searchError(failed map[git.SearchQuery]error) error {
+	for _, term := range q.terms {
+		if err := term.searchError(failed); err != nil {
+			return fmt.Errorf("%s:%s: %w", term.field, term.search.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// match reports whether entry satisfies every term. results holds the
+// matches of the backend searches; terms whose search has not completed yet
+// are treated as matching so the list does not blink empty while waiting.
+// Terms whose search is in failed match nothing, even negated, so a failure
+// never shows commits the query was meant to hide.
+func (q filterQuery) match(entry *git.Entry, results map[git.SearchQuery]map[string]bool, failed map[git.SearchQuery]error) bool {
+	for _, term := range q.terms {
+		if entry == nil || term.searchError(failed) != nil {
+			return false
+		}
+		ok, known := term.matches(entry, results)
+		if known && ok == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// searchError returns the error of the backend search of t in failed, or nil
+// when t needs no search or it did not fail.
+func (t queryTerm) searchError(failed map[git.SearchQuery]error) error {
+	if t.field != fieldPath && t.field != fieldContent {
+		return nil
+	}
+	return failed[t.search]
+}
+
+func (t queryTerm) matches(entry *git.Entry, results map[git.SearchQuery]map[string]bool) (ok bool, known bool) {
+	if t.field == fieldText {
+		return strings.Contains(entry.SearchText, t.text), true
+	}
+	c := entry.Commit
+	if c == nil {
+		return false, true
+	}
+	switch t.field {
+	case fieldAuthor:
+		return t.matchString(fmt.Sprintf("%s <%s>", c.Author.Name, c.Author.Email)), true
+	case fieldCommitter:
+		return t.matchString(fmt.Sprintf("%s <%s>", c.Committer.Name, c.Committer.Email)), true
+	case fieldMessage:
+		return t.matchString(c.Message), true
+	case fieldHash:
+		return strings.HasPrefix(strings.ToLower(c.Hash), t.text), true
+	case fieldSince:
+		return !c.Committer.When.Before(t.when), true
+	case fieldUntil:
+		return c.Committer.When.Before(t.when), true
+	case fieldMerge:
+		return (len(c.ParentHashes) > 1) == t.merge, true
+	case fieldPath, fieldContent:
+		matches, done := results[t.search]
+		return matches[c.Hash], done
+	default:
+		return false, true
+	}
+}
+
+func (t queryTerm) matchString(s string) bool {
+	if t.re != nil {
+		return t.re.MatchString(s)
+	}
+	return strings.Contains(strings.ToLower(s), t.text)
+}
+
+// parseFilterQuery parses the filter bar syntax. Bare words match the hash,
+// author and message like the plain filter; "key:value" terms match single
+// fields, with /regex/ values for author, committer and msg. Values can be
+// double quoted to include spaces. Words with unknown keys are plain words,
+// so searching for "fix:" still works.
+func parseFilterQuery(raw string) (filterQuery, error) {
+	var q filterQuery
+	s := strings.TrimSpace(raw)
+	for s != "" {
+		negate := false
+		if len(s) > 1 && s[0] == '-' && !unicode.IsSpace(rune(s[1])) {
+			negate = true
+			s = s[1:]
+		}
+		field := fieldText
+		if key, rest, ok := strings.Cut(s, ":"); ok && !strings.ContainsFunc(key, unicode.IsSpace) {
+			if f, known := queryFieldKeys[strings.ToLower(key)]; known {
+				field = f
+				s = rest
+			}
+		}
+		value, rest, err := scanQueryValue(s, field)
+		if err != nil {
+			return filterQuery{}, err
+		}
+		s = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		term, err := newQueryTerm(field, value)
+		if err != nil {
+			return filterQuery{}, err
+		}
+		term.negate = negate
+		q.terms = append(q.terms, term)
+	}
+	return q, nil
+}
+
+// scanQueryValue reads one value, which may be quoted or, for fields
+// accepting them, a /regex/. The returned value keeps regex delimiters.
+func scanQueryValue(s string, field queryField) (value string, rest string, err error) {
+	name := queryFieldName(field)
+	switch {
+	case strings.HasPrefix(s, `"`):
+		end := strings.IndexByte(s[1:], '"')
+		if end < 0 {
+			return "", "", fmt.Errorf("%sunterminated quote", name)
+		}
+		return s[1 : end+1], s[end+2:], nil
+	case strings.HasPrefix(s, "/") && acceptsRegexp(field):
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '/':
+				return s[:i+1], s[i+1:], nil
+			}
+		}
+		return "", "", fmt.Errorf("%sunterminated regular expression", name)
+	}
+	end := strings.IndexFunc(s, unicode.IsSpace)
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end], s[end:], nil
+}
+
+func acceptsRegexp(field queryField) bool {
+	return field == fieldAuthor || field == fieldCommitter || field == fieldMessage
+}
+
+// queryFieldName is the "key: " prefix of errors about a term of field.
+func queryFieldName(field queryField) string {
+	if field == fieldText {
+		return ""
+	}
+	return field.String() + ": "
+}
+
+func newQueryTerm(field queryField, value string) (queryTerm, error) {
+	term := queryTerm{field: field}
+	name := queryFieldName(field)
+	if value == "" {
+		if field == fieldText {
+			return term, fmt.Errorf("empty search term")
+		}
+		return term, fmt.Errorf("%smissing value", name)
+	}
+	switch field {
+	case fieldText, fieldHash:
+		term.text = strings.ToLower(value)
+	case fieldAuthor, fieldCommitter, fieldMessage:
+		if len(value) > 1 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+			re, err := regexp.Compile("(?i)" + value[1:len(value)-1])
+			if err != nil {
+				return term, fmt.Errorf("%sinvalid regular expression: %v", name, err)
+			}
+			term.re = re
+		} else {
+			term.text = strings.ToLower(value)
+		}
+	case fieldSince, fieldUntil:
+		when, err := parseQueryDate(value, field == fieldUntil)
+		if err != nil {
+			return term, fmt.Errorf("%s%v", name, err)
+		}
+		term.when = when
+	case fieldMerge:
+		switch strings.ToLower(value) {
+		case "yes", "true":
+			term.merge = true
+		case "no", "false":
+			term.merge = false
+		default:
+			return term, fmt.Errorf("%sexpected yes or no, got %q", name, value)
+		}
+	case fieldPath:
+		term.search = git.SearchQuery{Kind: git.SearchPaths, Pattern: value}
+	case fieldContent:
+		term.search = git.SearchQuery{Kind: git.SearchPickaxe, Pattern: value}
+	}
+	return term, nil
+}
+
+// parseQueryDate accepts RFC 3339 timestamps and local dates. A date used as
+// an upper bound includes the whole day.
+func parseQueryDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("invalid date %q (want YYYY-MM-DD)", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
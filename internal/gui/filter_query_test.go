@@ -0,0 +1,138 @@
+package gui
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func mustParseFilterQuery(t *testing.T, raw string) filterQuery {
+	t.Helper()
+	q, err := parseFilterQuery(raw)
+	if err != nil {
+		t.Fatalf("parseFilterQuery(%q): %v", raw, err)
+	}
+	return q
+}
+
+func TestFilterQueryMatch(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d.Add(12 * time.Hour)
+	}
+	newEntry := func(hash, author, msg string, when time.Time, parents ...string) *git.Entry {
+		c := &git.Commit{
+			Hash:         hash,
+			Author:       git.Signature{Name: author, Email: strings.ToLower(author) + "@example.com"},
+			Committer:    git.Signature{Name: "Bot", Email: "bot@example.com", When: when},
+			Message:      msg,
+			ParentHashes: parents,
+		}
+		return &git.Entry{Commit: c, SearchText: strings.ToLower(hash + " " + author + " " + msg)}
+	}
+	entries := []*git.Entry{
+		newEntry("aaa111", "Alice", "Fix parser\n\nWIP", day("2024-01-10"), "p1"),
+		newEntry("bbb222", "Bob", "Merge branch 'feature'", day("2024-03-01"), "p1", "p2"),
+		newEntry("ccc333", "Alice", "Add tests", day("2024-06-01"), "p1"),
+	}
+	pathSearch := git.SearchQuery{Kind: git.SearchPaths, Pattern: "internal/git"}
+	results := map[git.SearchQuery]map[string]bool{
+		pathSearch: {"aaa111": true, "bbb222": true},
+	}
+	failed := map[git.SearchQuery]error{pathSearch: errors.New("bad pathspec")}
+	tests := []struct {
+		query   string
+		results map[git.SearchQuery]map[string]bool
+		failed  map[git.SearchQuery]error
+		want    []string
+	}{
+		{query: "alice", want: []string{"aaa111", "ccc333"}},
+		{query: "author:ALICE -wip", want: []string{"ccc333"}},
+		{query: "author:/^bob <bob@/", want: []string{"bbb222"}},
+		{query: "committer:bot@example.com", want: []string{"aaa111", "bbb222", "ccc333"}},
+		{query: `msg:"merge branch"`, want: []string{"bbb222"}},
+		{query: "msg:/^(fix|add) /", want: []string{"aaa111", "ccc333"}},
+		{query: "hash:BBB", want: []string{"bbb222"}},
+		{query: "since:2024-03-01", want: []string{"bbb222", "ccc333"}},
+		{query: "until:2024-03-01", want: []string{"aaa111", "bbb222"}},
+		{query: "since:2024-02-01 until:2024-05-31", want: []string{"bbb222"}},
+		{query: "merge:yes", want: []string{"bbb222"}},
+		{query: "-merge:yes", want: []string{"aaa111", "ccc333"}},
+		{query: "merge:no", want: []string{"aaa111", "ccc333"}},
+		{query: "path:internal/git", results: results, want: []string{"aaa111", "bbb222"}},
+		{query: "-path:internal/git", results: results, want: []string{"ccc333"}},
+		// Pending searches do not hide anything.
+		{query: "path:internal/git alice", want: []string{"aaa111", "ccc333"}},
+		// Failed searches match nothing, even negated.
+		{query: "path:internal/git", failed: failed, want: nil},
+		{query: "-path:internal/git", failed: failed, want: nil},
+		{query: "alice", failed: failed, want: []string{"aaa111", "ccc333"}},
+		{query: "fix:", want: nil},
+	}
+	for _, tt := range tests {
+		q := mustParseFilterQuery(t, tt.query)
+		var got []string
+		for _, entry := range filterEntries(entries, q, tt.results, tt.failed) {
+			got = append(got, entry.Commit.Hash)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Fatalf("%q: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestFilterQuerySearches(t *testing.T) {
+	q := mustParseFilterQuery(t, "path:a content:foo -path:a path:b")
+	want := []git.SearchQuery{
+		{Kind: git.SearchPaths, Pattern: "a"},
+		{Kind: git.SearchPickaxe, Pattern: "foo"},
+		{Kind: git.SearchPaths, Pattern: "b"},
+	}
+	if got := q.searches(); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFilterQuerySearchError(t *testing.T) {
+	search := git.SearchQuery{Kind: git.SearchPickaxe, Pattern: "foo"}
+	failed := map[git.SearchQuery]error{search: errors.New("boom")}
+	q := mustParseFilterQuery(t, "alice -content:foo")
+	if err := q.searchError(failed); err == nil || err.Error() != "content:foo: boom" {
+		t.Fatalf("searchError() = %v, want content:foo: boom", err)
+	}
+	if err := q.searchError(nil); err != nil {
+		t.Fatalf("searchError(nil) = %v, want nil", err)
+	}
+	if err := mustParseFilterQuery(t, "path:foo").searchError(failed); err != nil {
+		t.Fatalf("expected no error for a different search, got %v", err)
+	}
+}
+
+func TestParseFilterQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "author:", want: "author: missing value"},
+		{query: "since:yesterday", want: "invalid date"},
+		{query: "msg:/(/", want: "invalid regular expression"},
+		{query: "message:/(/", want: "msg: invalid regular expression"},
+		{query: "content:", want: "content: missing value"},
+		{query: "msg:/open", want: "unterminated regular expression"},
+		{query: `"open`, want: "unterminated quote"},
+		{query: "merge:maybe", want: "expected yes or no"},
+	}
+	for _, tt := range tests {
+		_, err := parseFilterQuery(tt.query)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%q: got error %v, want %q", tt.query, err, tt.want)
+		}
+	}
+}
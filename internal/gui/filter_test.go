@@ -1,10 +1,12 @@
 package gui
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/thiagokokada/gitk-go/internal/debounce"
+	"github.com/thiagokokada/gitk-go/internal/git"
 )
 
 func TestScrollRestoreTarget(t *testing.T) {
@@ -75,3 +77,36 @@ func TestScheduleFilterApplyEmptyStopsDebounce(t *testing.T) {
 		t.Fatalf("expected filter value cleared, got %q", got)
 	}
 }
+
+func TestFilterKeepSearches(t *testing.T) {
+	kept := git.SearchQuery{Kind: git.SearchPaths, Pattern: "internal"}
+	prefix := git.SearchQuery{Kind: git.SearchPaths, Pattern: "int"}
+	running := git.SearchQuery{Kind: git.SearchPickaxe, Pattern: "fo"}
+	var cancelled []string
+	search := func(name string) *filterSearch {
+		return &filterSearch{cancel: func() { cancelled = append(cancelled, name) }}
+	}
+	f := &filterState{
+		pushed:  map[git.SearchQuery]map[string]bool{kept: {"a": true}, prefix: {"b": true}},
+		pushing: map[git.SearchQuery]*filterSearch{running: search("running")},
+		failed:  map[git.SearchQuery]error{prefix: errors.New("boom")},
+	}
+
+	f.keepSearches([]git.SearchQuery{kept})
+
+	if len(cancelled) != 1 || cancelled[0] != "running" {
+		t.Fatalf("cancelled = %v, want [running]", cancelled)
+	}
+	if len(f.pushing) != 0 || len(f.failed) != 0 {
+		t.Fatalf("expected dropped searches to be forgotten, pushing=%v failed=%v", f.pushing, f.failed)
+	}
+	if len(f.pushed) != 1 || !f.pushed[kept]["a"] {
+		t.Fatalf("pushed = %v, want only %v", f.pushed, kept)
+	}
+
+	f.pushing = map[git.SearchQuery]*filterSearch{kept: search("kept")}
+	f.keepSearches([]git.SearchQuery{kept})
+	if len(cancelled) != 1 || f.pushing[kept] == nil {
+		t.Fatalf("expected the search of a kept term to keep running, cancelled=%v", cancelled)
+	}
+}
@@ -0,0 +1,89 @@
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+// promptGoTo asks for a revision to select in the commit list, like gitk's
+// SHA1 ID box.
+func (a *Controller) promptGoTo() {
+	if a.ui.gotoWindow != nil {
+		Destroy(a.ui.gotoWindow.Window)
+		a.ui.gotoWindow = nil
+	}
+
+	dialog := App.Toplevel()
+	a.ui.gotoWindow = dialog
+	dialog.WmTitle("Go to Commit")
+	WmTransient(dialog.Window, App)
+
+	frame := dialog.TFrame(Padding("12p"))
+	Grid(frame, Row(0), Column(0), Sticky(NEWS))
+	GridColumnConfigure(frame.Window, 0, Weight(1))
+
+	label := frame.TLabel(Txt("Hash, ref or revision (e.g. HEAD~40, v1.2^2):"), Anchor(W))
+	Grid(label, Row(0), Column(0), Sticky(W), Pady("0 4p"))
+	entry := frame.TEntry(Width(40), Textvariable(a.state.jump.revision))
+	Grid(entry, Row(1), Column(0), Sticky(WE), Pady("0 8p"))
+
+	submit := func() {
+		rev := strings.TrimSpace(entry.Textvariable())
+		if rev == "" {
+			return
+		}
+		Destroy(dialog.Window)
+		a.goToRevision(rev)
+	}
+
+	buttons := frame.TFrame()
+	Grid(buttons, Row(2), Column(0), Sticky(E))
+	cancelBtn := buttons.TButton(Txt("Cancel"), Command(func() { Destroy(dialog.Window) }))
+	goBtn := buttons.TButton(Txt("Go"), Command(submit))
+	Grid(cancelBtn, Row(0), Column(0), Sticky(E), Padx("0 8p"))
+	Grid(goBtn, Row(0), Column(1), Sticky(E))
+
+	Bind(dialog.Window, "<KeyPress-Escape>", Command(func() { Destroy(dialog.Window) }))
+	Bind(dialog.Window, "<KeyPress-Return>", Command(submit))
+	Bind(dialog.Window, "<Destroy>", Command(func() {
+		if a.ui.gotoWindow == dialog {
+			a.ui.gotoWindow = nil
+		}
+	}))
+	if _, err := tkutil.Eval("focus %s; %s selection range 0 end", entry, entry); err != nil {
+		slog.Debug("focus go to entry", slog.Any("error", err))
+	}
+	dialog.Center()
+}
+
+// goToRevision resolves rev and selects the commit it names.
+func (a *Controller) goToRevision(rev string) {
+	if a.svc == nil {
+		return
+	}
+	a.state.jump.revision = rev
+	a.setStatus(fmt.Sprintf("Resolving %s...", rev))
+	svc := a.svc
+	go func() {
+		hash, err := svc.ResolveRevision(rev)
+		PostEvent(func() {
+			if svc != a.svc {
+				return
+			}
+			if err != nil {
+				a.setStatus(fmt.Sprintf("Unable to resolve %s: %v", rev, err))
+				return
+			}
+			label := rev
+			if !strings.HasPrefix(hash, rev) {
+				label = fmt.Sprintf("%s (%s)", rev, shortHash(hash))
+			}
+			a.jumpToCommit(hash, label)
+		}, false)
+	}()
+}
@@ -24,6 +24,7 @@ func (a *Controller) drawGraphCanvas() {
 	a.state.tree.graphCanvas.Draw(widgets.GraphCanvasDrawInput{
 		Visible: a.data.visible,
 		Labels:  a.state.tree.branchLabels,
+		Marked:  a.state.tree.marked,
 		Dark:    a.theme.palette.isDark(),
 	})
 }
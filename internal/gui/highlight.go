@@ -22,6 +22,7 @@ func (a *Controller) applySyntaxHighlight(content string) {
 	}
 	lines := strings.Split(content, "\n")
 	var currentLexer chroma.Lexer
+	cols := 1
 	for i, line := range lines {
 		lineNo := i + 1
 		if path, ok := diffPathFromLine(line); ok {
@@ -31,23 +32,54 @@ func (a *Controller) applySyntaxHighlight(content string) {
 			}
 			continue
 		}
-		if strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "@@") {
+		if n, ok := diffHunkColumns(line); ok {
+			cols = n
+			continue
+		}
+		if strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
 			continue
 		}
 		if currentLexer == nil {
 			continue
 		}
-		code, offset, ok := diffLineCode(line)
+		code, offset, ok := diffLineCode(line, cols)
 		if !ok {
 			continue
 		}
-		a.highlightCodeLine(currentLexer, style, code, lineNo, offset)
+		a.highlightCodeLine(a.ui.diffDetail, currentLexer, style, code, lineNo, offset)
+	}
+}
+
+// applySplitSyntaxHighlight highlights the code cells of the side-by-side view.
+func (a *Controller) applySplitSyntaxHighlight(split sideBySideDiff) {
+	style := styleForPalette(a.theme.palette)
+	if style == nil {
+		return
+	}
+	lexers := map[string]chroma.Lexer{}
+	for i, row := range split.rows {
+		if row.path == "" {
+			continue
+		}
+		lexer, ok := lexers[row.path]
+		if !ok {
+			lexer = lexerForPath(row.path)
+			lexers[row.path] = lexer
+		}
+		if row.left.code {
+			a.highlightCodeLine(a.ui.diffLeft, lexer, style, row.left.text, i+1, 0)
+		}
+		if row.right.code {
+			a.highlightCodeLine(a.ui.diffRight, lexer, style, row.right.text, i+1, 0)
+		}
 	}
 }
 
 func (a *Controller) clearSyntaxHighlight() {
 	for _, tag := range a.state.diff.syntaxTags {
-		a.ui.diffDetail.TagRemove(tag, "1.0", END)
+		for _, text := range a.diffTexts() {
+			text.TagRemove(tag, "1.0", END)
+		}
 	}
 }
 
@@ -62,12 +94,14 @@ func (a *Controller) syntaxTagForColor(color string) string {
 		return tag
 	}
 	tag := fmt.Sprintf("syntax_%d", len(a.state.diff.syntaxTags))
-	a.ui.diffDetail.TagConfigure(tag, Foreground(color))
+	for _, text := range a.diffTexts() {
+		text.TagConfigure(tag, Foreground(color))
+	}
 	a.state.diff.syntaxTags[color] = tag
 	return tag
 }
 
-func (a *Controller) highlightCodeLine(lexer chroma.Lexer, style *chroma.Style, code string, lineNo, offset int) {
+func (a *Controller) highlightCodeLine(text *TextWidget, lexer chroma.Lexer, style *chroma.Style, code string, lineNo, offset int) {
 	if lexer == nil || style == nil || code == "" {
 		return
 	}
@@ -89,7 +123,7 @@ func (a *Controller) highlightCodeLine(lexer chroma.Lexer, style *chroma.Style,
 			if tag != "" {
 				start := fmt.Sprintf("%d.%d", lineNo, col)
 				end := fmt.Sprintf("%d.%d", lineNo, col+length)
-				a.ui.diffDetail.TagAdd(tag, start, end)
+				text.TagAdd(tag, start, end)
 			}
 		}
 		col += length
@@ -0,0 +1,44 @@
+package gui
+
+import (
+	. "modernc.org/tk9.0"
+)
+
+// navigateHistory selects the previous (dir < 0) or next (dir > 0) commit of
+// the selection history, skipping commits that are no longer in the view.
+func (a *Controller) navigateHistory(dir int) {
+	present := func(hash string) bool {
+		if entryIndex(a.data.visible, hash) >= 0 {
+			return true
+		}
+		// Commits not loaded yet may still be further down the history.
+		return a.state.tree.hasMore && entryIndex(a.data.commits, hash) < 0
+	}
+	var hash string
+	var ok bool
+	if dir < 0 {
+		hash, ok = a.state.history.Back(present)
+	} else {
+		hash, ok = a.state.history.Forward(present)
+	}
+	a.updateHistoryButtons()
+	if !ok {
+		return
+	}
+	a.jumpToCommit(hash, shortHash(hash))
+}
+
+func (a *Controller) updateHistoryButtons() {
+	set := func(button *TButtonWidget, enabled bool) {
+		if button == nil {
+			return
+		}
+		state := "disabled"
+		if enabled {
+			state = "normal"
+		}
+		button.Configure(State(state))
+	}
+	set(a.ui.backButton, a.state.history.CanBack())
+	set(a.ui.forwardButton, a.state.history.CanForward())
+}
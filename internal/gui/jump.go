@@ -0,0 +1,112 @@
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+// entryIndex returns the position of the commit with hash in entries, or -1.
+func entryIndex(entries []*git.Entry, hash string) int {
+	return slices.IndexFunc(entries, func(entry *git.Entry) bool {
+		return entry != nil && entry.Commit != nil && entry.Commit.Hash == hash
+	})
+}
+
+// jumpToCommit selects the commit with hash in the commit list. When it is
+// not loaded yet, it first asks git whether the current view reaches it and
+// only then loads more commits until it shows up. label names the commit in
+// status messages.
+func (a *Controller) jumpToCommit(hash, label string) {
+	a.state.jump.hash, a.state.jump.label = "", ""
+	a.state.jump.seq++
+	if hash == "" || a.showLoadedCommit(hash, label) {
+		return
+	}
+	if a.svc == nil || (!a.state.tree.hasMore && !a.state.tree.loadingBatch) {
+		a.setStatus(fmt.Sprintf("%s is not reachable from the current view.", label))
+		return
+	}
+	svc, spec, seq := a.svc, a.cfg.logSpec, a.state.jump.seq
+	a.setStatus(fmt.Sprintf("Looking for %s in the current view...", label))
+	go func() {
+		ok, err := svc.LogContains(spec, hash)
+		PostEvent(func() {
+			if svc != a.svc || !spec.Equal(a.cfg.logSpec) || seq != a.state.jump.seq {
+				// Another jump started or the view changed meanwhile.
+				return
+			}
+			if err != nil {
+				slog.Error("check commit reachability", slog.String("commit", hash), slog.Any("error", err))
+				a.setStatus(fmt.Sprintf("Unable to look for %s: %v", label, err))
+				return
+			}
+			if !ok {
+				a.setStatus(fmt.Sprintf("%s is not reachable from the current view.", label))
+				return
+			}
+			a.state.jump.hash, a.state.jump.label = hash, label
+			a.continuePendingJump()
+		}, false)
+	}()
+}
+
+// showLoadedCommit selects the commit with hash if it is loaded, or reports
+// that the filter hides it. It returns false when the commit is not loaded.
+func (a *Controller) showLoadedCommit(hash, label string) bool {
+	if idx := entryIndex(a.data.visible, hash); idx >= 0 {
+		a.selectTreeIndex(idx)
+		a.centerTreeRow(strconv.Itoa(idx))
+		a.setStatus(a.statusSummary())
+		return true
+	}
+	if entryIndex(a.data.commits, hash) >= 0 {
+		a.setStatus(fmt.Sprintf("%s is hidden by the current filter.", label))
+		return true
+	}
+	return false
+}
+
+// continuePendingJump loads more commits until the commit of a jumpToCommit,
+// already known to be in the view, shows up.
+func (a *Controller) continuePendingJump() {
+	hash, label := a.state.jump.hash, a.state.jump.label
+	if hash == "" {
+		return
+	}
+	if a.showLoadedCommit(hash, label) {
+		a.state.jump.hash, a.state.jump.label = "", ""
+		return
+	}
+	if !a.state.tree.hasMore && !a.state.tree.loadingBatch {
+		a.state.jump.hash, a.state.jump.label = "", ""
+		a.setStatus(fmt.Sprintf("%s is not reachable from the current view.", label))
+		return
+	}
+	a.setStatus(fmt.Sprintf("Loading more commits to reach %s...", label))
+	a.loadMoreCommitsAsync(false)
+}
+
+// centerTreeRow scrolls the commit list so that the row id is in the middle.
+func (a *Controller) centerTreeRow(id string) {
+	start, end, err := a.treeYviewRange()
+	if err != nil {
+		slog.Debug("center tree row", slog.Any("error", err))
+		return
+	}
+	total := len(a.ui.treeView.Children(""))
+	if total == 0 || end-start >= 1 {
+		return
+	}
+	pos := tkutil.Atoi(tkutil.EvalOrEmpty("%s index %s", a.ui.treeView, id))
+	top := (float64(pos)+0.5)/float64(total) - (end-start)/2
+	if _, err := tkutil.Eval("%s yview moveto %f", a.ui.treeView, max(0, top)); err != nil {
+		slog.Debug("center tree row", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,189 @@
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/config"
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+// treeColumns are the commit list columns in their default order.
+var treeColumns = []string{"graph", "commit", "author", "date"}
+
+var geometryRE = regexp.MustCompile(`^(\d+)x(\d+)([+-]-?\d+)([+-]-?\d+)$`)
+
+// loadLayout reads the layout saved by the previous session.
+func (a *Controller) loadLayout() {
+	path, err := config.LayoutPath()
+	if err != nil {
+		return
+	}
+	layout, err := config.LoadLayout(path)
+	if err != nil {
+		slog.Warn("load window layout", slog.Any("error", err))
+		return
+	}
+	a.cfg.layout = *layout
+}
+
+// saveLayout records the window geometry, sash positions and commit list
+// columns for the next session.
+func (a *Controller) saveLayout() {
+	path, err := config.LayoutPath()
+	if err != nil {
+		return
+	}
+	layout := a.captureLayout()
+	if err := layout.Save(path); err != nil {
+		slog.Error("save window layout", slog.Any("error", err))
+	}
+}
+
+func (a *Controller) captureLayout() config.Layout {
+	layout := config.Layout{Geometry: WmGeometry(App)}
+	layout.HideRefs = a.state.refs.hidden
+	layout.RefsSash = a.state.refs.sash
+	if a.ui.refsPane != nil && !a.state.refs.hidden {
+		layout.RefsSash = tkutil.Atoi(tkutil.EvalOrEmpty("%s sashpos 0", a.ui.refsPane))
+	}
+	if a.ui.mainPane != nil {
+		layout.MainSash = tkutil.Atoi(tkutil.EvalOrEmpty("%s sashpos 0", a.ui.mainPane))
+	}
+	if a.ui.diffPane != nil {
+		layout.DiffSash = tkutil.Atoi(tkutil.EvalOrEmpty("%s sashpos 0", a.ui.diffPane))
+	}
+	if a.ui.treeView == nil {
+		return layout
+	}
+	order := strings.Fields(tkutil.EvalOrEmpty("%s cget -displaycolumns", a.ui.treeView))
+	if len(order) == 0 || order[0] == "#all" {
+		order = treeColumns
+	}
+	for _, id := range order {
+		width := tkutil.Atoi(tkutil.EvalOrEmpty("%s column %s -width", a.ui.treeView, id))
+		layout.Columns = append(layout.Columns, config.ColumnLayout{ID: id, Width: width})
+	}
+	return layout
+}
+
+// quit saves the layout before closing the main window.
+func (a *Controller) quit() {
+	a.saveLayout()
+	Destroy(App)
+}
+
+// applyColumnLayout restores the saved commit list column widths and order.
+func (a *Controller) applyColumnLayout() {
+	saved := a.cfg.layout.Columns
+	if len(saved) == 0 {
+		return
+	}
+	var ids []string
+	for _, col := range saved {
+		if !slices.Contains(treeColumns, col.ID) {
+			continue
+		}
+		ids = append(ids, col.ID)
+		if col.Width > 0 {
+			a.ui.treeView.Column(col.ID, Width(col.Width))
+		}
+	}
+	order := columnOrder(ids, a.cfg.graphCanvas)
+	if _, err := tkutil.Eval("%s configure -displaycolumns {%s}", a.ui.treeView, strings.Join(order, " ")); err != nil {
+		slog.Debug("restore column order", slog.Any("error", err))
+	}
+}
+
+// columnOrder returns the known columns in the saved order, followed by
+// those missing from it. The canvas graph overlay is drawn over the leftmost
+// column, so graphFirst keeps the graph there.
+func columnOrder(saved []string, graphFirst bool) []string {
+	var order []string
+	for _, id := range saved {
+		if slices.Contains(treeColumns, id) && !slices.Contains(order, id) {
+			order = append(order, id)
+		}
+	}
+	for _, id := range treeColumns {
+		if !slices.Contains(order, id) {
+			order = append(order, id)
+		}
+	}
+	if graphFirst {
+		idx := slices.Index(order, "graph")
+		order = slices.Insert(slices.Delete(order, idx, idx+1), 0, "graph")
+	}
+	return order
+}
+
+// fitGeometry clamps a saved "WxH+X+Y" geometry to the screen. The position
+// is dropped when it would put the window off screen, e.g. after a monitor
+// was disconnected.
+func fitGeometry(geometry string, screenW, screenH int) (string, bool) {
+	m := geometryRE.FindStringSubmatch(strings.TrimSpace(geometry))
+	if m == nil {
+		return "", false
+	}
+	w, _ := strconv.Atoi(m[1])
+	h, _ := strconv.Atoi(m[2])
+	x, _ := strconv.Atoi(strings.TrimPrefix(m[3], "+"))
+	y, _ := strconv.Atoi(strings.TrimPrefix(m[4], "+"))
+	if w <= 1 || h <= 1 {
+		return "", false
+	}
+	if screenW > 0 && screenH > 0 {
+		w, h = min(w, screenW), min(h, screenH)
+		if x < 0 || y < 0 || x+w > screenW || y+h > screenH {
+			return fmt.Sprintf("%dx%d", w, h), true
+		}
+	}
+	return fmt.Sprintf("%dx%d+%d+%d", w, h, x, y), true
+}
+
+// restoreGeometry applies the saved window geometry and reports whether it
+// included a position.
+func (a *Controller) restoreGeometry() bool {
+	screenW := tkutil.Atoi(tkutil.EvalOrEmpty("winfo vrootwidth ."))
+	screenH := tkutil.Atoi(tkutil.EvalOrEmpty("winfo vrootheight ."))
+	geometry, ok := fitGeometry(a.cfg.layout.Geometry, screenW, screenH)
+	if !ok {
+		return false
+	}
+	WmGeometry(App, geometry)
+	return strings.Contains(geometry, "+")
+}
+
+// setInitialSash moves the first sash of pane to pos once the pane has been
+// laid out, falling back to fallback (a Tcl expression of $size, the pane
+// extent) when pos does not fit. An empty fallback keeps Tk's placement.
+func setInitialSash(pane *TPanedwindowWidget, vertical bool, pos int, fallback string) {
+	dim := "width"
+	if vertical {
+		dim = "height"
+	}
+	if fallback == "" {
+		fallback = "-1"
+	}
+	tkutil.MustEval(`
+		bind %[1]s <Configure> {
+			set size [winfo %[2]s %[1]s]
+			if {$size > 1} {
+				set pos %[3]d
+				if {$pos <= 0 || $pos >= $size} {
+					set pos [expr {%[4]s}]
+				}
+				if {$pos > 0} {
+					%[1]s sashpos 0 $pos
+				}
+				bind %[1]s <Configure> {}
+			}
+		}
+	`, pane, dim, pos, fallback)
+}
@@ -0,0 +1,48 @@
+package gui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestColumnOrder(t *testing.T) {
+	tests := []struct {
+		saved      []string
+		graphFirst bool
+		want       []string
+	}{
+		{saved: nil, want: []string{"graph", "commit", "author", "date"}},
+		{saved: []string{"date", "commit", "bogus", "date"}, want: []string{"date", "commit", "graph", "author"}},
+		{saved: []string{"commit", "graph", "author", "date"}, graphFirst: true, want: []string{"graph", "commit", "author", "date"}},
+	}
+	for _, tt := range tests {
+		if got := columnOrder(tt.saved, tt.graphFirst); !slices.Equal(got, tt.want) {
+			t.Fatalf("columnOrder(%q, %v) = %q, want %q", tt.saved, tt.graphFirst, got, tt.want)
+		}
+	}
+}
+
+func TestFitGeometry(t *testing.T) {
+	tests := []struct {
+		geometry string
+		screenW  int
+		screenH  int
+		want     string
+		ok       bool
+	}{
+		{geometry: "1600x1000+10+20", screenW: 2560, screenH: 1440, want: "1600x1000+10+20", ok: true},
+		{geometry: "1600x1000+3000+20", screenW: 2560, screenH: 1440, want: "1600x1000", ok: true},
+		{geometry: "3000x1000+0+0", screenW: 2560, screenH: 1440, want: "2560x1000+0+0", ok: true},
+		{geometry: "1600x1000+-5+0", screenW: 2560, screenH: 1440, want: "1600x1000", ok: true},
+		{geometry: "800x600+10+10", want: "800x600+10+10", ok: true},
+		{geometry: "1x1+0+0", screenW: 2560, screenH: 1440},
+		{geometry: "", screenW: 2560, screenH: 1440},
+		{geometry: "garbage", screenW: 2560, screenH: 1440},
+	}
+	for _, tt := range tests {
+		got, ok := fitGeometry(tt.geometry, tt.screenW, tt.screenH)
+		if got != tt.want || ok != tt.ok {
+			t.Fatalf("fitGeometry(%q) = %q, %v, want %q, %v", tt.geometry, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+// localPatchTarget is a file of the local diff, and one of its hunks when
+// hunk is not -1.
+type localPatchTarget struct {
+	file   git.PatchFile
+	hunk   int
+	staged bool
+	ok     bool
+}
+
+// localPatchTargetAt returns the file and hunk of the local diff content
+// holding the 1-based line.
+func localPatchTargetAt(content string, line int, staged bool) localPatchTarget {
+	files := git.ParsePatch(content)
+	idx := git.PatchFileAt(files, line)
+	if idx < 0 {
+		return localPatchTarget{hunk: -1}
+	}
+	file := files[idx]
+	return localPatchTarget{file: file, hunk: file.HunkAt(line), staged: staged, ok: true}
+}
+
+// localPatchItems reports which patch entries of the diff context menu apply
+// to target. Changes in the index can only be unstaged, those of the working
+// tree staged or discarded. patchable is false when the diff options make
+// patches that cannot be applied.
+func localPatchItems(target localPatchTarget, patchable bool) map[string]bool {
+	file := target.ok && patchable && !target.file.Binary
+	hunk := file && target.hunk >= 0
+	return map[string]bool{
+		"stageHunk":   hunk && !target.staged,
+		"stageFile":   file && !target.staged,
+		"unstageHunk": hunk && target.staged,
+		"unstageFile": file && target.staged,
+		"discardHunk": hunk && !target.staged,
+		"discardFile": file && !target.staged,
+	}
+}
+
+// updateDiffPatchItems enables the stage, unstage and discard entries of the
+// diff context menu for the local changes under the pointer in text.
+func (a *Controller) updateDiffPatchItems(text *TextWidget, e *Event) {
+	diff := &a.state.diff
+	diff.patch = localPatchTarget{hunk: -1}
+	var line int
+	if staged, ok := a.state.selection.Local(); ok && diff.highlight {
+		if _, err := fmt.Sscanf(text.Index(fmt.Sprintf("@%d,%d", e.X, e.Y)), "%d.", &line); err == nil {
+			if diff.splitShown {
+				line = diff.split.unifiedLine(line)
+			}
+			diff.patch = localPatchTargetAt(diff.content, line, staged)
+		}
+	}
+	menu := a.ui.diffContextMenu
+	for key, enabled := range localPatchItems(diff.patch, git.PatchableDiff(diff.options)) {
+		state := "disabled"
+		if enabled {
+			state = "normal"
+		}
+		menu.EntryConfigure(a.ui.diffMenuItems[key], State(state))
+	}
+}
+
+// applyContextPatch applies the hunk under the diff context menu, or its
+// whole file, as action does, then reloads the local changes.
+func (a *Controller) applyContextPatch(action git.PatchAction, wholeFile bool) {
+	target := a.state.diff.patch
+	if a.svc == nil || !target.ok {
+		return
+	}
+	patch, what := target.file.Patch(), target.file.Path
+	if !wholeFile {
+		patch, what = target.file.HunkPatch(target.hunk), fmt.Sprintf("a hunk of %s", target.file.Path)
+	}
+	if patch == "" {
+		return
+	}
+	var done, failed string
+	switch action {
+	case git.PatchStage:
+		done, failed = fmt.Sprintf("Staged %s.", what), "Unable to stage the changes"
+	case git.PatchUnstage:
+		done, failed = fmt.Sprintf("Unstaged %s.", what), "Unable to unstage the changes"
+	case git.PatchDiscard:
+		if !a.confirm("Discard Changes", fmt.Sprintf("Discard the changes to %s?\n\nThis cannot be undone.", what)) {
+			return
+		}
+		done, failed = fmt.Sprintf("Discarded the changes to %s.", what), "Unable to discard the changes"
+	}
+	svc, opts := a.svc, a.state.diff.options
+	go func() {
+		err := svc.ApplyPatch(patch, action, opts)
+		PostEvent(func() {
+			if svc != a.svc {
+				return
+			}
+			if err != nil {
+				MessageBox(
+					Parent(App),
+					Title("Local Changes"),
+					Icon("error"),
+					Msg(fmt.Sprintf("%s:\n\n%v", failed, err)),
+					Type("ok"),
+				)
+				a.setStatus(fmt.Sprintf("%s: %v", failed, err))
+				return
+			}
+			a.reloadLocalChanges()
+			a.setStatus(done)
+		}, false)
+	}()
+}
+
+// reloadLocalChanges drops both local diffs and reloads them, keeping the
+// view of the one shown.
+func (a *Controller) reloadLocalChanges() {
+	text := a.ui.diffDetail
+	if a.state.diff.splitShown {
+		text = a.ui.diffLeft
+	}
+	if view := strings.Fields(tkutil.EvalOrEmpty("%s yview", text)); len(view) > 0 {
+		a.state.diff.scroll = view[0]
+	}
+	a.resetLocalDiffState(false)
+	a.resetLocalDiffState(true)
+	a.refreshLocalChangesAsync(true)
+}
+
+// restoreLocalDiffScroll scrolls the local diff back to the view saved by
+// reloadLocalChanges.
+func (a *Controller) restoreLocalDiffScroll() {
+	top := a.state.diff.scroll
+	if top == "" {
+		return
+	}
+	a.state.diff.scroll = ""
+	for _, text := range a.diffTexts() {
+		if _, err := tkutil.Eval("%s yview moveto %s", text, top); err != nil {
+			slog.Debug("restore local diff view", slog.Any("error", err))
+		}
+	}
+}
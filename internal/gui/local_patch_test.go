@@ -0,0 +1,74 @@
+package gui
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestLocalPatchTargetAt(t *testing.T) {
+	content := strings.Join([]string{
+		localUnstagedLabel,
+		"diff --git a/a.txt b/a.txt",
+		"--- a/a.txt",
+		"+++ b/a.txt",
+		"@@ -1 +1 @@",
+		"-one",
+		"+ONE",
+		"",
+		"diff --git a/bin.dat b/bin.dat",
+		"Binary files a/bin.dat and b/bin.dat differ",
+	}, "\n")
+	if got := localPatchTargetAt(content, 1, false); got.ok || got.hunk != -1 {
+		t.Fatalf("expected no target on the header, got %+v", got)
+	}
+	got := localPatchTargetAt(content, 6, true)
+	if !got.ok || got.file.Path != "a.txt" || got.hunk != 0 || !got.staged {
+		t.Fatalf("unexpected target in the hunk: %+v", got)
+	}
+	if got := localPatchTargetAt(content, 3, false); !got.ok || got.hunk != -1 {
+		t.Fatalf("expected the file without a hunk on its header, got %+v", got)
+	}
+	if got := localPatchTargetAt(content, 10, false); !got.ok || !got.file.Binary {
+		t.Fatalf("expected the binary file, got %+v", got)
+	}
+}
+
+func TestLocalPatchItems(t *testing.T) {
+	content := "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-one\n+ONE\n"
+	hunk := localPatchTargetAt(content, 5, false)
+	none := map[string]bool{
+		"stageHunk": false, "stageFile": false,
+		"unstageHunk": false, "unstageFile": false,
+		"discardHunk": false, "discardFile": false,
+	}
+	with := func(keys ...string) map[string]bool {
+		out := maps.Clone(none)
+		for _, key := range keys {
+			out[key] = true
+		}
+		return out
+	}
+	staged := hunk
+	staged.staged = true
+	header := localPatchTargetAt(content, 1, false)
+	binary := localPatchTargetAt("diff --git a/b b/b\nBinary files a/b and b/b differ\n", 1, false)
+	tests := []struct {
+		name      string
+		target    localPatchTarget
+		patchable bool
+		want      map[string]bool
+	}{
+		{"unstaged hunk", hunk, true, with("stageHunk", "stageFile", "discardHunk", "discardFile")},
+		{"staged hunk", staged, true, with("unstageHunk", "unstageFile")},
+		{"file header", header, true, with("stageFile", "discardFile")},
+		{"ignoring whitespace", hunk, false, none},
+		{"binary", binary, true, none},
+		{"no target", localPatchTarget{hunk: -1}, true, none},
+	}
+	for _, tt := range tests {
+		if got := localPatchItems(tt.target, tt.patchable); !maps.Equal(got, tt.want) {
+			t.Fatalf("%s: localPatchItems() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -17,18 +17,33 @@ func (a *Controller) initMenubar() {
 
 	openAccel := "Ctrl+O"
 	branchAccel := "Ctrl+B"
+	sideBySideAccel := "Ctrl+D"
+	gotoAccel := "Ctrl+G"
 	if runtime.GOOS == "darwin" {
 		openAccel = "Cmd+O"
 		branchAccel = "Cmd+B"
+		sideBySideAccel = "Cmd+D"
+		gotoAccel = "Cmd+G"
 	}
 
 	fileMenu := menubar.Menu(Tearoff(false))
 	fileMenu.AddCommand(Lbl("Open Repository..."), Accelerator(openAccel), Command(a.promptRepositorySwitch))
 	fileMenu.AddCommand(Lbl("Switch Branch..."), Accelerator(branchAccel), Command(a.promptBranchSwitch))
 	fileMenu.AddSeparator()
-	fileMenu.AddCommand(Lbl("Quit"), Command(func() { Destroy(App) }))
+	fileMenu.AddCommand(Lbl("Settings..."), Command(a.showSettingsDialog))
+	fileMenu.AddSeparator()
+	fileMenu.AddCommand(Lbl("Quit"), Command(a.quit))
 	menubar.AddCascade(Lbl("File"), Mnu(fileMenu))
 
+	viewMenu := menubar.Menu(Tearoff(false))
+	viewMenu.AddCommand(Lbl("Go to Commit..."), Accelerator(gotoAccel), Command(a.promptGoTo))
+	viewMenu.AddSeparator()
+	a.ui.sideBySideItem = viewMenu.AddCommand(Accelerator(sideBySideAccel), Command(a.toggleSideBySide))
+	a.ui.refsToggleItem = viewMenu.AddCommand(Command(a.toggleRefsSidebar))
+	a.ui.viewMenu = viewMenu
+	a.updateSideBySideMenuLabel()
+	menubar.AddCascade(Lbl("View"), Mnu(viewMenu))
+
 	helpMenu := menubar.Menu(Tearoff(false))
 	helpMenu.AddCommand(Lbl("Keyboard Shortcuts"), Command(a.showShortcutsDialog))
 	helpMenu.AddCommand(Lbl("About gitk-go"), Command(a.showAboutDialog))
@@ -37,6 +52,17 @@ func (a *Controller) initMenubar() {
 	App.Configure(Mnu(menubar))
 }
 
+func (a *Controller) updateSideBySideMenuLabel() {
+	if a.ui.viewMenu == nil {
+		return
+	}
+	label := "Side-by-Side Diff"
+	if a.state.diff.sideBySide {
+		label = "Unified Diff"
+	}
+	a.ui.viewMenu.EntryConfigure(a.ui.sideBySideItem, Lbl(label))
+}
+
 func (a *Controller) promptRepositorySwitch() {
 	dir := strings.TrimSpace(ChooseDirectory(
 		Parent(App),
@@ -70,7 +96,7 @@ func (*Controller) showAboutDialog() {
 }
 
 func (a *Controller) switchRepository(path string) {
-	newSvc, err := git.Open(path)
+	newSvc, err := git.OpenBackend(path, a.cfg.backend)
 	if err != nil {
 		MessageBox(
 			Parent(App),
@@ -91,21 +117,36 @@ func (a *Controller) switchRepository(path string) {
 	a.cancelPendingDiffLoad()
 
 	a.svc = newSvc
+	// Revisions and paths given on the command line belong to the previous repository.
+	a.cfg.logSpec = git.LogSpec{}
+	a.state.fileHistory = fileHistoryState{}
+	a.updateFileHistoryBanner()
 	a.repo.path = newSvc.RepoPath()
 	a.repo.headRef = ""
 	a.data.commits = nil
+	a.data.index = nil
 	a.data.visible = nil
 	a.state.tree = treeState{}
 	a.state.localDiff = localDiffCache{}
-	a.state.filter = filterState{}
+	// Stop the searches of the previous repository and keep the generation
+	// increasing so their results are discarded.
+	a.clearSearch()
+	a.resetFilterSearches()
+	a.state.filter = filterState{
+		search: searchState{generation: a.state.filter.search.generation + 1},
+	}
 	a.state.selection = selection.State{}
+	a.state.history.Clear()
+	a.updateHistoryButtons()
 	a.stopFilterDebounce()
 	if a.ui.filterEntry != nil {
 		a.ui.filterEntry.Configure(Textvariable(""))
 	}
-	if a.ui.diffFileList != nil {
-		a.ui.diffFileList.Delete(0, END)
+	if a.ui.filterMode != nil {
+		a.ui.filterMode.Configure(Textvariable(filterModeText.String()))
 	}
+	a.clearFileList()
+	a.resetFileTree()
 
 	a.setLocalRowVisibility(false, false)
 	a.setLocalRowVisibility(true, false)
@@ -0,0 +1,315 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+// changedLabelHashes returns the commits whose ref labels differ between
+// old and labels, sorted.
+func changedLabelHashes(old, labels map[string][]string) []string {
+	var changed []string
+	for hash, names := range labels {
+		if !slices.Equal(old[hash], names) {
+			changed = append(changed, hash)
+		}
+	}
+	for hash := range old {
+		if _, ok := labels[hash]; !ok {
+			changed = append(changed, hash)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// refDialog is a small form asking for a ref name.
+type refDialog struct {
+	window *ToplevelWidget
+	frame  *TFrameWidget
+	entry  *TEntryWidget
+	// row is the next free row of frame.
+	row int
+}
+
+// newRefDialog opens a dialog titled title asking for a name, prefilled with
+// initial. The caller adds its own fields and then calls finish.
+func (a *Controller) newRefDialog(title, prompt, initial string) *refDialog {
+	if a.ui.refWindow != nil {
+		Destroy(a.ui.refWindow.Window)
+		a.ui.refWindow = nil
+	}
+	dialog := App.Toplevel()
+	a.ui.refWindow = dialog
+	dialog.WmTitle(title)
+	WmTransient(dialog.Window, App)
+
+	frame := dialog.TFrame(Padding("12p"))
+	Grid(frame, Row(0), Column(0), Sticky(NEWS))
+	GridColumnConfigure(frame.Window, 0, Weight(1))
+	Grid(frame.TLabel(Txt(prompt), Anchor(W)), Row(0), Column(0), Sticky(W), Pady("0 4p"))
+	entry := frame.TEntry(Width(40), Textvariable(initial))
+	Grid(entry, Row(1), Column(0), Sticky(WE), Pady("0 8p"))
+
+	Bind(dialog.Window, "<KeyPress-Escape>", Command(func() { Destroy(dialog.Window) }))
+	Bind(dialog.Window, "<Destroy>", Command(func() {
+		if a.ui.refWindow == dialog {
+			a.ui.refWindow = nil
+		}
+	}))
+	return &refDialog{window: dialog, frame: frame, entry: entry, row: 2}
+}
+
+// finish adds the buttons, calling submit with the entered name when it is
+// not empty.
+func (d *refDialog) finish(action string, submit func(name string)) {
+	run := func() {
+		name := strings.TrimSpace(d.entry.Textvariable())
+		if name == "" {
+			return
+		}
+		submit(name)
+		Destroy(d.window.Window)
+	}
+	buttons := d.frame.TFrame()
+	Grid(buttons, Row(d.row), Column(0), Sticky(E))
+	Grid(buttons.TButton(Txt("Cancel"), Command(func() { Destroy(d.window.Window) })), Row(0), Column(0), Sticky(E), Padx("0 8p"))
+	Grid(buttons.TButton(Txt(action), Command(run)), Row(0), Column(1), Sticky(E))
+	Bind(d.entry, "<KeyPress-Return>", Command(run))
+	if _, err := tkutil.Eval("focus %s; %s selection range 0 end", d.entry, d.entry); err != nil {
+		slog.Debug("focus ref name entry", slog.Any("error", err))
+	}
+	d.window.Center()
+}
+
+// promptCreateBranch asks for the name of a branch to create at commit,
+// offering to check it out, which checkout preselects.
+func (a *Controller) promptCreateBranch(commit string, checkout bool) {
+	if a.svc == nil || commit == "" {
+		return
+	}
+	d := a.newRefDialog("Create Branch", fmt.Sprintf("New branch at %s:", shortHash(commit)), "")
+	checkoutButton := d.frame.TCheckbutton(Txt("Check out the new branch"), Variable(checkout))
+	Grid(checkoutButton, Row(d.row), Column(0), Sticky(W), Pady("0 8p"))
+	d.row++
+	d.finish("Create", func(name string) {
+		a.createBranchAsync(name, commit, checkoutButton.Variable() == "1")
+	})
+}
+
+// promptCreateTag asks for the name and optional message of a tag to create
+// at commit.
+func (a *Controller) promptCreateTag(commit string) {
+	if a.svc == nil || commit == "" {
+		return
+	}
+	d := a.newRefDialog("Create Tag", fmt.Sprintf("New tag at %s:", shortHash(commit)), "")
+	Grid(d.frame.TLabel(Txt("Message (leave empty for a lightweight tag):"), Anchor(W)), Row(d.row), Column(0), Sticky(W), Pady("0 4p"))
+	message := d.frame.Text(Height(5), Width(40), Wrap("word"))
+	Grid(message, Row(d.row+1), Column(0), Sticky(NEWS), Pady("0 8p"))
+	d.row += 2
+	d.finish("Create", func(name string) {
+		a.createTagAsync(name, commit, tkutil.EvalOrEmpty("%s get 1.0 {end - 1 chars}", message))
+	})
+}
+
+func (a *Controller) promptRenameBranch(branch string) {
+	if a.svc == nil || branch == "" {
+		return
+	}
+	d := a.newRefDialog("Rename Branch", fmt.Sprintf("New name for %s:", branch), branch)
+	d.finish("Rename", func(name string) {
+		if name == branch {
+			return
+		}
+		a.runRefAction("Rename Branch", fmt.Sprintf("Renamed %s to %s.", branch, name), "Unable to rename the branch", false, func(svc *git.Service) error {
+			return svc.RenameBranch(branch, name)
+		})
+	})
+}
+
+func (a *Controller) createBranchAsync(name, commit string, checkout bool) {
+	done := fmt.Sprintf("Created branch %s at %s.", name, shortHash(commit))
+	a.runRefAction("Create Branch", done, "Unable to create the branch", checkout, func(svc *git.Service) error {
+		return svc.CreateBranch(name, commit, checkout)
+	})
+}
+
+func (a *Controller) createTagAsync(name, commit, message string) {
+	done := fmt.Sprintf("Created tag %s at %s.", name, shortHash(commit))
+	a.runRefAction("Create Tag", done, "Unable to create the tag", false, func(svc *git.Service) error {
+		return svc.CreateTag(name, commit, message)
+	})
+}
+
+// confirmDeleteBranch asks before deleting branch, warning about the commits
+// that are not merged into HEAD.
+func (a *Controller) confirmDeleteBranch(branch string) {
+	if a.svc == nil || branch == "" {
+		return
+	}
+	svc := a.svc
+	go func() {
+		unmerged, err := svc.UnmergedCommits(branch)
+		PostEvent(func() {
+			if svc != a.svc {
+				return
+			}
+			if err != nil {
+				slog.Error("count unmerged commits", slog.String("branch", branch), slog.Any("error", err))
+			}
+			if !a.confirm("Delete Branch", deleteBranchMessage(branch, unmerged, err)) {
+				return
+			}
+			// Only force the deletion once the user was warned about the
+			// unmerged commits; otherwise git refuses to lose them.
+			force := err == nil && unmerged > 0
+			a.runRefAction("Delete Branch", fmt.Sprintf("Deleted branch %s.", branch), "Unable to delete the branch", false, func(svc *git.Service) error {
+				return svc.DeleteBranch(branch, force)
+			})
+		}, false)
+	}()
+}
+
+// deleteBranchMessage asks before deleting branch, warning about its unmerged
+// commits that HEAD does not reach. countErr is the error of counting them.
+func deleteBranchMessage(branch string, unmerged int, countErr error) string {
+	msg := fmt.Sprintf("Delete branch %s?", branch)
+	switch {
+	case countErr != nil:
+		return msg + "\n\nUnable to check whether it is merged into HEAD."
+	case unmerged == 1:
+		return msg + "\n\nIt has 1 commit that is not merged into HEAD and may be lost."
+	case unmerged > 1:
+		return msg + fmt.Sprintf("\n\nIt has %d commits that are not merged into HEAD and may be lost.", unmerged)
+	}
+	return msg
+}
+
+func (a *Controller) confirmDeleteTag(tag string) {
+	if a.svc == nil || tag == "" || !a.confirm("Delete Tag", fmt.Sprintf("Delete tag %s?", tag)) {
+		return
+	}
+	a.runRefAction("Delete Tag", fmt.Sprintf("Deleted tag %s.", tag), "Unable to delete the tag", false, func(svc *git.Service) error {
+		return svc.DeleteTag(tag)
+	})
+}
+
+func (a *Controller) confirm(title, msg string) bool {
+	return MessageBox(
+		Parent(App),
+		Title(title),
+		Icon("warning"),
+		Msg(msg),
+		Type("yesno"),
+		Default("no"),
+	) == "yes"
+}
+
+// runRefAction runs action in the background, then refreshes the ref labels,
+// or restarts the commit list when moved reports that HEAD changed. Failures
+// are reported in a dialog titled title, starting with failed; conflicts also
+// refresh the local changes.
+func (a *Controller) runRefAction(title, done, failed string, moved bool, action func(*git.Service) error) {
+	if a.svc == nil {
+		return
+	}
+	svc := a.svc
+	go func() {
+		err := action(svc)
+		PostEvent(func() {
+			if svc != a.svc {
+				return
+			}
+			if err != nil {
+				var conflict *git.ConflictError
+				if errors.As(err, &conflict) {
+					// The operation stopped halfway, the local changes rows
+					// show the conflicting files.
+					a.refreshLocalChangesAsync(true)
+				}
+				MessageBox(
+					Parent(App),
+					Title(title),
+					Icon("error"),
+					Msg(fmt.Sprintf("%s:\n\n%v", failed, err)),
+					Type("ok"),
+				)
+				a.setStatus(fmt.Sprintf("%s: %v", failed, err))
+				return
+			}
+			if moved {
+				a.restartCommitList(done)
+				return
+			}
+			a.refreshRefLabels()
+			a.setStatus(done)
+		}, false)
+	}()
+}
+
+// refreshRefLabels reloads the ref labels of the commit list and the refs
+// sidebar, redrawing only the commits whose labels changed.
+func (a *Controller) refreshRefLabels() {
+	if a.svc == nil {
+		return
+	}
+	svc := a.svc
+	go func() {
+		labels, err := svc.BranchLabels()
+		PostEvent(func() {
+			if svc != a.svc {
+				return
+			}
+			if err != nil {
+				slog.Error("failed to refresh branch labels", slog.Any("error", err))
+				return
+			}
+			old := a.state.tree.branchLabels
+			a.state.tree.branchLabels = labels
+			for _, hash := range changedLabelHashes(old, labels) {
+				a.updateCommitRow(hash)
+			}
+			a.scheduleGraphCanvasDraw()
+		}, false)
+	}()
+	a.refreshRefsAsync()
+}
+
+// updateCommitRow redraws the row of the commit with hash, if it is shown.
+func (a *Controller) updateCommitRow(hash string) {
+	if id, values, ok := a.commitRowUpdate(hash); ok {
+		a.ui.treeView.Item(id, Values(values))
+	}
+}
+
+// commitRowUpdate returns the item id and the values of the row of the commit
+// with hash under the current ref labels, or false when it is not shown.
+func (a *Controller) commitRowUpdate(hash string) (string, []string, bool) {
+	idx := entryIndex(a.data.visible, hash)
+	if idx < 0 {
+		return "", nil, false
+	}
+	rows := buildTreeRows(a.data.visible[idx:idx+1], a.state.tree.branchLabels, a.cfg.graphCanvas)
+	if len(rows) == 0 {
+		return "", nil, false
+	}
+	return strconv.Itoa(idx), a.commitRowValues(rows[0]), true
+}
+
+// contextCommitHash is the hash of the commit under the tree context menu.
+func (a *Controller) contextCommitHash() string {
+	if commit := a.contextCommit(); commit != nil {
+		return commit.Hash
+	}
+	return ""
+}
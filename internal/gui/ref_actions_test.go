@@ -0,0 +1,74 @@
+package gui
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestChangedLabelHashes(t *testing.T) {
+	old := map[string][]string{
+		"a": {"HEAD -> main"},
+		"b": {"feature"},
+		"c": {"tag: v1"},
+	}
+	labels := map[string][]string{
+		"a": {"HEAD -> main"},
+		"b": {"feature", "topic"},
+		"d": {"tag: v2"},
+	}
+	if got := changedLabelHashes(old, labels); !slices.Equal(got, []string{"b", "c", "d"}) {
+		t.Fatalf("changedLabelHashes() = %v, want [b c d]", got)
+	}
+	if got := changedLabelHashes(labels, labels); len(got) != 0 {
+		t.Fatalf("expected no changes, got %v", got)
+	}
+}
+
+func TestDeleteBranchMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		unmerged int
+		err      error
+		want     string
+	}{
+		{name: "merged", want: "Delete branch topic?"},
+		{name: "one", unmerged: 1, want: "Delete branch topic?\n\nIt has 1 commit that is not merged into HEAD and may be lost."},
+		{name: "several", unmerged: 3, want: "Delete branch topic?\n\nIt has 3 commits that are not merged into HEAD and may be lost."},
+		{name: "error", unmerged: 3, err: errors.New("boom"), want: "Delete branch topic?\n\nUnable to check whether it is merged into HEAD."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deleteBranchMessage("topic", tt.unmerged, tt.err); got != tt.want {
+				t.Fatalf("deleteBranchMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitRowUpdateUsesCurrentLabels(t *testing.T) {
+	entries := []*git.Entry{
+		{Commit: &git.Commit{Hash: "aaaa", Message: "first"}, Graph: "*"},
+		{Commit: &git.Commit{Hash: "bbbb", Message: "second"}, Graph: "*"},
+	}
+	a := &Controller{data: controllerData{visible: entries}}
+	a.state.tree.branchLabels = map[string][]string{"bbbb": {"topic"}}
+
+	id, values, ok := a.commitRowUpdate("bbbb")
+	if !ok || id != "1" {
+		t.Fatalf("commitRowUpdate() = %q, %v, want row 1", id, ok)
+	}
+	if values[0] != "* [topic]" {
+		t.Fatalf("graph value = %q, want %q", values[0], "* [topic]")
+	}
+
+	a.state.tree.branchLabels = map[string][]string{"bbbb": {"renamed"}}
+	if _, values, _ = a.commitRowUpdate("bbbb"); values[0] != "* [renamed]" {
+		t.Fatalf("graph value after rename = %q, want %q", values[0], "* [renamed]")
+	}
+	if _, _, ok := a.commitRowUpdate("cccc"); ok {
+		t.Fatalf("expected no row for a commit that is not shown")
+	}
+}
@@ -0,0 +1,403 @@
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+type refTargetKind uint8
+
+const (
+	refTargetBranch refTargetKind = iota
+	refTargetRemote
+	refTargetTag
+	refTargetStash
+)
+
+// refTarget is the commit an entry of the refs sidebar points to.
+type refTarget struct {
+	kind refTargetKind
+	// name is the short ref name: main, origin/main, v1 or stash@{0}.
+	name string
+	hash string
+	head bool
+}
+
+// refItem is a row of the refs sidebar. Group rows have no target.
+type refItem struct {
+	id     string
+	parent string
+	label  string
+	target *refTarget
+}
+
+// key identifies a group row across refreshes.
+func (it refItem) key() string {
+	return it.parent + "/" + it.label
+}
+
+// buildRefItems lays out the refs sidebar: local branches, remote branches
+// grouped by remote, tags and stashes. Empty groups are left out.
+func buildRefItems(list git.RefList) []refItem {
+	var locals, tags []git.Ref
+	var remoteNames []string
+	remotes := map[string][]git.Ref{}
+	for _, ref := range list.Refs {
+		switch ref.Kind {
+		case git.RefKindBranch:
+			locals = append(locals, ref)
+		case git.RefKindRemoteBranch:
+			remote, branch, ok := strings.Cut(ref.Name, "/")
+			if !ok || branch == "HEAD" {
+				continue
+			}
+			if _, seen := remotes[remote]; !seen {
+				remoteNames = append(remoteNames, remote)
+			}
+			remotes[remote] = append(remotes[remote], ref)
+		case git.RefKindTag:
+			tags = append(tags, ref)
+		}
+	}
+
+	var items []refItem
+	addGroup := func(id, parent, label string) {
+		items = append(items, refItem{id: id, parent: parent, label: label})
+	}
+	addRef := func(parent, label string, target refTarget) {
+		id := fmt.Sprintf("ref:%d", len(items))
+		items = append(items, refItem{id: id, parent: parent, label: label, target: &target})
+	}
+
+	if len(locals) > 0 {
+		addGroup("group:local", "", "Local")
+		for _, ref := range locals {
+			head := ref.Name == list.HeadName
+			label := ref.Name
+			if head {
+				label += " (HEAD)"
+			}
+			addRef("group:local", label, refTarget{kind: refTargetBranch, name: ref.Name, hash: ref.Hash, head: head})
+		}
+	}
+	if len(remoteNames) > 0 {
+		addGroup("group:remotes", "", "Remotes")
+		for i, remote := range remoteNames {
+			groupID := fmt.Sprintf("remote:%d", i)
+			addGroup(groupID, "group:remotes", remote)
+			for _, ref := range remotes[remote] {
+				label := strings.TrimPrefix(ref.Name, remote+"/")
+				addRef(groupID, label, refTarget{kind: refTargetRemote, name: ref.Name, hash: ref.Hash})
+			}
+		}
+	}
+	if len(tags) > 0 {
+		addGroup("group:tags", "", "Tags")
+		for _, ref := range tags {
+			addRef("group:tags", ref.Name, refTarget{kind: refTargetTag, name: ref.Name, hash: ref.Hash})
+		}
+	}
+	if len(list.Stashes) > 0 {
+		addGroup("group:stash", "", "Stash")
+		for _, stash := range list.Stashes {
+			label := fmt.Sprintf("%s: %s", stash.Name, stash.Message)
+			addRef("group:stash", label, refTarget{kind: refTargetStash, name: stash.Name, hash: stash.Hash})
+		}
+	}
+	return items
+}
+
+// buildRefsSidebar creates the refs sidebar inside parent. It is added to
+// the paned window by the caller.
+func (a *Controller) buildRefsSidebar(parent *TPanedwindowWidget) *TFrameWidget {
+	frame := parent.TFrame()
+	a.ui.refsSidebar = frame
+	GridRowConfigure(frame.Window, 0, Weight(1))
+	GridColumnConfigure(frame.Window, 0, Weight(1))
+
+	scroll := frame.TScrollbar()
+	a.ui.refsTree = frame.TTreeview(
+		Show("tree"),
+		Selectmode("browse"),
+		Yscrollcommand(func(e *Event) { e.ScrollSet(scroll) }),
+	)
+	a.ui.refsTree.Column("#0", Width(200))
+	Grid(a.ui.refsTree, Row(0), Column(0), Sticky(NEWS))
+	Grid(scroll, Row(0), Column(1), Sticky(NS))
+	scroll.Configure(Command(func(e *Event) { e.Yview(a.ui.refsTree) }))
+
+	Bind(a.ui.refsTree, "<ButtonRelease-1>", Command(func(e *Event) {
+		a.jumpToRef(a.refTargetAt(e))
+	}))
+	Bind(a.ui.refsTree, "<KeyPress-Return>", Command(func() {
+		a.jumpToRef(a.selectedRefTarget())
+	}))
+	Bind(a.ui.refsTree, "<Double-Button-1>", Command(func(e *Event) {
+		if target := a.refTargetAt(e); target != nil {
+			a.checkoutRef(*target)
+		}
+	}))
+	a.initRefsContextMenu()
+	handler := func(e *Event) { a.showRefsContextMenu(e) }
+	Bind(a.ui.refsTree, "<Button-2>", Command(handler))
+	Bind(a.ui.refsTree, "<Button-3>", Command(handler))
+	return frame
+}
+
+func (a *Controller) initRefsContextMenu() {
+	menu := App.Menu(Tearoff(false))
+	a.ui.refsMenuItems = map[string]*MenuItem{
+		"checkout": menu.AddCommand(Lbl("Check Out"), Command(func() { a.runRefsContextAction(a.checkoutRef) })),
+		"tracking": menu.AddCommand(Lbl("Check Out as New Tracking Branch..."), Command(func() {
+			a.runRefsContextAction(func(target refTarget) { a.promptTrackingBranch(target.name) })
+		})),
+		"compare": menu.AddCommand(Lbl("Compare with HEAD"), Command(func() { a.runRefsContextAction(a.compareRefWithHead) })),
+	}
+	menu.AddSeparator()
+	a.ui.refsMenuItems["copy"] = menu.AddCommand(Lbl("Copy Name"), Command(func() { a.runRefsContextAction(a.copyRefName) }))
+	menu.AddSeparator()
+	menu.AddCommand(Lbl("Create Branch Here..."), Command(func() {
+		a.runRefsContextAction(func(target refTarget) { a.promptCreateBranch(target.hash, false) })
+	}))
+	menu.AddCommand(Lbl("Create Tag Here..."), Command(func() {
+		a.runRefsContextAction(func(target refTarget) { a.promptCreateTag(target.hash) })
+	}))
+	a.ui.refsMenuItems["rename"] = menu.AddCommand(Lbl("Rename Branch..."), Command(func() {
+		a.runRefsContextAction(func(target refTarget) { a.promptRenameBranch(target.name) })
+	}))
+	a.ui.refsMenuItems["deleteBranch"] = menu.AddCommand(Lbl("Delete Branch"), Command(func() {
+		a.runRefsContextAction(func(target refTarget) { a.confirmDeleteBranch(target.name) })
+	}))
+	a.ui.refsMenuItems["deleteTag"] = menu.AddCommand(Lbl("Delete Tag"), Command(func() {
+		a.runRefsContextAction(func(target refTarget) { a.confirmDeleteTag(target.name) })
+	}))
+	a.ui.refsContextMenu = menu
+}
+
+func (a *Controller) showRefsContextMenu(e *Event) {
+	if e == nil {
+		return
+	}
+	id := strings.TrimSpace(a.ui.refsTree.IdentifyItem(e.X, e.Y))
+	target := a.refTargetByID(id)
+	if target == nil {
+		return
+	}
+	a.ui.refsTree.Selection("set", id)
+	a.ui.refsTree.Focus(id)
+	a.state.refs.contextTarget = id
+	checkout := "normal"
+	if !canCheckoutRef(*target) {
+		checkout = "disabled"
+	}
+	compare := "normal"
+	if a.state.refs.headHash == "" {
+		compare = "disabled"
+	}
+	a.ui.refsContextMenu.EntryConfigure(a.ui.refsMenuItems["checkout"], State(checkout))
+	a.ui.refsContextMenu.EntryConfigure(a.ui.refsMenuItems["compare"], State(compare))
+	for key, enabled := range refEditItems(*target) {
+		state := "disabled"
+		if enabled {
+			state = "normal"
+		}
+		a.ui.refsContextMenu.EntryConfigure(a.ui.refsMenuItems[key], State(state))
+	}
+	Popup(a.ui.refsContextMenu.Window, e.XRoot, e.YRoot, nil)
+}
+
+// refEditItems reports which ref editing entries of the refs context menu
+// apply to target. The checked out branch cannot be deleted.
+func refEditItems(target refTarget) map[string]bool {
+	branch := target.kind == refTargetBranch
+	return map[string]bool{
+		"tracking":     target.kind == refTargetRemote,
+		"rename":       branch,
+		"deleteBranch": branch && !target.head,
+		"deleteTag":    target.kind == refTargetTag,
+	}
+}
+
+func (a *Controller) runRefsContextAction(action func(refTarget)) {
+	if target := a.refTargetByID(a.state.refs.contextTarget); target != nil {
+		action(*target)
+	}
+}
+
+func (a *Controller) refTargetByID(id string) *refTarget {
+	for _, item := range a.state.refs.items {
+		if item.id == id {
+			return item.target
+		}
+	}
+	return nil
+}
+
+func (a *Controller) refTargetAt(e *Event) *refTarget {
+	if e == nil {
+		return nil
+	}
+	return a.refTargetByID(strings.TrimSpace(a.ui.refsTree.IdentifyItem(e.X, e.Y)))
+}
+
+func (a *Controller) selectedRefTarget() *refTarget {
+	sel := a.ui.refsTree.Selection("")
+	if len(sel) == 0 {
+		return nil
+	}
+	return a.refTargetByID(sel[0])
+}
+
+func (a *Controller) jumpToRef(target *refTarget) {
+	if target == nil {
+		return
+	}
+	a.jumpToCommit(target.hash, target.name)
+}
+
+// refreshRefsAsync reloads the refs sidebar.
+func (a *Controller) refreshRefsAsync() {
+	if a.svc == nil || a.ui.refsTree == nil {
+		return
+	}
+	svc := a.svc
+	go func() {
+		list, err := svc.ListRefs()
+		PostEvent(func() {
+			if svc != a.svc {
+				return
+			}
+			if err != nil {
+				slog.Error("failed to list refs", slog.Any("error", err))
+				list = git.RefList{}
+			}
+			a.populateRefs(list)
+		}, false)
+	}()
+}
+
+// populateRefs replaces the rows of the refs sidebar, keeping the groups the
+// user collapsed closed.
+func (a *Controller) populateRefs(list git.RefList) {
+	tree := a.ui.refsTree
+	closed := map[string]bool{}
+	for _, item := range a.state.refs.items {
+		if item.target != nil {
+			continue
+		}
+		switch tkutil.EvalOrEmpty("%s item %s -open", tree, item.id) {
+		case "0", "false":
+			closed[item.key()] = true
+		}
+	}
+	if children := tree.Children(""); len(children) > 0 {
+		args := make([]any, len(children))
+		for i, child := range children {
+			args[i] = child
+		}
+		tree.Delete(args...)
+	}
+
+	items := buildRefItems(list)
+	for _, item := range items {
+		tree.Insert(item.parent, "end", Id(item.id), Txt(item.label))
+		if item.target == nil && !closed[item.key()] {
+			if _, err := tkutil.Eval("%s item %s -open 1", tree, item.id); err != nil {
+				slog.Debug("open refs group", slog.Any("error", err))
+			}
+		}
+	}
+	a.state.refs.items = items
+	a.state.refs.refs = list.Refs
+	a.state.refs.headHash = list.HeadHash
+	a.state.refs.contextTarget = ""
+}
+
+// canCheckoutRef reports whether double-clicking target switches to it.
+// Remote branches are checked out through git's tracking branch guessing and
+// tags detach HEAD.
+func canCheckoutRef(target refTarget) bool {
+	switch target.kind {
+	case refTargetBranch:
+		return !target.head
+	case refTargetRemote, refTargetTag:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *Controller) checkoutRef(target refTarget) {
+	if !canCheckoutRef(target) {
+		if target.kind == refTargetStash {
+			a.setStatus(fmt.Sprintf("%s is not a branch and cannot be checked out.", target.name))
+		}
+		return
+	}
+	switch target.kind {
+	case refTargetTag:
+		a.checkoutDetachedAsync(target.name, target.name)
+	case refTargetRemote:
+		_, branch, _ := strings.Cut(target.name, "/")
+		a.switchBranchAsync(branch)
+	default:
+		a.switchBranchAsync(target.name)
+	}
+}
+
+// compareRefWithHead shows the changes from HEAD to target in the diff view.
+func (a *Controller) compareRefWithHead(target refTarget) {
+	if head := a.state.refs.headHash; head != "" {
+		a.showCompareDiff(head, target.hash, "HEAD", target.name)
+	}
+}
+
+func (a *Controller) copyRefName(target refTarget) {
+	ClipboardClear()
+	ClipboardAppend(target.name)
+	a.setStatus(fmt.Sprintf("Copied %s to clipboard.", target.name))
+}
+
+// toggleRefsSidebar shows or hides the refs sidebar.
+func (a *Controller) toggleRefsSidebar() {
+	pane, sidebar := a.ui.refsPane, a.ui.refsSidebar
+	if pane == nil || sidebar == nil {
+		return
+	}
+	if a.state.refs.hidden {
+		if _, err := tkutil.Eval("%s insert 0 %s -weight 0", pane, sidebar); err != nil {
+			slog.Error("show refs sidebar", slog.Any("error", err))
+			return
+		}
+		if a.state.refs.sash > 0 {
+			if _, err := tkutil.Eval("%s sashpos 0 %d", pane, a.state.refs.sash); err != nil {
+				slog.Debug("restore refs sidebar width", slog.Any("error", err))
+			}
+		}
+	} else {
+		a.state.refs.sash = tkutil.Atoi(tkutil.EvalOrEmpty("%s sashpos 0", pane))
+		if _, err := tkutil.Eval("%s forget %s", pane, sidebar); err != nil {
+			slog.Error("hide refs sidebar", slog.Any("error", err))
+			return
+		}
+	}
+	a.state.refs.hidden = !a.state.refs.hidden
+	a.updateRefsMenuLabel()
+}
+
+func (a *Controller) updateRefsMenuLabel() {
+	if a.ui.viewMenu == nil {
+		return
+	}
+	label := "Hide Refs Sidebar"
+	if a.state.refs.hidden {
+		label = "Show Refs Sidebar"
+	}
+	a.ui.viewMenu.EntryConfigure(a.ui.refsToggleItem, Lbl(label))
+}
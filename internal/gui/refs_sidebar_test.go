@@ -0,0 +1,108 @@
+package gui
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestBuildRefItems_GroupsRefs(t *testing.T) {
+	list := git.RefList{
+		Refs: []git.Ref{
+			{Hash: "b1", Kind: git.RefKindBranch, Name: "main"},
+			{Hash: "b2", Kind: git.RefKindBranch, Name: "topic"},
+			{Hash: "r0", Kind: git.RefKindRemoteBranch, Name: "origin/HEAD"},
+			{Hash: "r1", Kind: git.RefKindRemoteBranch, Name: "origin/main"},
+			{Hash: "r2", Kind: git.RefKindRemoteBranch, Name: "upstream/feature/x"},
+			{Hash: "t1", Kind: git.RefKindTag, Name: "v1"},
+		},
+		Stashes:  []git.Stash{{Hash: "s0", Name: "stash@{0}", Message: "WIP on main"}},
+		HeadName: "main",
+		HeadHash: "b1",
+	}
+	items := buildRefItems(list)
+
+	var rows []string
+	for _, item := range items {
+		rows = append(rows, item.parent+" > "+item.label)
+	}
+	want := []string{
+		" > Local",
+		"group:local > main (HEAD)",
+		"group:local > topic",
+		" > Remotes",
+		"group:remotes > origin",
+		"remote:0 > main",
+		"group:remotes > upstream",
+		"remote:1 > feature/x",
+		" > Tags",
+		"group:tags > v1",
+		" > Stash",
+		"group:stash > stash@{0}: WIP on main",
+	}
+	if !slices.Equal(rows, want) {
+		t.Fatalf("rows = %#v, want %#v", rows, want)
+	}
+
+	targets := map[string]refTarget{}
+	for _, item := range items {
+		if item.target != nil {
+			targets[item.label] = *item.target
+		}
+	}
+	if got := targets["main (HEAD)"]; !got.head || got.hash != "b1" || canCheckoutRef(got) {
+		t.Fatalf("head branch target = %+v", got)
+	}
+	if got := targets["feature/x"]; got.name != "upstream/feature/x" || !canCheckoutRef(got) {
+		t.Fatalf("remote target = %+v", got)
+	}
+	if got := targets["v1"]; got.kind != refTargetTag || !canCheckoutRef(got) {
+		t.Fatalf("tag target = %+v", got)
+	}
+	if got := targets["stash@{0}: WIP on main"]; got.kind != refTargetStash || canCheckoutRef(got) {
+		t.Fatalf("stash target = %+v", got)
+	}
+}
+
+func TestBuildRefItems_SkipsEmptyGroups(t *testing.T) {
+	items := buildRefItems(git.RefList{
+		Refs:     []git.Ref{{Hash: "t1", Kind: git.RefKindTag, Name: "v1"}},
+		HeadName: "HEAD",
+	})
+	if len(items) != 2 || items[0].id != "group:tags" {
+		t.Fatalf("items = %+v, want only the tags group", items)
+	}
+}
+
+func TestEntryIndex(t *testing.T) {
+	entries := []*git.Entry{
+		{Commit: &git.Commit{Hash: "a"}},
+		nil,
+		{Commit: &git.Commit{Hash: "b"}},
+	}
+	if got := entryIndex(entries, "b"); got != 2 {
+		t.Fatalf("entryIndex(b) = %d, want 2", got)
+	}
+	if got := entryIndex(entries, "c"); got != -1 {
+		t.Fatalf("entryIndex(c) = %d, want -1", got)
+	}
+}
+
+func TestRefEditItems(t *testing.T) {
+	tests := []struct {
+		target refTarget
+		want   map[string]bool
+	}{
+		{refTarget{kind: refTargetBranch, name: "topic"}, map[string]bool{"tracking": false, "rename": true, "deleteBranch": true, "deleteTag": false}},
+		{refTarget{kind: refTargetBranch, name: "main", head: true}, map[string]bool{"tracking": false, "rename": true, "deleteBranch": false, "deleteTag": false}},
+		{refTarget{kind: refTargetTag, name: "v1"}, map[string]bool{"tracking": false, "rename": false, "deleteBranch": false, "deleteTag": true}},
+		{refTarget{kind: refTargetRemote, name: "origin/main"}, map[string]bool{"tracking": true, "rename": false, "deleteBranch": false, "deleteTag": false}},
+	}
+	for _, tt := range tests {
+		if got := refEditItems(tt.target); !maps.Equal(got, tt.want) {
+			t.Fatalf("refEditItems(%+v) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+
+	. "modernc.org/tk9.0"
+)
+
+// filterMode selects what the filter bar matches. The text mode filters the
+// loaded commits; the others ask the backend for matching commits and
+// highlight them instead, like gitk's find modes.
+type filterMode int
+
+const (
+	filterModeText filterMode = iota
+	filterModePickaxe
+	filterModeChangedLines
+	filterModePaths
+)
+
+var filterModeLabels = []string{
+	"Commit text",
+	"Adding/removing string",
+	"Changing lines matching",
+	"Touching paths",
+}
+
+func (m filterMode) String() string {
+	return filterModeLabels[m]
+}
+
+func filterModeFromLabel(label string) filterMode {
+	for i, l := range filterModeLabels {
+		if l == label {
+			return filterMode(i)
+		}
+	}
+	return filterModeText
+}
+
+func (m filterMode) searchKind() git.SearchKind {
+	switch m {
+	case filterModeChangedLines:
+		return git.SearchChangedLines
+	case filterModePaths:
+		return git.SearchPaths
+	default:
+		return git.SearchPickaxe
+	}
+}
+
+// nextSearchMatch returns the index of the first entry after (dir > 0) or
+// before (dir < 0) index from that is in matches, or -1.
+func nextSearchMatch(entries []*git.Entry, matches map[string]bool, from int, dir int) int {
+	if dir == 0 {
+		return -1
+	}
+	for i := from + dir; i >= 0 && i < len(entries); i += dir {
+		if entry := entries[i]; entry != nil && entry.Commit != nil && matches[entry.Commit.Hash] {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadedMatchCount counts how many of the matches are among entries.
+func loadedMatchCount(entries []*git.Entry, matches map[string]bool) int {
+	count := 0
+	for _, entry := range entries {
+		if entry != nil && entry.Commit != nil && matches[entry.Commit.Hash] {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *Controller) onFilterModeChanged() {
+	mode := filterModeFromLabel(a.ui.filterMode.Textvariable())
+	if mode == a.state.filter.mode {
+		return
+	}
+	a.state.filter.mode = mode
+	a.clearSearch()
+	a.ui.filterEntry.Configure(Textvariable(""))
+	a.applyFilterImmediate("")
+}
+
+func (a *Controller) onFilterEntryChanged() {
+	raw := a.ui.filterEntry.Textvariable()
+	if a.state.filter.mode == filterModeText {
+		a.scheduleFilterApply(raw)
+		return
+	}
+	if strings.TrimSpace(raw) == "" && a.state.filter.search.matches != nil {
+		a.clearSearch()
+		a.applyFilterContent("")
+	}
+}
+
+// onFilterEntryReturn runs a new search when the pattern changed and steps to
+// the next match otherwise.
+func (a *Controller) onFilterEntryReturn() {
+	raw := a.ui.filterEntry.Textvariable()
+	if a.state.filter.mode == filterModeText || strings.TrimSpace(raw) == "" {
+		a.stepSearch(1)
+		return
+	}
+	query := git.SearchQuery{Kind: a.state.filter.mode.searchKind(), Pattern: raw}
+	if query == a.state.filter.search.query && a.state.filter.search.matches != nil {
+		a.stepSearch(1)
+		return
+	}
+	a.startSearch(query)
+}
+
+func (a *Controller) clearSearch() {
+	if cancel := a.state.filter.search.cancel; cancel != nil {
+		cancel()
+	}
+	a.state.filter.search.generation++
+	a.state.filter.search.query = git.SearchQuery{}
+	a.state.filter.search.matches = nil
+	a.state.filter.search.pendingStep = 0
+}
+
+func (a *Controller) startSearch(query git.SearchQuery) {
+	a.clearSearch()
+	a.state.filter.search.query = query
+	gen := a.state.filter.search.generation
+	ctx, cancel := context.WithCancel(context.Background())
+	a.state.filter.search.cancel = cancel
+	spec := a.cfg.logSpec
+	svc := a.svc
+	a.setStatus(fmt.Sprintf("Searching commits %s %q...", strings.ToLower(a.state.filter.mode.String()), query.Pattern))
+	go func() {
+		defer cancel()
+		matches, err := svc.SearchCommits(ctx, spec, query)
+		PostEvent(func() {
+			if gen != a.state.filter.search.generation {
+				return
+			}
+			if err != nil {
+				slog.Error("search commits", slog.Any("error", err))
+				a.state.filter.search.query = git.SearchQuery{}
+				a.setStatus(fmt.Sprintf("Search failed: %v", err))
+				return
+			}
+			a.state.filter.search.matches = matches
+			a.applyFilterContent(a.state.filter.value)
+			if len(matches) == 0 {
+				return
+			}
+			a.stepSearch(1)
+		}, false)
+	}()
+}
+
+// rerunSearch refreshes the matches after the commit list was reloaded.
+func (a *Controller) rerunSearch() {
+	if a.state.filter.search.matches == nil {
+		return
+	}
+	a.startSearch(a.state.filter.search.query)
+}
+
+// stepSearch selects the next (dir > 0) or previous (dir < 0) match, loading
+// more commits when the next match is beyond the loaded ones.
+func (a *Controller) stepSearch(dir int) {
+	matches := a.state.filter.search.matches
+	if a.state.filter.mode == filterModeText || matches == nil {
+		a.moveSelection(dir)
+		return
+	}
+	from := a.visibleSelectionIndex()
+	if idx := nextSearchMatch(a.data.visible, matches, from, dir); idx >= 0 {
+		a.selectTreeIndex(idx)
+		a.setStatus(a.statusSummary())
+		return
+	}
+	if dir > 0 && a.state.tree.hasMore && loadedMatchCount(a.data.commits, matches) < len(matches) {
+		a.state.filter.search.pendingStep = dir
+		a.setStatus("Loading more commits to reach the next match...")
+		a.loadMoreCommitsAsync(false)
+		return
+	}
+	a.setStatus(fmt.Sprintf("No more matches. %s", a.statusSummary()))
+}
+
+// continuePendingSearch resumes a stepSearch interrupted to load commits.
+func (a *Controller) continuePendingSearch() {
+	dir := a.state.filter.search.pendingStep
+	if dir == 0 {
+		return
+	}
+	a.state.filter.search.pendingStep = 0
+	a.stepSearch(dir)
+}
+
+func (a *Controller) searchSummary() string {
+	search := a.state.filter.search
+	if search.matches == nil {
+		return ""
+	}
+	loaded := loadedMatchCount(a.data.commits, search.matches)
+	return fmt.Sprintf("%s %q: %d matches (%d loaded)",
+		a.state.filter.mode, search.query.Pattern, len(search.matches), loaded)
+}
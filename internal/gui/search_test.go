@@ -0,0 +1,47 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestFilterModeFromLabel(t *testing.T) {
+	for i, label := range filterModeLabels {
+		if got := filterModeFromLabel(label); got != filterMode(i) {
+			t.Fatalf("label %q: want %d, got %d", label, i, got)
+		}
+	}
+	if got := filterModeFromLabel("unknown"); got != filterModeText {
+		t.Fatalf("unknown label: want text mode, got %d", got)
+	}
+	if filterModePaths.searchKind() != git.SearchPaths || filterModeChangedLines.searchKind() != git.SearchChangedLines {
+		t.Fatal("unexpected search kind mapping")
+	}
+}
+
+func TestNextSearchMatch(t *testing.T) {
+	var entries []*git.Entry
+	for _, hash := range []string{"a", "b", "c", "d", "e"} {
+		entries = append(entries, &git.Entry{Commit: &git.Commit{Hash: hash}})
+	}
+	matches := map[string]bool{"b": true, "d": true, "z": true}
+	tests := []struct {
+		from, dir, want int
+	}{
+		{from: -1, dir: 1, want: 1},
+		{from: 1, dir: 1, want: 3},
+		{from: 3, dir: 1, want: -1},
+		{from: 4, dir: -1, want: 3},
+		{from: 1, dir: -1, want: -1},
+		{from: 2, dir: 0, want: -1},
+	}
+	for _, tc := range tests {
+		if got := nextSearchMatch(entries, matches, tc.from, tc.dir); got != tc.want {
+			t.Fatalf("from=%d dir=%d: want %d, got %d", tc.from, tc.dir, tc.want, got)
+		}
+	}
+	if got := loadedMatchCount(entries, matches); got != 2 {
+		t.Fatalf("loadedMatchCount: want 2, got %d", got)
+	}
+}
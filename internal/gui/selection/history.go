@@ -0,0 +1,74 @@
+package selection
+
+// DefaultHistoryLimit is the number of entries kept by a zero History.
+const DefaultHistoryLimit = 200
+
+// History is the back/forward list of selected commits. Entries are kept by
+// hash so that they survive reloads.
+type History struct {
+	// Limit bounds the number of entries; zero means DefaultHistoryLimit.
+	Limit int
+
+	entries []string
+	pos     int
+}
+
+// Visit makes hash the current entry, dropping the entries after the
+// current one.
+func (h *History) Visit(hash string) {
+	if hash == "" {
+		return
+	}
+	if len(h.entries) > 0 {
+		if h.entries[h.pos] == hash {
+			return
+		}
+		h.entries = h.entries[:h.pos+1]
+	}
+	h.entries = append(h.entries, hash)
+	limit := h.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	if extra := len(h.entries) - limit; extra > 0 {
+		h.entries = append([]string(nil), h.entries[extra:]...)
+	}
+	h.pos = len(h.entries) - 1
+}
+
+// Back moves to the closest earlier entry for which present returns true and
+// returns its hash. A nil present accepts every entry.
+func (h *History) Back(present func(hash string) bool) (string, bool) {
+	return h.step(-1, present)
+}
+
+// Forward is the counterpart of Back.
+func (h *History) Forward(present func(hash string) bool) (string, bool) {
+	return h.step(1, present)
+}
+
+func (h *History) step(dir int, present func(hash string) bool) (string, bool) {
+	for i := h.pos + dir; i >= 0 && i < len(h.entries); i += dir {
+		if present == nil || present(h.entries[i]) {
+			h.pos = i
+			return h.entries[i], true
+		}
+	}
+	return "", false
+}
+
+// CanBack reports whether there are entries before the current one.
+func (h *History) CanBack() bool {
+	return len(h.entries) > 0 && h.pos > 0
+}
+
+// CanForward reports whether there are entries after the current one.
+func (h *History) CanForward() bool {
+	return h.pos < len(h.entries)-1
+}
+
+// Clear drops every entry.
+func (h *History) Clear() {
+	h.entries = nil
+	h.pos = 0
+}
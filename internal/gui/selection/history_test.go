@@ -0,0 +1,69 @@
+package selection
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHistoryBackForward(t *testing.T) {
+	var h History
+	for _, hash := range []string{"a", "b", "b", "c"} {
+		h.Visit(hash)
+	}
+	if !h.CanBack() || h.CanForward() {
+		t.Fatalf("CanBack=%v CanForward=%v after visits", h.CanBack(), h.CanForward())
+	}
+	if got, ok := h.Back(nil); !ok || got != "b" {
+		t.Fatalf("Back = %q, %v; want b", got, ok)
+	}
+	if got, ok := h.Back(nil); !ok || got != "a" {
+		t.Fatalf("Back = %q, %v; want a", got, ok)
+	}
+	if _, ok := h.Back(nil); ok {
+		t.Fatalf("expected Back to fail at the oldest entry")
+	}
+	if got, ok := h.Forward(nil); !ok || got != "b" {
+		t.Fatalf("Forward = %q, %v; want b", got, ok)
+	}
+
+	// Visiting from the middle drops the forward entries.
+	h.Visit("d")
+	if h.CanForward() {
+		t.Fatalf("expected no forward entries after a visit")
+	}
+	if got, _ := h.Back(nil); got != "b" {
+		t.Fatalf("Back = %q, want b", got)
+	}
+}
+
+func TestHistorySkipsMissingCommits(t *testing.T) {
+	var h History
+	for _, hash := range []string{"a", "gone", "c"} {
+		h.Visit(hash)
+	}
+	present := func(hash string) bool { return hash != "gone" }
+	if got, ok := h.Back(present); !ok || got != "a" {
+		t.Fatalf("Back = %q, %v; want a", got, ok)
+	}
+	if got, ok := h.Forward(present); !ok || got != "c" {
+		t.Fatalf("Forward = %q, %v; want c", got, ok)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	h := History{Limit: 3}
+	for i := range 5 {
+		h.Visit(fmt.Sprint(i))
+	}
+	var got []string
+	for {
+		hash, ok := h.Back(nil)
+		if !ok {
+			break
+		}
+		got = append(got, hash)
+	}
+	if fmt.Sprint(got) != "[3 2]" {
+		t.Fatalf("back entries = %v, want [3 2]", got)
+	}
+}
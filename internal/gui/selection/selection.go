@@ -61,6 +61,18 @@ func (s *State) SetLocal(staged bool) {
 	s.storeSnapshot(selectionSnapshot{kind: kind})
 }
 
+// Local reports whether local changes are selected, and which ones.
+func (s *State) Local() (staged bool, ok bool) {
+	switch s.snapshotValue().kind {
+	case selectionLocalUnstaged:
+		return false, true
+	case selectionLocalStaged:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
 func (s *State) CommitHash() string {
 	snap := s.snapshotValue()
 	if snap.kind != selectionCommit {
@@ -60,3 +60,22 @@ func TestSelectionStateCommitHash(t *testing.T) {
 		t.Fatalf("expected hash %q, got %q", "abc", got)
 	}
 }
+
+func TestSelectionStateLocal(t *testing.T) {
+	var sel State
+	if _, ok := sel.Local(); ok {
+		t.Fatal("empty selection reported as local")
+	}
+	sel.SetLocal(true)
+	if staged, ok := sel.Local(); !ok || !staged {
+		t.Fatalf("Local() = %v, %v; want staged", staged, ok)
+	}
+	sel.SetLocal(false)
+	if staged, ok := sel.Local(); !ok || staged {
+		t.Fatalf("Local() = %v, %v; want unstaged", staged, ok)
+	}
+	sel.SetCommit(&git.Entry{Commit: &git.Commit{Hash: "abc"}}, 0)
+	if _, ok := sel.Local(); ok {
+		t.Fatal("commit selection reported as local")
+	}
+}
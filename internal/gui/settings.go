@@ -0,0 +1,239 @@
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/config"
+	"github.com/thiagokokada/gitk-go/internal/git"
+	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
+
+	. "modernc.org/tk9.0"
+)
+
+var settingsScopeLabels = []string{"All repositories", "This repository"}
+
+// settingsForm holds the values edited in the settings dialog.
+type settingsForm struct {
+	limit      string
+	graphCols  string
+	backend    string
+	mode       string
+	textGraph  bool
+	autoReload bool
+	syntax     bool
+}
+
+// defaultSettings mirrors the defaults of the command-line flags.
+func defaultSettings() config.Settings {
+	limit, graphCols := uint(git.DefaultBatch), uint(git.DefaultGraphMaxColumns)
+	backend, mode := git.BackendCLI.String(), ThemeAuto.String()
+	off := false
+	return config.Settings{
+		Limit:     &limit,
+		GraphCols: &graphCols,
+		TextGraph: &off,
+		Backend:   &backend,
+		Mode:      &mode,
+		NoWatch:   &off,
+		NoSyntax:  &off,
+		Verbose:   &off,
+	}
+}
+
+// newSettingsForm fills the form from fully resolved settings.
+func newSettingsForm(s config.Settings) settingsForm {
+	return settingsForm{
+		limit:      strconv.FormatUint(uint64(*s.Limit), 10),
+		graphCols:  strconv.FormatUint(uint64(*s.GraphCols), 10),
+		backend:    *s.Backend,
+		mode:       *s.Mode,
+		textGraph:  *s.TextGraph,
+		autoReload: !*s.NoWatch,
+		syntax:     !*s.NoSyntax,
+	}
+}
+
+// apply returns s with the form values stored in it.
+func (f settingsForm) apply(s config.Settings) (config.Settings, error) {
+	parseUint := func(label, raw string) (*uint, error) {
+		v, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 0)
+		if err != nil || v == 0 {
+			return nil, fmt.Errorf("%s must be a positive number", label)
+		}
+		u := uint(v)
+		return &u, nil
+	}
+	limit, err := parseUint("Commits per batch", f.limit)
+	if err != nil {
+		return s, err
+	}
+	graphCols, err := parseUint("Graph columns", f.graphCols)
+	if err != nil {
+		return s, err
+	}
+	if _, err := git.BackendKindFromString(f.backend); err != nil {
+		return s, err
+	}
+	backend := strings.ToLower(strings.TrimSpace(f.backend))
+	mode := ThemePreferenceFromString(f.mode).String()
+	textGraph, noWatch, noSyntax := f.textGraph, !f.autoReload, !f.syntax
+	s.Limit = limit
+	s.GraphCols = graphCols
+	s.Backend = &backend
+	s.Mode = &mode
+	s.TextGraph = &textGraph
+	s.NoWatch = &noWatch
+	s.NoSyntax = &noSyntax
+	return s, nil
+}
+
+// scopeSettings returns the settings a scope falls back to (the built-in
+// defaults for the user scope, the user settings for a repository) and the
+// values currently in effect for it.
+func scopeSettings(file *config.File, repoPath string, repoScope bool) (base, current config.Settings) {
+	base = defaultSettings()
+	if !repoScope {
+		return base, base.Merge(file.Settings)
+	}
+	base = base.Merge(file.Settings)
+	return base, base.Merge(file.Repositories[config.RepoKey(repoPath)])
+}
+
+func (a *Controller) showSettingsDialog() {
+	if a.ui.settingsWindow != nil {
+		Destroy(a.ui.settingsWindow.Window)
+		a.ui.settingsWindow = nil
+	}
+	path, err := config.Path()
+	if err != nil {
+		a.showSettingsError(fmt.Sprintf("No configuration directory: %v", err))
+		return
+	}
+	file, err := config.Load(path)
+	if err != nil {
+		a.showSettingsError(err.Error())
+		return
+	}
+	repoPath := a.repo.path
+	if a.svc != nil {
+		repoPath = a.svc.RepoPath()
+	}
+
+	dialog := App.Toplevel()
+	a.ui.settingsWindow = dialog
+	dialog.WmTitle("Settings")
+	WmTransient(dialog.Window, App)
+
+	frame := dialog.TFrame(Padding("12p"))
+	Grid(frame, Row(0), Column(0), Sticky(NEWS))
+	GridColumnConfigure(frame.Window, 1, Weight(1))
+
+	row := 0
+	addRow := func(label string, w Widget) {
+		Grid(frame.TLabel(Txt(label), Anchor(W)), Row(row), Column(0), Sticky(W), Padx("0 8p"), Pady("0 4p"))
+		Grid(w, Row(row), Column(1), Sticky(WE), Pady("0 4p"))
+		row++
+	}
+	scope := frame.TCombobox(Values(settingsScopeLabels), State("readonly"), Width(24), Textvariable(settingsScopeLabels[0]))
+	addRow("Save for:", scope)
+	limit := frame.TEntry(Width(10), Textvariable(""))
+	addRow("Commits per batch:", limit)
+	graphCols := frame.TEntry(Width(10), Textvariable(""))
+	addRow("Graph columns:", graphCols)
+	backend := frame.TCombobox(Values([]string{git.BackendCLI.String(), git.BackendNative.String()}), State("readonly"), Width(10), Textvariable(""))
+	addRow("Backend:", backend)
+	mode := frame.TCombobox(Values([]string{ThemeAuto.String(), ThemeLight.String(), ThemeDark.String()}), State("readonly"), Width(10), Textvariable(""))
+	addRow("Color mode:", mode)
+
+	var checks []*TCheckbuttonWidget
+	addCheck := func(label string) {
+		check := frame.TCheckbutton(Txt(label), Variable(false))
+		Grid(check, Row(row), Column(0), Columnspan(2), Sticky(W), Pady("0 4p"))
+		checks = append(checks, check)
+		row++
+	}
+	addCheck("Draw graph as text")
+	addCheck("Reload when the repository changes")
+	addCheck("Syntax highlighting")
+
+	note := frame.TLabel(Txt(fmt.Sprintf("Saved to %s.\nChanges apply the next time gitk-go starts; flags take precedence.", path)), Anchor(W))
+	Grid(note, Row(row), Column(0), Columnspan(2), Sticky(W), Pady("8p 0"))
+	row++
+
+	load := func(repoScope bool) {
+		_, current := scopeSettings(file, repoPath, repoScope)
+		form := newSettingsForm(current)
+		limit.Configure(Textvariable(form.limit))
+		graphCols.Configure(Textvariable(form.graphCols))
+		backend.Configure(Textvariable(form.backend))
+		mode.Configure(Textvariable(form.mode))
+		for i, value := range []bool{form.textGraph, form.autoReload, form.syntax} {
+			checks[i].Configure(Variable(value))
+		}
+	}
+	load(false)
+	repoScope := func() bool { return scope.Textvariable() == settingsScopeLabels[1] }
+	Bind(scope, "<<ComboboxSelected>>", Command(func() { load(repoScope()) }))
+
+	save := func() {
+		edited := settingsForm{
+			limit:      limit.Textvariable(),
+			graphCols:  graphCols.Textvariable(),
+			backend:    backend.Textvariable(),
+			mode:       mode.Textvariable(),
+			textGraph:  checks[0].Variable() == "1",
+			autoReload: checks[1].Variable() == "1",
+			syntax:     checks[2].Variable() == "1",
+		}
+		isRepo := repoScope()
+		base, current := scopeSettings(file, repoPath, isRepo)
+		settings, err := edited.apply(current)
+		if err != nil {
+			a.showSettingsError(err.Error())
+			return
+		}
+		if isRepo {
+			file.SetRepository(repoPath, settings.Diff(base))
+		} else {
+			file.Settings = settings.Diff(base)
+		}
+		if err := file.Save(path); err != nil {
+			a.showSettingsError(fmt.Sprintf("Failed to save settings: %v", err))
+			return
+		}
+		Destroy(dialog.Window)
+		a.setStatus(fmt.Sprintf("Settings saved to %s", path))
+	}
+
+	buttons := frame.TFrame()
+	Grid(buttons, Row(row), Column(0), Columnspan(2), Sticky(E), Pady("8p 0"))
+	cancelBtn := buttons.TButton(Txt("Cancel"), Command(func() { Destroy(dialog.Window) }))
+	saveBtn := buttons.TButton(Txt("Save"), Command(save))
+	Grid(cancelBtn, Row(0), Column(0), Sticky(E), Padx("0 8p"))
+	Grid(saveBtn, Row(0), Column(1), Sticky(E))
+
+	Bind(dialog.Window, "<KeyPress-Escape>", Command(func() { Destroy(dialog.Window) }))
+	Bind(dialog.Window, "<KeyPress-Return>", Command(save))
+	Bind(dialog.Window, "<Destroy>", Command(func() {
+		if a.ui.settingsWindow == dialog {
+			a.ui.settingsWindow = nil
+		}
+	}))
+	if _, err := tkutil.Eval("focus %s", limit); err != nil {
+		slog.Debug("focus settings entry", slog.Any("error", err))
+	}
+	dialog.Center()
+}
+
+func (*Controller) showSettingsError(msg string) {
+	MessageBox(
+		Parent(App),
+		Title("Settings"),
+		Msg(msg),
+		Icon("error"),
+		Type("ok"),
+	)
+}
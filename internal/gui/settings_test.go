@@ -0,0 +1,55 @@
+package gui
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/config"
+)
+
+func TestSettingsFormRoundTrip(t *testing.T) {
+	form := newSettingsForm(defaultSettings())
+	if form.limit != "1000" || form.backend != "cli" || form.mode != "auto" || !form.autoReload || !form.syntax || form.textGraph {
+		t.Fatalf("unexpected default form %+v", form)
+	}
+	form.graphCols = " 50 "
+	form.mode = "Dark"
+	form.autoReload = false
+	settings, err := form.apply(defaultSettings())
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	got := settings.Diff(defaultSettings()).FlagValues()
+	want := map[string]string{"graph-cols": "50", "mode": "dark", "nowatch": "true"}
+	if !maps.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestSettingsFormValidation(t *testing.T) {
+	for _, form := range []settingsForm{
+		{limit: "0", graphCols: "1", backend: "cli"},
+		{limit: "1", graphCols: "many", backend: "cli"},
+		{limit: "1", graphCols: "1", backend: "svn"},
+	} {
+		if _, err := form.apply(defaultSettings()); err == nil {
+			t.Fatalf("expected error for %+v", form)
+		}
+	}
+}
+
+func TestScopeSettings(t *testing.T) {
+	userLimit, repoLimit := uint(10), uint(20)
+	file := &config.File{Settings: config.Settings{Limit: &userLimit}}
+	repo := t.TempDir()
+	file.SetRepository(repo, config.Settings{Limit: &repoLimit})
+
+	base, current := scopeSettings(file, repo, false)
+	if *base.Limit != 1000 || *current.Limit != 10 {
+		t.Fatalf("user scope: base %d, current %d", *base.Limit, *current.Limit)
+	}
+	base, current = scopeSettings(file, repo, true)
+	if *base.Limit != 10 || *current.Limit != 20 {
+		t.Fatalf("repository scope: base %d, current %d", *base.Limit, *current.Limit)
+	}
+}
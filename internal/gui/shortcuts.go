@@ -139,6 +139,28 @@ func (a *Controller) shortcutBindings() []shortcutBinding {
 			navigation:  false,
 			handler:     func() { a.copyDetailSelection(true) },
 		},
+		{
+			category:    "Diff view",
+			display:     "Ctrl/Cmd + D",
+			description: "Toggle side-by-side diff",
+			sequences:   []string{"<Control-KeyPress-d>", "<Command-KeyPress-d>"},
+			navigation:  false,
+			handler:     a.toggleSideBySide,
+		},
+		{
+			category:    "Commit list",
+			display:     "F3 / Shift + F3",
+			description: "Jump to the next / previous search match",
+			sequences:   []string{"<F3>"},
+			navigation:  false,
+			handler:     func() { a.stepSearch(1) },
+		},
+		{
+			category:   "Commit list",
+			sequences:  []string{"<Shift-F3>"},
+			navigation: false,
+			handler:    func() { a.stepSearch(-1) },
+		},
 		{
 			category:    "General",
 			display:     "/",
@@ -171,6 +193,20 @@ func (a *Controller) shortcutBindings() []shortcutBinding {
 			navigation:  false,
 			handler:     a.showShortcutsDialog,
 		},
+		{
+			category:    "Commit list",
+			display:     "Alt + Left / Alt + Right",
+			description: "Go back / forward in the selection history",
+			sequences:   []string{"<Alt-KeyPress-Left>"},
+			navigation:  true,
+			handler:     func() { a.navigateHistory(-1) },
+		},
+		{
+			category:   "Commit list",
+			sequences:  []string{"<Alt-KeyPress-Right>"},
+			navigation: true,
+			handler:    func() { a.navigateHistory(1) },
+		},
 		{
 			category:    "General",
 			display:     "Ctrl/Cmd + O",
@@ -187,6 +223,14 @@ func (a *Controller) shortcutBindings() []shortcutBinding {
 			navigation:  false,
 			handler:     a.promptBranchSwitch,
 		},
+		{
+			category:    "General",
+			display:     "Ctrl/Cmd + G",
+			description: "Go to a commit by hash, ref or revision",
+			sequences:   []string{"<Control-KeyPress-g>", "<Command-KeyPress-g>"},
+			navigation:  false,
+			handler:     a.promptGoTo,
+		},
 		{
 			category:    "Shortcuts dialog",
 			display:     "Escape",
@@ -200,7 +244,7 @@ func (a *Controller) shortcutBindings() []shortcutBinding {
 			description: "Quit gitk-go",
 			sequences:   []string{"<Control-KeyPress-q>"},
 			navigation:  false,
-			handler:     func() { Destroy(App) },
+			handler:     a.quit,
 		},
 	}
 }
@@ -380,7 +424,7 @@ func (a *Controller) scrollDetail(delta int, unit string) {
 	if delta == 0 {
 		return
 	}
-	if _, err := tkutil.Eval("%s yview scroll %d %s", a.ui.diffDetail, delta, unit); err != nil {
+	if _, err := tkutil.Eval("%s yview scroll %d %s", a.diffView(), delta, unit); err != nil {
 		slog.Error("detail scroll", slog.Any("error", err))
 	}
 }
@@ -0,0 +1,173 @@
+package gui
+
+import (
+	"strings"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+// sideBySideCell is one half of a side-by-side row. code marks cells holding
+// file content (without the +/-/space marker) that can be syntax highlighted.
+type sideBySideCell struct {
+	text string
+	tag  string
+	code bool
+}
+
+type sideBySideRow struct {
+	left  sideBySideCell
+	right sideBySideCell
+	// path is the file the row belongs to, used to pick a lexer.
+	path string
+}
+
+// sideBySideDiff is a unified diff rearranged into aligned old/new columns.
+type sideBySideDiff struct {
+	rows []sideBySideRow
+	// lineMap maps each 0-based line of the unified diff to its 0-based row.
+	lineMap []int
+}
+
+// buildSideBySide pairs the deleted and added lines of every change in a
+// unified diff, padding the shorter side with filler rows so both columns
+// stay aligned. Lines outside hunks (commit header, file headers) are shown
+// on both sides, except "---"/"+++" which land on their own side.
+func buildSideBySide(content string) sideBySideDiff {
+	lines := strings.Split(content, "\n")
+	out := sideBySideDiff{lineMap: make([]int, len(lines))}
+	type pendingLine struct {
+		index int
+		cell  sideBySideCell
+	}
+	var (
+		path   string
+		inHunk bool
+		// combined is the number of marker columns of the current combined
+		// diff hunk, or 0.
+		combined int
+		dels     []pendingLine
+		adds     []pendingLine
+	)
+	codeCell := func(line string) sideBySideCell {
+		return sideBySideCell{text: line[1:], tag: diffLineTag(line, 1), code: true}
+	}
+	filler := sideBySideCell{tag: "diffFiller"}
+	flush := func() {
+		for i := range max(len(dels), len(adds)) {
+			row := sideBySideRow{left: filler, right: filler, path: path}
+			if i < len(dels) {
+				row.left = dels[i].cell
+				out.lineMap[dels[i].index] = len(out.rows)
+			}
+			if i < len(adds) {
+				row.right = adds[i].cell
+				out.lineMap[adds[i].index] = len(out.rows)
+			}
+			out.rows = append(out.rows, row)
+		}
+		dels, adds = dels[:0], adds[:0]
+	}
+	both := func(i int, c sideBySideCell) {
+		out.lineMap[i] = len(out.rows)
+		out.rows = append(out.rows, sideBySideRow{left: c, right: c, path: path})
+	}
+	for i, line := range lines {
+		if inHunk && line != "" {
+			switch line[0] {
+			case '-':
+				if len(adds) > 0 {
+					flush()
+				}
+				dels = append(dels, pendingLine{i, codeCell(line)})
+				continue
+			case '+':
+				adds = append(adds, pendingLine{i, codeCell(line)})
+				continue
+			case '\\':
+				// "\ No newline at end of file" belongs to the line before it.
+				note := pendingLine{i, sideBySideCell{text: line}}
+				switch {
+				case len(adds) > 0:
+					adds = append(adds, note)
+				case len(dels) > 0:
+					dels = append(dels, note)
+				default:
+					both(i, note.cell)
+				}
+				continue
+			case ' ':
+				flush()
+				both(i, codeCell(line))
+				continue
+			}
+		}
+		if !inHunk && strings.HasPrefix(line, "+++ ") {
+			adds = append(adds, pendingLine{i, sideBySideCell{text: line}})
+			continue
+		}
+		flush()
+		switch {
+		case isDiffFileHeader(line):
+			inHunk = false
+			path, _ = diffPathFromLine(line)
+			both(i, sideBySideCell{text: line, tag: "diffHeader"})
+		case strings.HasPrefix(line, "@@"):
+			// Combined diffs have one marker column per parent and cannot be
+			// split into two sides.
+			if cols, _ := diffHunkColumns(line); cols > 1 {
+				inHunk, combined = false, cols
+			} else {
+				inHunk, combined = true, 0
+			}
+			both(i, sideBySideCell{text: line})
+		case !inHunk && strings.HasPrefix(line, "--- "):
+			dels = append(dels, pendingLine{i, sideBySideCell{text: line}})
+		case combined > 0:
+			both(i, sideBySideCell{text: line, tag: diffLineTag(line, combined)})
+		default:
+			inHunk = false
+			both(i, sideBySideCell{text: line})
+		}
+	}
+	flush()
+	return out
+}
+
+// text joins one column of the diff for insertion in a text widget.
+func (d sideBySideDiff) text(right bool) string {
+	var b strings.Builder
+	for i, row := range d.rows {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if right {
+			b.WriteString(row.right.text)
+		} else {
+			b.WriteString(row.left.text)
+		}
+	}
+	return b.String()
+}
+
+// unifiedLine returns the first 1-based line of the unified diff shown on
+// the 1-based row, or 0 when there is none.
+func (d sideBySideDiff) unifiedLine(row int) int {
+	for i, r := range d.lineMap {
+		if r == row-1 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// remapSections converts file section lines of the unified diff to rows.
+func (d sideBySideDiff) remapSections(sections []git.FileSection) []git.FileSection {
+	out := make([]git.FileSection, len(sections))
+	for i, sec := range sections {
+		out[i] = sec
+		if sec.Line > 0 && sec.Line <= len(d.lineMap) {
+			out[i].Line = d.lineMap[sec.Line-1] + 1
+		}
+	}
+	return out
+}
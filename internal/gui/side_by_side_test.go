@@ -0,0 +1,109 @@
+package gui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestBuildSideBySide(t *testing.T) {
+	diff := strings.Join([]string{
+		"commit abc",
+		"diff --git a/main.go b/main.go",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1,4 +1,4 @@",
+		" package main",
+		"-var a = 1",
+		"-var b = 2",
+		"+var a = 10",
+		" ",
+		"+var c = 3",
+		"-last",
+		"\\ No newline at end of file",
+		"+last",
+	}, "\n")
+	split := buildSideBySide(diff)
+
+	type cell struct{ text, tag string }
+	want := [][2]cell{
+		{{"commit abc", ""}, {"commit abc", ""}},
+		{{"diff --git a/main.go b/main.go", "diffHeader"}, {"diff --git a/main.go b/main.go", "diffHeader"}},
+		{{"--- a/main.go", ""}, {"+++ b/main.go", ""}},
+		{{"@@ -1,4 +1,4 @@", ""}, {"@@ -1,4 +1,4 @@", ""}},
+		{{"package main", ""}, {"package main", ""}},
+		{{"var a = 1", "diffDel"}, {"var a = 10", "diffAdd"}},
+		{{"var b = 2", "diffDel"}, {"", "diffFiller"}},
+		{{"", ""}, {"", ""}},
+		{{"", "diffFiller"}, {"var c = 3", "diffAdd"}},
+		{{"last", "diffDel"}, {"last", "diffAdd"}},
+		{{"\\ No newline at end of file", ""}, {"", "diffFiller"}},
+	}
+	if len(split.rows) != len(want) {
+		t.Fatalf("got %d rows, want %d:\n%s\n--\n%s", len(split.rows), len(want), split.text(false), split.text(true))
+	}
+	for i, row := range split.rows {
+		got := [2]cell{{row.left.text, row.left.tag}, {row.right.text, row.right.tag}}
+		if got != want[i] {
+			t.Fatalf("row %d: got %+v, want %+v", i, got, want[i])
+		}
+	}
+	if split.rows[0].path != "" || split.rows[5].path != "main.go" {
+		t.Fatalf("unexpected paths: %q %q", split.rows[0].path, split.rows[5].path)
+	}
+	if !split.rows[5].left.code || split.rows[3].left.code {
+		t.Fatal("only hunk content should be marked as code")
+	}
+	wantMap := []int{0, 1, 2, 2, 3, 4, 5, 6, 5, 7, 8, 9, 10, 9}
+	if !slices.Equal(split.lineMap, wantMap) {
+		t.Fatalf("lineMap: got %v, want %v", split.lineMap, wantMap)
+	}
+	for row, want := range map[int]int{1: 1, 3: 3, 6: 7, 10: 12, 12: 0} {
+		if got := split.unifiedLine(row); got != want {
+			t.Fatalf("unifiedLine(%d) = %d, want %d", row, got, want)
+		}
+	}
+}
+
+func TestSideBySideRemapSections(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/a b/a",
+		"@@ -1,2 +1,2 @@",
+		"-old",
+		"+new",
+		"",
+		"diff --git a/b b/b",
+	}, "\n")
+	split := buildSideBySide(diff)
+	got := split.remapSections([]git.FileSection{{Path: "a", Line: 1}, {Path: "b", Line: 6}, {Path: "x", Line: 99}})
+	want := []git.FileSection{{Path: "a", Line: 1}, {Path: "b", Line: 5}, {Path: "x", Line: 99}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestBuildSideBySideCombined(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --cc main.go",
+		"@@@ -1,1 -1,1 +1,1 @@@",
+		"- ours",
+		" -theirs",
+		"++resolved",
+		"  same",
+	}, "\n")
+	split := buildSideBySide(diff)
+	wantTags := []string{"diffHeader", "", "diffDel", "diffDel", "diffAdd", ""}
+	if len(split.rows) != len(wantTags) {
+		t.Fatalf("got %d rows, want %d", len(split.rows), len(wantTags))
+	}
+	for i, row := range split.rows {
+		if row.left != row.right || row.left.tag != wantTags[i] {
+			t.Fatalf("row %d: got %+v | %+v, want tag %q on both sides", i, row.left, row.right, wantTags[i])
+		}
+	}
+	if split.rows[0].path != "main.go" {
+		t.Fatalf("path = %q, want main.go", split.rows[0].path)
+	}
+}
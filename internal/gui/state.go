@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"context"
 	"sync"
 
 	"github.com/thiagokokada/gitk-go/internal/debounce"
@@ -8,37 +9,160 @@ import (
 	"github.com/thiagokokada/gitk-go/internal/gui/widgets"
 )
 
+// browseState backs the Tree mode of the file panel.
+type browseState struct {
+	active bool
+	// commit is the commit to list and loaded the one whose tree is listed.
+	commit string
+	loaded string
+	gen    int
+	items  []fileTreeItem
+	// shown is the file displayed in the detail pane instead of the patch.
+	shown       string
+	fileGen     int
+	contextItem string
+}
+
+// fileHistoryState is set while the commit list holds the history of a
+// single file.
+type fileHistoryState struct {
+	path string
+	// previous is the log spec to restore when leaving the file history.
+	previous git.LogSpec
+}
+
 type diffState struct {
 	fileSections          []git.FileSection
+	fileSort              fileSortState
 	syntaxTags            map[string]string
 	suppressFileSelection bool
 	skipNextSync          bool
+	// sideBySide is the requested layout; splitShown reports whether the
+	// current content is rendered in the two-column view, which only happens
+	// for diffs.
+	sideBySide bool
+	splitShown bool
+	// content, highlight and sections hold the last rendered diff so that it
+	// can be redrawn when the layout is toggled.
+	content   string
+	highlight bool
+	sections  []git.FileSection
+	split     sideBySideDiff
+	// blameParent and blameCommit are the files offered for blame by the
+	// diff context menu.
+	blameParent blameTarget
+	blameCommit blameTarget
+	// historyPath is the file offered by the "Show history of this file"
+	// entries of the context menus.
+	historyPath string
+	// patch is the file and hunk of the local changes under the diff
+	// context menu.
+	patch localPatchTarget
+	// scroll is the view of the local diff to restore once it is reloaded
+	// after a patch was applied, or "".
+	scroll string
+	// links are the parent and child hashes in the commit header of content.
+	links []git.HeaderLink
+	// compareGen identifies the latest diff between two chosen commits, and
+	// compare holds them while their diff is shown.
+	compareGen int
+	compare    compareTarget
+	// mergeMode selects the diff shown for merge commits and options tune
+	// every diff. Both last for the whole session.
+	mergeMode git.MergeDiffMode
+	options   git.DiffOptions
 
 	mu          sync.Mutex
 	debouncer   *debounce.Debouncer
 	pendingDiff *git.Entry
 	pendingHash string
+	pendingRel  *git.CommitRelations
+	pendingMode git.MergeDiffMode
+	pendingOpts git.DiffOptions
+}
+
+// compareTarget names the two commits of a comparison diff.
+type compareTarget struct {
+	from, to           string
+	fromLabel, toLabel string
 }
 
 type treeState struct {
-	branchLabels      map[string][]string
+	branchLabels map[string][]string
+	// marked is the hash of the commit marked for diffing against.
+	marked            string
 	contextTargetID   string
 	hasMore           bool
 	loadingBatch      bool
 	showLocalUnstaged bool
 	showLocalStaged   bool
+	// conflicts lists the files left unmerged in the working tree.
+	conflicts []string
 
 	graphCanvas *widgets.GraphCanvas
 }
 
 type filterState struct {
-	value string
+	value  string
+	mode   filterMode
+	search searchState
+	// err is the parse error of value in text mode, if any.
+	err error
+	// pushed caches the results of the backend searches (path: and
+	// content: terms) of the text mode query; pushing holds those in flight
+	// and failed records the error of those that failed.
+	pushed  map[git.SearchQuery]map[string]bool
+	pushing map[git.SearchQuery]*filterSearch
+	failed  map[git.SearchQuery]error
+	// searchErr is the error of a failed backend search of a term of value,
+	// which then matches nothing.
+	searchErr error
 
 	mu        sync.Mutex
 	debouncer *debounce.Debouncer
 	pending   string
 }
 
+// searchState holds the results of a diff or path search.
+// filterSearch is a backend search of a text mode query term in flight.
+type filterSearch struct {
+	cancel context.CancelFunc
+}
+
+type searchState struct {
+	query git.SearchQuery
+	// matches is nil while no search results are shown.
+	matches    map[string]bool
+	generation int
+	// cancel stops the search of query while it runs.
+	cancel context.CancelFunc
+	// pendingStep is the direction of a step waiting for more commits to load.
+	pendingStep int
+}
+
+// refsState holds the contents of the refs sidebar.
+type refsState struct {
+	items    []refItem
+	refs     []git.Ref
+	headHash string
+	hidden   bool
+	// sash is the sidebar width to restore when it is shown again.
+	sash          int
+	contextTarget string
+}
+
+type jumpState struct {
+	// hash and label name a commit the user asked to select that is in the
+	// view but not loaded yet.
+	hash  string
+	label string
+	// seq counts the jumps, so that the reachability check of an older one is
+	// dropped.
+	seq int
+	// revision is the last revision entered in the Go to dialog.
+	revision string
+}
+
 type scrollState struct {
 	start float64
 	total int
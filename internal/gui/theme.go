@@ -23,9 +23,20 @@ type colorPalette struct {
 	ThemeName        string
 	DiffAdd          string
 	DiffDel          string
+	DiffAddWord      string
+	DiffDelWord      string
 	DiffHeader       string
+	DiffFiller       string
 	LocalUnstagedRow string
 	LocalStagedRow   string
+	SearchMatchRow   string
+	MarkedRow        string
+	Link             string
+	LineNumber       string
+	// BlameNew and BlameOld are the backgrounds of the newest and oldest
+	// lines in the blame view.
+	BlameNew string
+	BlameOld string
 }
 
 var (
@@ -33,17 +44,35 @@ var (
 		ThemeName:        "azure light",
 		DiffAdd:          "#dff5de",
 		DiffDel:          "#f9d6d5",
+		DiffAddWord:      "#a8e4a6",
+		DiffDelWord:      "#f0a3a1",
 		DiffHeader:       "#e4e4e4",
+		DiffFiller:       "#f0f0f0",
 		LocalUnstagedRow: "#fde2e1",
 		LocalStagedRow:   "#e2f7e1",
+		SearchMatchRow:   "#fff3b0",
+		MarkedRow:        "#e3dcf7",
+		Link:             "#1a56c4",
+		LineNumber:       "#8a8a8a",
+		BlameNew:         "#ffd280",
+		BlameOld:         "#f2f2f2",
 	}
 	darkPalette = colorPalette{
 		ThemeName:        "azure dark",
 		DiffAdd:          "#1c6135",
 		DiffDel:          "#612238",
+		DiffAddWord:      "#2b8f50",
+		DiffDelWord:      "#93344f",
 		DiffHeader:       "#3a3a3a",
+		DiffFiller:       "#2a2a2a",
 		LocalUnstagedRow: "#4a1f23",
 		LocalStagedRow:   "#1f3b2a",
+		SearchMatchRow:   "#5a4a12",
+		MarkedRow:        "#3d3159",
+		Link:             "#8ab4f8",
+		LineNumber:       "#808080",
+		BlameNew:         "#7a5714",
+		BlameOld:         "#2c2c2c",
 	}
 	detectDarkMode = darkmode.IsDarkMode
 )
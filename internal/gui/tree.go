@@ -15,12 +15,12 @@ import (
 func (a *Controller) insertLocalRows() {
 	index := 0
 	if a.state.tree.showLocalUnstaged {
-		vals := []string{"", localUnstagedLabel, "", ""}
+		vals := []string{"", localRowLabel(false, a.state.tree.conflicts), "", ""}
 		a.ui.treeView.Insert("", index, Id(localUnstagedRowID), Values(vals), Tags("localUnstaged"))
 		index++
 	}
 	if a.state.tree.showLocalStaged {
-		vals := []string{"", localStagedLabel, "", ""}
+		vals := []string{"", localRowLabel(true, nil), "", ""}
 		a.ui.treeView.Insert("", index, Id(localStagedRowID), Values(vals), Tags("localStaged"))
 	}
 }
@@ -81,7 +81,7 @@ func (a *Controller) setLocalRowVisibility(staged bool, show bool) {
 }
 
 func (a *Controller) insertSingleLocalRow(staged bool) {
-	label := localRowLabel(staged)
+	label := localRowLabel(staged, a.state.tree.conflicts)
 	tag := localRowTag(staged)
 	index := 0
 	if staged && a.state.tree.showLocalUnstaged {
@@ -98,11 +98,30 @@ func localRowID(staged bool) string {
 	return localUnstagedRowID
 }
 
-func localRowLabel(staged bool) string {
+// localRowLabel is the text of a local changes row. The unstaged row lists
+// the files left with conflicts.
+func localRowLabel(staged bool, conflicts []string) string {
 	if staged {
 		return localStagedLabel
 	}
-	return localUnstagedLabel
+	if len(conflicts) == 0 {
+		return localUnstagedLabel
+	}
+	const maxShown = 3
+	shown := strings.Join(conflicts[:min(len(conflicts), maxShown)], ", ")
+	if len(conflicts) > maxShown {
+		shown += fmt.Sprintf(" and %d more", len(conflicts)-maxShown)
+	}
+	return fmt.Sprintf("%s (conflicts in %s)", localUnstagedLabel, shown)
+}
+
+// updateLocalRowLabel refreshes the text of the unstaged row, if it is shown.
+func (a *Controller) updateLocalRowLabel() {
+	if !a.treeItemExists(localUnstagedRowID) {
+		return
+	}
+	vals := []string{"", localRowLabel(false, a.state.tree.conflicts), "", ""}
+	a.ui.treeView.Item(localUnstagedRowID, Values(vals))
 }
 
 func localRowTag(staged bool) string {
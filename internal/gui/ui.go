@@ -9,6 +9,7 @@ import (
 
 	. "modernc.org/tk9.0"
 
+	"github.com/thiagokokada/gitk-go/internal/git"
 	"github.com/thiagokokada/gitk-go/internal/gui/tkutil"
 	"github.com/thiagokokada/gitk-go/internal/gui/widgets"
 )
@@ -21,8 +22,8 @@ func (a *Controller) buildUI() {
 	controls := a.buildControls()
 	Grid(controls, Row(0), Column(0), Sticky(WE))
 
-	mainPane := a.buildMainPane()
-	Grid(mainPane, Row(1), Column(0), Sticky(NEWS), Padx("4p"), Pady("4p"))
+	refsPane := a.buildRefsPane()
+	Grid(refsPane, Row(1), Column(0), Sticky(NEWS), Padx("4p"), Pady("4p"))
 
 	a.ui.status = App.TLabel(Anchor(W), Relief(SUNKEN), Padding("4p"))
 	Grid(a.ui.status, Row(2), Column(0), Sticky(WE))
@@ -33,43 +34,89 @@ func (a *Controller) buildUI() {
 
 func (a *Controller) buildControls() *TFrameWidget {
 	controls := App.TFrame(Padding("4p"))
-	GridColumnConfigure(controls.Window, 1, Weight(1))
+	GridColumnConfigure(controls.Window, 2, Weight(1))
 
 	a.ui.repoLabel = controls.TLabel(Anchor(W))
 	a.updateRepoLabel()
-	Grid(a.ui.repoLabel, Row(0), Column(0), Columnspan(4), Sticky(W))
+	Grid(a.ui.repoLabel, Row(0), Column(0), Columnspan(5), Sticky(W))
+	a.ui.backButton = controls.TButton(Txt("←"), Width(3), Command(func() { a.navigateHistory(-1) }))
+	Grid(a.ui.backButton, Row(0), Column(5), Sticky(E), Padx("4p"), Pady("0 4p"))
+	a.ui.forwardButton = controls.TButton(Txt("→"), Width(3), Command(func() { a.navigateHistory(1) }))
+	Grid(a.ui.forwardButton, Row(0), Column(6), Sticky(E), Pady("0 4p"))
+	a.updateHistoryButtons()
 
 	Grid(controls.TLabel(Txt("Filter:"), Anchor(E)), Row(1), Column(0), Sticky(E))
+	a.ui.filterMode = controls.TCombobox(
+		Values(filterModeLabels),
+		State("readonly"),
+		Width(22),
+		Textvariable(filterModeText.String()),
+	)
+	Grid(a.ui.filterMode, Row(1), Column(1), Sticky(W), Padx("4p 0"))
+	Bind(a.ui.filterMode, "<<ComboboxSelected>>", Command(a.onFilterModeChanged))
 	a.ui.filterEntry = controls.TEntry(Width(40), Textvariable(""))
-	Grid(a.ui.filterEntry, Row(1), Column(1), Sticky(WE), Padx("4p"))
+	Grid(a.ui.filterEntry, Row(1), Column(2), Sticky(WE), Padx("4p"))
 
-	Bind(a.ui.filterEntry, "<KeyRelease>", Command(func() {
-		a.scheduleFilterApply(a.ui.filterEntry.Textvariable())
-	}))
+	Bind(a.ui.filterEntry, "<KeyRelease>", Command(a.onFilterEntryChanged))
+	Bind(a.ui.filterEntry, "<KeyPress-Return>", Command(a.onFilterEntryReturn))
 
+	prevBtn := controls.TButton(Txt("Previous"), Command(func() { a.stepSearch(-1) }))
+	Grid(prevBtn, Row(1), Column(3), Sticky(E))
+	nextBtn := controls.TButton(Txt("Next"), Command(func() { a.stepSearch(1) }))
+	Grid(nextBtn, Row(1), Column(4), Sticky(E), Padx("4p 0"))
 	clearBtn := controls.TButton(Txt("Clear"), Command(func() {
 		a.ui.filterEntry.Configure(Textvariable(""))
+		a.clearSearch()
 		a.applyFilterImmediate("")
 	}))
-	Grid(clearBtn, Row(1), Column(2), Sticky(E), Padx("4p"))
+	Grid(clearBtn, Row(1), Column(5), Sticky(E), Padx("4p"))
 	a.ui.reloadButton = controls.TButton(Txt("Reload"), Command(a.onReloadButton))
-	Grid(a.ui.reloadButton, Row(1), Column(3), Sticky(E))
+	Grid(a.ui.reloadButton, Row(1), Column(6), Sticky(E))
 	return controls
 }
 
-func (a *Controller) buildMainPane() *TPanedwindowWidget {
-	pane := App.TPanedwindow(Orient(VERTICAL))
-	listArea := pane.TFrame()
+// buildRefsPane puts the refs sidebar left of the commit list and diff.
+func (a *Controller) buildRefsPane() *TPanedwindowWidget {
+	pane := App.TPanedwindow(Orient(HORIZONTAL))
+	a.ui.refsPane = pane
+	sidebar := a.buildRefsSidebar(pane)
+	mainPane := a.buildMainPane(pane)
+	a.state.refs.hidden = a.cfg.layout.HideRefs
+	a.state.refs.sash = a.cfg.layout.RefsSash
+	if !a.state.refs.hidden {
+		pane.Add(sidebar.Window, Weight(0))
+	}
+	pane.Add(mainPane.Window, Weight(1))
+	a.updateRefsMenuLabel()
+	if !a.state.refs.hidden {
+		PostEvent(func() {
+			setInitialSash(pane, false, a.state.refs.sash, "")
+		}, false)
+	}
+	return pane
+}
+
+func (a *Controller) buildMainPane(parent *TPanedwindowWidget) *TPanedwindowWidget {
+	pane := parent.TPanedwindow(Orient(VERTICAL))
+	a.ui.mainPane = pane
+	listPane := pane.TFrame()
 	diffArea := pane.TFrame()
-	pane.Add(listArea.Window)
+	pane.Add(listPane.Window)
 	pane.Add(diffArea.Window)
 
+	GridRowConfigure(listPane.Window, 1, Weight(1))
+	GridColumnConfigure(listPane.Window, 0, Weight(1))
+	banner := a.buildFileHistoryBanner(listPane)
+	Grid(banner, Row(0), Column(0), Sticky(WE))
+	GridRemove(banner.Window)
+	listArea := listPane.TFrame()
+	Grid(listArea, Row(1), Column(0), Sticky(NEWS))
 	a.buildCommitPane(listArea)
 	a.buildDiffPane(diffArea)
 
-	// calculates the first widget to be 25% of the total height of the,
-	// widget, based in the <Configure> event (that triggers whenever the
-	// widget configuration changes)
+	// Start with the commit list at 25% of the height unless a layout was
+	// saved. Sashes can only be placed once the panes have a size, so this
+	// waits for their first <Configure> event.
 	PostEvent(
 		func() {
 			switch runtime.GOOS {
@@ -77,15 +124,8 @@ func (a *Controller) buildMainPane() *TPanedwindowWidget {
 				<-time.After(10 * time.Millisecond)
 			default:
 			}
-			tkutil.MustEval(`
-				bind %[1]s <Configure> {
-					set h [winfo height %[1]s]
-					if {$h > 1} {
-						%[1]s sashpos 0 [expr {round($h * 0.25)}]
-						bind %[1]s <Configure> {}
-					}
-				}
-			`, pane)
+			setInitialSash(pane, true, a.cfg.layout.MainSash, "round($size * 0.25)")
+			setInitialSash(a.ui.diffPane, false, a.cfg.layout.DiffSash, "")
 		}, false,
 	)
 
@@ -128,6 +168,7 @@ func (a *Controller) buildCommitPane(listArea *TFrameWidget) {
 	a.ui.treeView.Heading("commit", Txt("Commit"))
 	a.ui.treeView.Heading("author", Txt("Author"))
 	a.ui.treeView.Heading("date", Txt("Date"))
+	a.applyColumnLayout()
 	unstagedColor := a.theme.palette.LocalUnstagedRow
 	if unstagedColor == "" {
 		unstagedColor = "#fde2e1"
@@ -138,6 +179,16 @@ func (a *Controller) buildCommitPane(listArea *TFrameWidget) {
 	}
 	a.ui.treeView.TagConfigure("localUnstaged", Background(unstagedColor))
 	a.ui.treeView.TagConfigure("localStaged", Background(stagedColor))
+	matchColor := a.theme.palette.SearchMatchRow
+	if matchColor == "" {
+		matchColor = lightPalette.SearchMatchRow
+	}
+	a.ui.treeView.TagConfigure("searchMatch", Background(matchColor))
+	markedColor := a.theme.palette.MarkedRow
+	if markedColor == "" {
+		markedColor = lightPalette.MarkedRow
+	}
+	a.ui.treeView.TagConfigure(markedRowTag, Background(markedColor))
 	Grid(a.ui.treeView, Row(0), Column(0), Sticky(NEWS))
 	Grid(treeScroll, Row(0), Column(1), Sticky(NS))
 	treeScroll.Configure(Command(func(e *Event) {
@@ -170,44 +221,56 @@ func (a *Controller) buildCommitPane(listArea *TFrameWidget) {
 }
 
 func (a *Controller) buildDiffPane(diffArea *TFrameWidget) {
-	GridRowConfigure(diffArea.Window, 0, Weight(1))
+	GridRowConfigure(diffArea.Window, 1, Weight(1))
 	GridColumnConfigure(diffArea.Window, 0, Weight(1))
 
+	toolbar := a.buildDiffToolbar(diffArea)
+	Grid(toolbar, Row(0), Column(0), Sticky(WE), Pady("0 4p"))
 	diffPane := diffArea.TPanedwindow(Orient(HORIZONTAL))
-	Grid(diffPane, Row(0), Column(0), Sticky(NEWS))
+	a.ui.diffPane = diffPane
+	Grid(diffPane, Row(1), Column(0), Sticky(NEWS))
 
 	textFrame := diffPane.TFrame()
 	fileFrame := diffPane.TFrame()
 	diffPane.Add(textFrame.Window, Weight(5))
 	diffPane.Add(fileFrame.Window, Weight(1))
 
-	GridRowConfigure(fileFrame.Window, 0, Weight(1))
-	GridColumnConfigure(fileFrame.Window, 0, Weight(1))
 	GridRowConfigure(textFrame.Window, 0, Weight(1))
 	GridColumnConfigure(textFrame.Window, 0, Weight(1))
 
-	detailYScroll := textFrame.TScrollbar(Command(func(e *Event) { e.Yview(a.ui.diffDetail) }))
-	detailXScroll := textFrame.TScrollbar(Orient(HORIZONTAL), Command(func(e *Event) { e.Xview(a.ui.diffDetail) }))
-	a.ui.diffDetail = textFrame.Text(Wrap(NONE), Font(CourierFont(), 11), Exportselection(false), Tabs("1c"))
+	a.ui.diffUnified = textFrame.TFrame()
+	GridRowConfigure(a.ui.diffUnified.Window, 0, Weight(1))
+	GridColumnConfigure(a.ui.diffUnified.Window, 0, Weight(1))
+	Grid(a.ui.diffUnified, Row(0), Column(0), Sticky(NEWS))
+	detailYScroll := a.ui.diffUnified.TScrollbar(Command(func(e *Event) { e.Yview(a.ui.diffDetail) }))
+	detailXScroll := a.ui.diffUnified.TScrollbar(Orient(HORIZONTAL), Command(func(e *Event) { e.Xview(a.ui.diffDetail) }))
+	a.ui.diffDetail = a.newDiffText(a.ui.diffUnified)
 	a.ui.diffDetail.Configure(Yscrollcommand(func(e *Event) {
 		e.ScrollSet(detailYScroll)
 		a.onDiffScrolled()
 	}))
 	a.ui.diffDetail.Configure(Xscrollcommand(func(e *Event) { e.ScrollSet(detailXScroll) }))
-	addColor := a.theme.palette.DiffAdd
-	if addColor == "" {
-		addColor = lightPalette.DiffAdd
-	}
-	delColor := a.theme.palette.DiffDel
-	if delColor == "" {
-		delColor = lightPalette.DiffDel
-	}
-	headerColor := a.theme.palette.DiffHeader
-	if headerColor == "" {
-		headerColor = lightPalette.DiffHeader
+	Grid(a.ui.diffDetail, Row(0), Column(0), Sticky(NEWS))
+	Grid(detailYScroll, Row(0), Column(1), Sticky(NS))
+	Grid(detailXScroll, Row(1), Column(0), Sticky(WE))
+	a.buildSplitDiff(textFrame)
+	a.initDiffContextMenu()
+	a.bindDiffContextMenu()
+
+	a.buildFileList(fileFrame)
+}
+
+// newDiffText creates a read-only text widget configured with the diff tags.
+func (a *Controller) newDiffText(parent *TFrameWidget) *TextWidget {
+	text := parent.Text(Wrap(NONE), Font(CourierFont(), 11), Exportselection(false), Tabs("1c"))
+	color := func(c, fallback string) string {
+		if c == "" {
+			return fallback
+		}
+		return c
 	}
-	selBg := a.ui.diffDetail.Selectbackground()
-	selFg := a.ui.diffDetail.Selectforeground()
+	selBg := text.Selectbackground()
+	selFg := text.Selectforeground()
 	tagOpts := func(bg string) []Opt {
 		opts := []Opt{Background(bg)}
 		if selBg != "" {
@@ -218,23 +281,61 @@ func (a *Controller) buildDiffPane(diffArea *TFrameWidget) {
 		}
 		return opts
 	}
-	a.ui.diffDetail.TagConfigure("diffAdd", tagOpts(addColor)...)
-	a.ui.diffDetail.TagConfigure("diffDel", tagOpts(delColor)...)
-	a.ui.diffDetail.TagConfigure("diffHeader", tagOpts(headerColor)...)
-	Grid(a.ui.diffDetail, Row(0), Column(0), Sticky(NEWS))
-	Grid(detailYScroll, Row(0), Column(1), Sticky(NS))
-	Grid(detailXScroll, Row(1), Column(0), Sticky(WE))
-	a.ui.diffDetail.Configure(State("disabled"))
-	a.initDiffContextMenu()
-	a.bindDiffContextMenu()
+	text.TagConfigure("diffAdd", tagOpts(color(a.theme.palette.DiffAdd, lightPalette.DiffAdd))...)
+	text.TagConfigure("diffDel", tagOpts(color(a.theme.palette.DiffDel, lightPalette.DiffDel))...)
+	text.TagConfigure("diffHeader", tagOpts(color(a.theme.palette.DiffHeader, lightPalette.DiffHeader))...)
+	text.TagConfigure("diffFiller", tagOpts(color(a.theme.palette.DiffFiller, lightPalette.DiffFiller))...)
+	// Configured after the line tags so that they take precedence.
+	text.TagConfigure("diffAddWord", tagOpts(color(a.theme.palette.DiffAddWord, lightPalette.DiffAddWord))...)
+	text.TagConfigure("diffDelWord", tagOpts(color(a.theme.palette.DiffDelWord, lightPalette.DiffDelWord))...)
+	text.TagConfigure(lineNumberTag, Foreground(color(a.theme.palette.LineNumber, lightPalette.LineNumber)))
+	a.bindCommitLinks(text, color(a.theme.palette.Link, lightPalette.Link))
+	text.Configure(State("disabled"))
+	return text
+}
 
-	fileScroll := fileFrame.TScrollbar()
-	a.ui.diffFileList = fileFrame.Listbox(Exportselection(false), Width(40))
-	a.ui.diffFileList.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(fileScroll) }))
-	Grid(a.ui.diffFileList, Row(0), Column(0), Sticky(NEWS))
-	Grid(fileScroll, Row(0), Column(1), Sticky(NS))
-	fileScroll.Configure(Command(func(e *Event) { e.Yview(a.ui.diffFileList) }))
-	Bind(a.ui.diffFileList, "<<ListboxSelect>>", Command(a.onFileSelectionChanged))
+// buildSplitDiff creates the side-by-side view: old and new contents in two
+// text widgets sharing a vertical scrollbar. It starts hidden.
+func (a *Controller) buildSplitDiff(parent *TFrameWidget) {
+	a.ui.diffSplit = parent.TFrame()
+	GridRowConfigure(a.ui.diffSplit.Window, 0, Weight(1))
+	GridColumnConfigure(a.ui.diffSplit.Window, 0, Weight(1), Uniform("side"))
+	GridColumnConfigure(a.ui.diffSplit.Window, 1, Weight(1), Uniform("side"))
+	Grid(a.ui.diffSplit, Row(0), Column(0), Sticky(NEWS))
+
+	yScroll := a.ui.diffSplit.TScrollbar(Command(func(e *Event) { e.Yview(a.ui.diffLeft) }))
+	leftXScroll := a.ui.diffSplit.TScrollbar(Orient(HORIZONTAL), Command(func(e *Event) { e.Xview(a.ui.diffLeft) }))
+	rightXScroll := a.ui.diffSplit.TScrollbar(Orient(HORIZONTAL), Command(func(e *Event) { e.Xview(a.ui.diffRight) }))
+	a.ui.diffLeft = a.newDiffText(a.ui.diffSplit)
+	a.ui.diffRight = a.newDiffText(a.ui.diffSplit)
+	// Both columns have the same number of lines, so keeping their first
+	// visible fraction equal keeps the rows aligned.
+	a.ui.diffLeft.Configure(Yscrollcommand(func(e *Event) {
+		e.ScrollSet(yScroll)
+		a.syncSplitScroll(a.ui.diffLeft, a.ui.diffRight)
+		a.onDiffScrolled()
+	}))
+	a.ui.diffRight.Configure(Yscrollcommand(func(e *Event) {
+		a.syncSplitScroll(a.ui.diffRight, a.ui.diffLeft)
+	}))
+	a.ui.diffLeft.Configure(Xscrollcommand(func(e *Event) { e.ScrollSet(leftXScroll) }))
+	a.ui.diffRight.Configure(Xscrollcommand(func(e *Event) { e.ScrollSet(rightXScroll) }))
+	Grid(a.ui.diffLeft, Row(0), Column(0), Sticky(NEWS), Padx("0 2p"))
+	Grid(a.ui.diffRight, Row(0), Column(1), Sticky(NEWS))
+	Grid(yScroll, Row(0), Column(2), Sticky(NS))
+	Grid(leftXScroll, Row(1), Column(0), Sticky(WE), Padx("0 2p"))
+	Grid(rightXScroll, Row(1), Column(1), Sticky(WE))
+	GridRemove(a.ui.diffSplit.Window)
+}
+
+func (a *Controller) syncSplitScroll(from, to *TextWidget) {
+	_, err := tkutil.Eval(`
+		set f [lindex [%[1]s yview] 0]
+		if {$f != [lindex [%[2]s yview] 0]} { %[2]s yview moveto $f }
+	`, from, to)
+	if err != nil {
+		slog.Error("sync diff scroll", slog.Any("error", err))
+	}
 }
 
 func (a *Controller) showInitialLoadingRow() {
@@ -253,6 +354,26 @@ func (a *Controller) initTreeContextMenu() {
 	menu := App.Menu(Tearoff(false))
 	item := menu.AddCommand(Command(a.copySelectedCommitReference))
 	menu.EntryConfigure(item, Lbl("Copy commit reference"))
+	menu.AddSeparator()
+	a.ui.treeMenuItems = map[string]*MenuItem{
+		"mark":       menu.AddCommand(Lbl("Mark this commit"), Command(a.markContextCommit)),
+		"toMarked":   menu.AddCommand(Lbl("Diff this → marked"), Command(func() { a.diffContextWithMarked(false) })),
+		"fromMarked": menu.AddCommand(Lbl("Diff marked → this"), Command(func() { a.diffContextWithMarked(true) })),
+		"unmark":     menu.AddCommand(Lbl("Unmark"), Command(a.clearMarkedCommit)),
+	}
+	menu.AddSeparator()
+	menu.AddCommand(Lbl("Check out this commit (detached HEAD)"), Command(func() {
+		hash := a.contextCommitHash()
+		a.checkoutDetachedAsync(hash, shortHash(hash))
+	}))
+	menu.AddCommand(Lbl("Create branch here..."), Command(func() { a.promptCreateBranch(a.contextCommitHash(), false) }))
+	menu.AddCommand(Lbl("Create tag here..."), Command(func() { a.promptCreateTag(a.contextCommitHash()) }))
+	menu.AddSeparator()
+	menu.AddCommand(Lbl("Cherry-pick this commit"), Command(a.cherryPickContextCommit))
+	menu.AddCommand(Lbl("Revert this commit"), Command(a.revertContextCommit))
+	menu.AddCommand(Lbl("Reset HEAD to here (soft)"), Command(func() { a.resetToContextCommit(git.ResetSoft) }))
+	menu.AddCommand(Lbl("Reset HEAD to here (mixed)"), Command(func() { a.resetToContextCommit(git.ResetMixed) }))
+	menu.AddCommand(Lbl("Reset HEAD to here (hard)"), Command(func() { a.resetToContextCommit(git.ResetHard) }))
 	a.ui.treeContextMenu = menu
 }
 
@@ -275,10 +396,13 @@ func (a *Controller) showTreeContextMenu(e *Event) {
 	a.ui.treeView.Selection("set", item)
 	a.ui.treeView.Focus(item)
 	a.state.tree.contextTargetID = item
+	a.updateMarkMenuItems()
 	Popup(a.ui.treeContextMenu.Window, e.XRoot, e.YRoot, nil)
 }
 
-func (a *Controller) copySelectedCommitReference() {
+// contextCommit returns the commit the tree context menu was opened on,
+// falling back to the selected one.
+func (a *Controller) contextCommit() *git.Commit {
 	id := a.state.tree.contextTargetID
 	if id == "" {
 		if sel := a.ui.treeView.Selection(""); len(sel) > 0 {
@@ -287,13 +411,21 @@ func (a *Controller) copySelectedCommitReference() {
 	}
 	idx, ok := a.treeCommitIndex(id)
 	if !ok {
-		return
+		return nil
 	}
 	entry := a.data.visible[idx]
-	if entry == nil || entry.Commit == nil {
+	if entry == nil {
+		return nil
+	}
+	return entry.Commit
+}
+
+func (a *Controller) copySelectedCommitReference() {
+	commit := a.contextCommit()
+	if commit == nil {
 		return
 	}
-	hash := entry.Commit.Hash
+	hash := commit.Hash
 	ClipboardClear()
 	ClipboardAppend(hash)
 	a.setStatus(fmt.Sprintf("Copied %s to clipboard.", hash))
@@ -308,21 +440,48 @@ func (a *Controller) initDiffContextMenu() {
 	menu := App.Menu(Tearoff(false))
 	menu.AddCommand(Lbl("Copy selection"), Command(func() { a.copyDetailSelection(false) }))
 	menu.AddCommand(Lbl("Copy selection without +/- markers"), Command(func() { a.copyDetailSelection(true) }))
+	menu.AddSeparator()
+	a.ui.diffMenuItems = map[string]*MenuItem{
+		"blameParent": menu.AddCommand(Lbl("Blame this file at parent"), Command(func() { a.blameDiffContextFile(true) })),
+		"blameCommit": menu.AddCommand(Lbl("Blame this file at commit"), Command(func() { a.blameDiffContextFile(false) })),
+		"history":     menu.AddCommand(Lbl("Show history of this file"), Command(a.showContextFileHistory)),
+	}
+	menu.AddSeparator()
+	for _, item := range []struct {
+		key, label string
+		action     git.PatchAction
+		file       bool
+	}{
+		{"stageHunk", "Stage this hunk", git.PatchStage, false},
+		{"stageFile", "Stage this file", git.PatchStage, true},
+		{"unstageHunk", "Unstage this hunk", git.PatchUnstage, false},
+		{"unstageFile", "Unstage this file", git.PatchUnstage, true},
+		{"discardHunk", "Discard this hunk...", git.PatchDiscard, false},
+		{"discardFile", "Discard this file...", git.PatchDiscard, true},
+	} {
+		a.ui.diffMenuItems[item.key] = menu.AddCommand(Lbl(item.label), Command(func() {
+			a.applyContextPatch(item.action, item.file)
+		}))
+	}
 	a.ui.diffContextMenu = menu
 }
 
 func (a *Controller) bindDiffContextMenu() {
-	handler := func(e *Event) {
-		a.showDiffContextMenu(e)
+	for _, text := range a.diffTexts() {
+		handler := func(e *Event) {
+			a.showDiffContextMenu(text, e)
+		}
+		Bind(text, "<Button-2>", Command(handler))
+		Bind(text, "<Button-3>", Command(handler))
 	}
-	Bind(a.ui.diffDetail, "<Button-2>", Command(handler))
-	Bind(a.ui.diffDetail, "<Button-3>", Command(handler))
 }
 
-func (a *Controller) showDiffContextMenu(e *Event) {
+func (a *Controller) showDiffContextMenu(text *TextWidget, e *Event) {
 	if e == nil {
 		return
 	}
+	a.updateDiffFileItems(text, e)
+	a.updateDiffPatchItems(text, e)
 	Popup(a.ui.diffContextMenu.Window, e.XRoot, e.YRoot, nil)
 }
 
@@ -5,16 +5,55 @@ import (
 )
 
 type appWidgets struct {
-	status          *TLabelWidget
-	repoLabel       *TLabelWidget
-	filterEntry     *TEntryWidget
-	reloadButton    *TButtonWidget
-	graphCanvas     *CanvasWidget
-	treeView        *TTreeviewWidget
-	treeContextMenu *MenuWidget
-	diffDetail      *TextWidget
-	diffFileList    *ListboxWidget
-	diffContextMenu *MenuWidget
-	shortcutsWindow *ToplevelWidget
-	branchWindow    *ToplevelWidget
+	status            *TLabelWidget
+	repoLabel         *TLabelWidget
+	filterMode        *TComboboxWidget
+	filterEntry       *TEntryWidget
+	reloadButton      *TButtonWidget
+	backButton        *TButtonWidget
+	forwardButton     *TButtonWidget
+	refsPane          *TPanedwindowWidget
+	refsSidebar       *TFrameWidget
+	refsTree          *TTreeviewWidget
+	refsContextMenu   *MenuWidget
+	refsMenuItems     map[string]*MenuItem
+	refsToggleItem    *MenuItem
+	mainPane          *TPanedwindowWidget
+	diffPane          *TPanedwindowWidget
+	graphCanvas       *CanvasWidget
+	treeView          *TTreeviewWidget
+	fileHistoryBanner *TFrameWidget
+	fileHistoryLabel  *TLabelWidget
+	treeContextMenu   *MenuWidget
+	treeMenuItems     map[string]*MenuItem
+	diffDetail        *TextWidget
+	diffUnified       *TFrameWidget
+	diffSplit         *TFrameWidget
+	diffLeft          *TextWidget
+	diffRight         *TextWidget
+	diffFileList      *TTreeviewWidget
+	diffFileSummary   *TLabelWidget
+	fileListMenu      *MenuWidget
+	fileMode          *TComboboxWidget
+	fileTable         *TFrameWidget
+	fileTreeFrame     *TFrameWidget
+	fileTree          *TTreeviewWidget
+	fileTreeMenu      *MenuWidget
+	fileMenuItems     map[string]*MenuItem
+	diffContextMenu   *MenuWidget
+	diffMenuItems     map[string]*MenuItem
+	mergeDiffMode     *TComboboxWidget
+	diffWhitespace    *TComboboxWidget
+	diffBlankLines    *TCheckbuttonWidget
+	diffContext       *TSpinboxWidget
+	diffRenames       *TComboboxWidget
+	diffCopies        *TComboboxWidget
+	diffAlgorithm     *TComboboxWidget
+	viewMenu          *MenuWidget
+	sideBySideItem    *MenuItem
+	shortcutsWindow   *ToplevelWidget
+	branchWindow      *ToplevelWidget
+	settingsWindow    *ToplevelWidget
+	gotoWindow        *ToplevelWidget
+	refWindow         *ToplevelWidget
 }
@@ -10,6 +10,7 @@ import (
 
 type treeRow struct {
 	ID     string
+	Hash   string
 	Graph  string
 	Commit string
 	Author string
@@ -29,6 +30,7 @@ func buildTreeRows(entries []*git.Entry, labels map[string][]string, graphCanvas
 		graph := formatGraphValue(entry, labels[entry.Commit.Hash], graphCanvas)
 		rows = append(rows, treeRow{
 			ID:     strconv.Itoa(i),
+			Hash:   entry.Commit.Hash,
 			Graph:  graph,
 			Commit: msg,
 			Author: author,
@@ -43,16 +45,23 @@ func commitListColumns(entry *git.Entry) (msg, author, when string) {
 	if len(firstLine) > 80 {
 		firstLine = firstLine[:77] + "..."
 	}
-	hash := entry.Commit.Hash
-	if len(hash) > 7 {
-		hash = hash[:7]
+	msg = fmt.Sprintf("%s  %s", shortHash(entry.Commit.Hash), firstLine)
+	if entry.Commit.Path != "" {
+		// Following a file: show its name at this commit.
+		msg += "  (" + entry.Commit.Path + ")"
 	}
-	msg = fmt.Sprintf("%s  %s", hash, firstLine)
 	author = fmt.Sprintf("%s <%s>", entry.Commit.Author.Name, entry.Commit.Author.Email)
 	when = entry.Commit.Committer.When.Format("2006-01-02 15:04")
 	return msg, author, when
 }
 
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
 func formatGraphValue(entry *git.Entry, labels []string, graphCanvas bool) string {
 	graph := strings.TrimRight(entry.Graph, " ")
 	if graph == "" {
@@ -72,14 +81,13 @@ func formatLabelSuffix(labels []string) string {
 	return fmt.Sprintf(" [%s]", strings.Join(labels, ", "))
 }
 
-func filterEntries(entries []*git.Entry, query string) []*git.Entry {
-	q := strings.ToLower(strings.TrimSpace(query))
-	if q == "" {
+func filterEntries(entries []*git.Entry, query filterQuery, results map[git.SearchQuery]map[string]bool, failed map[git.SearchQuery]error) []*git.Entry {
+	if query.empty() {
 		return entries
 	}
 	var filtered []*git.Entry
 	for _, entry := range entries {
-		if strings.Contains(entry.SearchText, q) {
+		if query.match(entry, results, failed) {
 			filtered = append(filtered, entry)
 		}
 	}
@@ -55,6 +55,8 @@ type GraphCanvasDrawInput struct {
 	Visible []*git.Entry
 	Labels  map[string][]string
 	Dark    bool
+	// Marked is the hash of the commit to draw a box around.
+	Marked string
 }
 
 type graphOverlayState struct {
@@ -73,6 +75,12 @@ type graphCanvasDrawState struct {
 	maxCols     int
 }
 
+type graphCanvasRow struct {
+	idx   int
+	yTop  int
+	entry *git.Entry
+}
+
 type graphCanvasDrawPlan struct {
 	contentHeight int
 	rowHeight     int
@@ -122,21 +130,43 @@ func (g *GraphCanvas) Draw(input GraphCanvasDrawInput) {
 	if !ok {
 		return
 	}
+	var rows []graphCanvasRow
 	y := plan.startY
 	for idx := plan.firstIdx; idx < len(plan.visible); idx++ {
 		if plan.contentHeight > 0 && y > plan.contentHeight {
 			break
 		}
-		entry := plan.visible[idx]
-		if entry != nil {
-			rowLabels := []string(nil)
-			if entry.Commit != nil && plan.labels != nil {
-				rowLabels = plan.labels[entry.Commit.Hash]
-			}
-			g.drawGraphRow(entry.Graph, rowLabels, y, plan.rowHeight, idx == plan.selectedIdx)
+		if entry := plan.visible[idx]; entry != nil {
+			rows = append(rows, graphCanvasRow{idx: idx, yTop: y, entry: entry})
 		}
 		y += plan.rowHeight
 	}
+	// Draw in passes so edges leading into the next row never cover its
+	// selection highlight, nodes or labels.
+	for _, row := range rows {
+		if row.idx == plan.selectedIdx {
+			g.drawGraphSelection(row.yTop, plan.rowHeight)
+		}
+	}
+	if plan.firstIdx > 0 {
+		// Edges entering the first visible row start at the row scrolled above it.
+		if prev := plan.visible[plan.firstIdx-1]; prev != nil {
+			g.drawGraphEdges(graphRowForEntry(prev), plan.startY-plan.rowHeight, plan.rowHeight)
+		}
+	}
+	for _, row := range rows {
+		g.drawGraphEdges(graphRowForEntry(row.entry), row.yTop, plan.rowHeight)
+	}
+	for _, row := range rows {
+		rowLabels := []string(nil)
+		if row.entry.Commit != nil && plan.labels != nil {
+			rowLabels = plan.labels[row.entry.Commit.Hash]
+		}
+		g.drawGraphNode(graphRowForEntry(row.entry), rowLabels, row.yTop, plan.rowHeight)
+		if row.entry.Commit != nil && row.entry.Commit.Hash == g.input.Marked {
+			g.drawGraphMark(graphRowForEntry(row.entry), row.yTop, plan.rowHeight)
+		}
+	}
 }
 
 func (g *GraphCanvas) planGraphCanvasDraw() (graphCanvasDrawPlan, bool) {
@@ -426,70 +456,97 @@ type graphLabelStyle struct {
 	text string
 }
 
-func (g *GraphCanvas) drawGraphRow(
-	raw string,
-	labels []string,
-	yTop int,
-	height int,
-	selected bool,
-) {
-	tokens := parseGraphTokens(raw, g.draw.maxCols)
-	if len(tokens) == 0 {
-		return
+func (g *GraphCanvas) drawGraphSelection(yTop int, height int) {
+	fill := "#cfe7ff"
+	if g.draw.dark {
+		fill = "#253446"
 	}
-	if selected {
-		fill := "#cfe7ff"
-		if g.draw.dark {
-			fill = "#253446"
+	g.draw.canvas.CreateRectangle(
+		0, yTop,
+		g.draw.canvasWidth, yTop+height,
+		Fill(fill),
+		Width(0),
+	)
+}
+
+func (g *GraphCanvas) drawGraphEdges(row *git.GraphRow, yTop int, height int) {
+	colors := graphCanvasLaneColors(g.draw.dark)
+	y1 := graphRowMidY(yTop, height)
+	y2 := y1 + height
+	for _, edge := range row.Edges {
+		if edge.From >= g.draw.maxCols || edge.To >= g.draw.maxCols {
+			continue
 		}
-		g.draw.canvas.CreateRectangle(
-			0, yTop,
-			g.draw.canvasWidth, yTop+height,
-			Fill(fill),
-			Width(0),
-		)
+		x1 := graphLaneX(edge.From)
+		x2 := graphLaneX(edge.To)
+		color := colors[edge.Color%len(colors)]
+		if x1 == x2 {
+			g.draw.canvas.CreateLine(x1, y1, x2, y2, Width(graphCanvasLineWidth), Fill(color))
+			continue
+		}
+		// Merges leave the node diagonally and then run down the parent's lane;
+		// lanes that fork or shift keep going straight and bend into the next row.
+		yBend := y1 + height/2
+		if edge.Kind == git.GraphEdgeMerge {
+			g.draw.canvas.CreateLine(x1, y1, x2, yBend, Width(graphCanvasLineWidth), Fill(color))
+			g.draw.canvas.CreateLine(x2, yBend, x2, y2, Width(graphCanvasLineWidth), Fill(color))
+			continue
+		}
+		g.draw.canvas.CreateLine(x1, y1, x1, yBend, Width(graphCanvasLineWidth), Fill(color))
+		g.draw.canvas.CreateLine(x1, yBend, x2, y2, Width(graphCanvasLineWidth), Fill(color))
+	}
+}
+
+func (g *GraphCanvas) drawGraphNode(row *git.GraphRow, labels []string, yTop int, height int) {
+	if row.Column >= g.draw.maxCols {
+		return
 	}
 	yMid := graphRowMidY(yTop, height)
 	radius := min(graphCanvasLaneSpacing/2, max(2, height/3))
 
 	colors := graphCanvasLaneColors(g.draw.dark)
-	head := containsPrefix(labels, "HEAD")
-	nodeX := graphCanvasLaneMargin + graphCanvasLaneSpacing/2
-	nodeColor := colors[0]
-	for col, token := range tokens {
-		x := graphCanvasLaneMargin + col*graphCanvasLaneSpacing + graphCanvasLaneSpacing/2
-		color := colors[col%len(colors)]
-		switch token {
-		case "|":
-			g.draw.canvas.CreateLine(x, yTop, x, yTop+height, Width(graphCanvasLineWidth), Fill(color))
-		case "*":
-			nodeX = x
-			nodeColor = color
-			g.draw.canvas.CreateLine(x, yTop, x, yMid-radius, Width(graphCanvasLineWidth), Fill(color))
-			g.draw.canvas.CreateLine(x, yMid+radius, x, yTop+height, Width(graphCanvasLineWidth), Fill(color))
-			fill := "white"
-			if g.draw.dark {
-				fill = "#1e1e1e"
-			}
-			if head {
-				fill = "#ffd75e"
-				if g.draw.dark {
-					fill = "#b58900"
-				}
-			}
-			g.draw.canvas.CreateOval(
-				x-radius, yMid-radius,
-				x+radius, yMid+radius,
-				Fill(fill),
-				Outline(color),
-				Width(1),
-			)
-		default:
+	nodeX := graphLaneX(row.Column)
+	nodeColor := colors[row.Color%len(colors)]
+	fill := "white"
+	if g.draw.dark {
+		fill = "#1e1e1e"
+	}
+	if containsPrefix(labels, "HEAD") {
+		fill = "#ffd75e"
+		if g.draw.dark {
+			fill = "#b58900"
 		}
 	}
+	g.draw.canvas.CreateOval(
+		nodeX-radius, yMid-radius,
+		nodeX+radius, yMid+radius,
+		Fill(fill),
+		Outline(nodeColor),
+		Width(1),
+	)
 	g.drawGraphLabels(labels, nodeX, yMid, radius, nodeColor)
 }
 
+// drawGraphMark draws a box around the node of the marked commit, like gitk.
+func (g *GraphCanvas) drawGraphMark(row *git.GraphRow, yTop int, height int) {
+	if row.Column >= g.draw.maxCols {
+		return
+	}
+	yMid := graphRowMidY(yTop, height)
+	size := min(graphCanvasLaneSpacing/2, max(2, height/3)) + 2
+	nodeX := graphLaneX(row.Column)
+	color := "black"
+	if g.draw.dark {
+		color = "white"
+	}
+	g.draw.canvas.CreateRectangle(
+		nodeX-size, yMid-size,
+		nodeX+size, yMid+size,
+		Outline(color),
+		Width(2),
+	)
+}
+
 func (g *GraphCanvas) drawGraphLabels(
 	labels []string,
 	nodeX int,
@@ -0,0 +1,17 @@
+package widgets
+
+import "github.com/thiagokokada/gitk-go/internal/git"
+
+// graphRowForEntry returns the lane layout of entry. Keep the graph legible even
+// if the backend didn't populate a layout yet by drawing a lone node, matching
+// the list view's textual fallback.
+func graphRowForEntry(entry *git.Entry) *git.GraphRow {
+	if entry == nil || entry.GraphRow == nil {
+		return &git.GraphRow{Lanes: 1}
+	}
+	return entry.GraphRow
+}
+
+func graphLaneX(col int) int {
+	return graphCanvasLaneMargin + col*graphCanvasLaneSpacing + graphCanvasLaneSpacing/2
+}
@@ -0,0 +1,33 @@
+package widgets
+
+import (
+	"testing"
+
+	"github.com/thiagokokada/gitk-go/internal/git"
+)
+
+func TestGraphRowForEntry(t *testing.T) {
+	t.Run("fallback", func(t *testing.T) {
+		for _, entry := range []*git.Entry{nil, {Graph: "*"}} {
+			got := graphRowForEntry(entry)
+			if got.Column != 0 || got.Lanes != 1 || len(got.Edges) != 0 {
+				t.Fatalf("expected lone node, got %+v", got)
+			}
+		}
+	})
+	t.Run("layout", func(t *testing.T) {
+		row := &git.GraphRow{Column: 1, Lanes: 2}
+		if got := graphRowForEntry(&git.Entry{GraphRow: row}); got != row {
+			t.Fatalf("expected entry layout, got %+v", got)
+		}
+	})
+}
+
+func TestGraphLaneX(t *testing.T) {
+	if got := graphLaneX(0); got != graphCanvasLaneMargin+graphCanvasLaneSpacing/2 {
+		t.Fatalf("unexpected x for first lane: %d", got)
+	}
+	if got := graphLaneX(2) - graphLaneX(1); got != graphCanvasLaneSpacing {
+		t.Fatalf("expected lane spacing %d, got %d", graphCanvasLaneSpacing, got)
+	}
+}
@@ -0,0 +1,151 @@
+package gui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/thiagokokada/gitk-go/internal/myers"
+)
+
+// maxWordDiffTokens skips intra-line diffs of very long lines (minified
+// files, generated data) where the result is noise anyway.
+const maxWordDiffTokens = 1000
+
+// wordSpan is a changed run of characters inside a removed or added line.
+// line is the 0-based line of the unified diff; start and end are character
+// columns in that line, including the +/- marker.
+type wordSpan struct {
+	line  int
+	start int
+	end   int
+	tag   string
+}
+
+// wordDiffSpans pairs the removed and added lines of every change in a
+// unified diff (the n-th removed line with the n-th added one) and returns
+// the words that differ between them.
+func wordDiffSpans(content string) []wordSpan {
+	var (
+		spans  []wordSpan
+		inHunk bool
+		dels   []int
+		adds   []int
+	)
+	lines := strings.Split(content, "\n")
+	flush := func() {
+		for i := range min(len(dels), len(adds)) {
+			spans = append(spans, wordDiffPair(lines, dels[i], adds[i])...)
+		}
+		dels, adds = dels[:0], adds[:0]
+	}
+	for i, line := range lines {
+		if inHunk && line != "" {
+			switch line[0] {
+			case '-':
+				if len(adds) > 0 {
+					flush()
+				}
+				dels = append(dels, i)
+				continue
+			case '+':
+				adds = append(adds, i)
+				continue
+			case '\\':
+				continue
+			case ' ':
+				flush()
+				continue
+			}
+		}
+		flush()
+		inHunk = strings.HasPrefix(line, "@@") && !strings.HasPrefix(line, "@@@")
+	}
+	flush()
+	return spans
+}
+
+func wordDiffPair(lines []string, del, add int) []wordSpan {
+	a := diffWords(lines[del][1:])
+	b := diffWords(lines[add][1:])
+	if len(a) > maxWordDiffTokens || len(b) > maxWordDiffTokens {
+		return nil
+	}
+	ops := myers.Diff(a, b)
+	// Lines sharing nothing but whitespace are rewrites; the line background
+	// already says everything changed.
+	common := false
+	for _, op := range ops {
+		if op.Kind != myers.Equal {
+			continue
+		}
+		for _, word := range a[op.AStart:op.AEnd] {
+			if strings.TrimSpace(word) != "" {
+				common = true
+			}
+		}
+	}
+	if !common {
+		return nil
+	}
+	var spans []wordSpan
+	appendSpan := func(line int, words []string, start, end int, tag string) {
+		// Offset by one for the +/- marker.
+		col := 1
+		for _, word := range words[:start] {
+			col += utf8.RuneCountInString(word)
+		}
+		width := 0
+		for _, word := range words[start:end] {
+			width += utf8.RuneCountInString(word)
+		}
+		if n := len(spans); n > 0 && spans[n-1].line == line && spans[n-1].end == col {
+			spans[n-1].end += width
+			return
+		}
+		spans = append(spans, wordSpan{line: line, start: col, end: col + width, tag: tag})
+	}
+	for _, op := range ops {
+		switch op.Kind {
+		case myers.Delete:
+			appendSpan(del, a, op.AStart, op.AEnd, "diffDelWord")
+		case myers.Insert:
+			appendSpan(add, b, op.BStart, op.BEnd, "diffAddWord")
+		}
+	}
+	return spans
+}
+
+// diffWords splits s into runs of letters and digits, runs of whitespace and
+// single punctuation characters.
+func diffWords(s string) []string {
+	var words []string
+	for s != "" {
+		r, size := utf8.DecodeRuneInString(s)
+		class := wordClass(r)
+		end := size
+		if class != 0 {
+			for end < len(s) {
+				next, n := utf8.DecodeRuneInString(s[end:])
+				if wordClass(next) != class {
+					break
+				}
+				end += n
+			}
+		}
+		words = append(words, s[:end])
+		s = s[end:]
+	}
+	return words
+}
+
+func wordClass(r rune) int {
+	switch {
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	case unicode.IsSpace(r):
+		return 2
+	default:
+		return 0
+	}
+}
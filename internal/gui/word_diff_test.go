@@ -0,0 +1,55 @@
+package gui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	got := diffWords("foo_bar(x, 42)  é")
+	want := []string{"foo_bar", "(", "x", ",", " ", "42", ")", "  ", "é"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestWordDiffSpans(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/a.go b/a.go",
+		"--- a/a.go",
+		"+++ b/a.go",
+		"@@ -1,4 +1,4 @@",
+		" context",
+		"-return a + b",
+		"+return a * bb",
+		"-totally different",
+		"+nothing in common",
+		"@@ -10 +10 @@",
+		"-é := 1",
+		"+é := 2",
+	}, "\n")
+	got := wordDiffSpans(diff)
+	want := []wordSpan{
+		{line: 5, start: 10, end: 11, tag: "diffDelWord"},
+		{line: 6, start: 10, end: 11, tag: "diffAddWord"},
+		{line: 5, start: 12, end: 13, tag: "diffDelWord"},
+		{line: 6, start: 12, end: 14, tag: "diffAddWord"},
+		{line: 10, start: 6, end: 7, tag: "diffDelWord"},
+		{line: 11, start: 6, end: 7, tag: "diffAddWord"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestWordDiffSpansIgnoresFileHeaders(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/a b/a",
+		"--- a/old name",
+		"+++ b/new name",
+	}, "\n")
+	if got := wordDiffSpans(diff); len(got) != 0 {
+		t.Fatalf("expected no spans, got %+v", got)
+	}
+}
@@ -0,0 +1,191 @@
+// Package myers computes minimal edit scripts between two sequences using
+// Eugene Myers' O(ND) difference algorithm in linear space.
+package myers
+
+type OpKind uint8
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+// Op is a run of elements sharing the same kind. Equal runs cover
+// a[AStart:AEnd] and b[BStart:BEnd]; Delete runs only cover a and Insert runs
+// only cover b, with the other range empty at the current position.
+type Op struct {
+	Kind   OpKind
+	AStart int
+	AEnd   int
+	BStart int
+	BEnd   int
+}
+
+// Diff returns the edit script turning a into b. Within a change, deletions
+// always come before insertions.
+func Diff[T comparable](a, b []T) []Op {
+	d := differ[T]{
+		a:    a,
+		b:    b,
+		delA: make([]bool, len(a)),
+		insB: make([]bool, len(b)),
+	}
+	d.compare(0, len(a), 0, len(b))
+	return d.ops()
+}
+
+type differ[T comparable] struct {
+	a, b []T
+	delA []bool
+	insB []bool
+}
+
+func (d *differ[T]) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+	switch {
+	case aLo == aHi:
+		for i := bLo; i < bHi; i++ {
+			d.insB[i] = true
+		}
+		return
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			d.delA[i] = true
+		}
+		return
+	}
+	x, y, ok := d.bisect(aLo, aHi, bLo, bHi)
+	if !ok {
+		for i := aLo; i < aHi; i++ {
+			d.delA[i] = true
+		}
+		for i := bLo; i < bHi; i++ {
+			d.insB[i] = true
+		}
+		return
+	}
+	d.compare(aLo, x, bLo, y)
+	d.compare(x, aHi, y, bHi)
+}
+
+// bisect finds the middle snake of the shortest edit path between
+// a[aLo:aHi] and b[bLo:bHi] by searching forward and backward at once, and
+// returns the point where both searches meet.
+func (d *differ[T]) bisect(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n := aHi - aLo
+	m := bHi - bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	vLen := 2*maxD + 2
+	v1 := make([]int, vLen)
+	v2 := make([]int, vLen)
+	for i := range v1 {
+		v1[i] = -1
+		v2[i] = -1
+	}
+	v1[offset+1] = 0
+	v2[offset+1] = 0
+	delta := n - m
+	// With an odd delta the forward path detects the overlap, otherwise the
+	// reverse path does.
+	front := delta%2 != 0
+	var k1start, k1end, k2start, k2end int
+	for step := range maxD {
+		for k1 := -step + k1start; k1 <= step-k1end; k1 += 2 {
+			k1off := offset + k1
+			var x1 int
+			if k1 == -step || (k1 != step && v1[k1off-1] < v1[k1off+1]) {
+				x1 = v1[k1off+1]
+			} else {
+				x1 = v1[k1off-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && d.a[aLo+x1] == d.b[bLo+y1] {
+				x1++
+				y1++
+			}
+			v1[k1off] = x1
+			switch {
+			case x1 > n:
+				k1end += 2
+			case y1 > m:
+				k1start += 2
+			case front:
+				k2off := offset + delta - k1
+				if k2off >= 0 && k2off < vLen && v2[k2off] != -1 {
+					if x1 >= n-v2[k2off] {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+		for k2 := -step + k2start; k2 <= step-k2end; k2 += 2 {
+			k2off := offset + k2
+			var x2 int
+			if k2 == -step || (k2 != step && v2[k2off-1] < v2[k2off+1]) {
+				x2 = v2[k2off+1]
+			} else {
+				x2 = v2[k2off-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && d.a[aHi-x2-1] == d.b[bHi-y2-1] {
+				x2++
+				y2++
+			}
+			v2[k2off] = x2
+			switch {
+			case x2 > n:
+				k2end += 2
+			case y2 > m:
+				k2start += 2
+			case !front:
+				k1off := offset + delta - k2
+				if k1off >= 0 && k1off < vLen && v1[k1off] != -1 {
+					x1 := v1[k1off]
+					y1 := offset + x1 - k1off
+					if x1 >= n-x2 {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func (d *differ[T]) ops() []Op {
+	var ops []Op
+	i, j := 0, 0
+	for i < len(d.a) || j < len(d.b) {
+		op := Op{AStart: i, BStart: j}
+		switch {
+		case i < len(d.a) && d.delA[i]:
+			op.Kind = Delete
+			for i < len(d.a) && d.delA[i] {
+				i++
+			}
+		case j < len(d.b) && d.insB[j]:
+			op.Kind = Insert
+			for j < len(d.b) && d.insB[j] {
+				j++
+			}
+		default:
+			op.Kind = Equal
+			for i < len(d.a) && j < len(d.b) && !d.delA[i] && !d.insB[j] {
+				i++
+				j++
+			}
+		}
+		op.AEnd = i
+		op.BEnd = j
+		ops = append(ops, op)
+	}
+	return ops
+}
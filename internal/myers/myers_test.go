@@ -0,0 +1,115 @@
+package myers
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []Op
+	}{
+		{name: "empty"},
+		{
+			name: "equal",
+			a:    "abc",
+			b:    "abc",
+			want: []Op{{Kind: Equal, AEnd: 3, BEnd: 3}},
+		},
+		{
+			name: "insert all",
+			b:    "ab",
+			want: []Op{{Kind: Insert, BEnd: 2}},
+		},
+		{
+			name: "delete all",
+			a:    "ab",
+			want: []Op{{Kind: Delete, AEnd: 2}},
+		},
+		{
+			name: "replace middle",
+			a:    "abcd",
+			b:    "axd",
+			want: []Op{
+				{Kind: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Kind: Delete, AStart: 1, AEnd: 3, BStart: 1, BEnd: 1},
+				{Kind: Insert, AStart: 3, AEnd: 3, BStart: 1, BEnd: 2},
+				{Kind: Equal, AStart: 3, AEnd: 4, BStart: 2, BEnd: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff([]byte(tt.a), []byte(tt.b))
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("Diff(%q, %q) = %+v, want %+v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffIsMinimal(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 500 {
+		a := randomString(rng)
+		b := randomString(rng)
+		ops := Diff([]byte(a), []byte(b))
+		if got := apply(a, b, ops); got != b {
+			t.Fatalf("applying Diff(%q, %q) produced %q", a, b, got)
+		}
+		edits := 0
+		for _, op := range ops {
+			if op.Kind != Equal {
+				edits += op.AEnd - op.AStart + op.BEnd - op.BStart
+			}
+		}
+		if want := len(a) + len(b) - 2*lcsLen(a, b); edits != want {
+			t.Fatalf("Diff(%q, %q) used %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func randomString(rng *rand.Rand) string {
+	var b strings.Builder
+	for range rng.IntN(20) {
+		b.WriteByte("abc"[rng.IntN(3)])
+	}
+	return b.String()
+}
+
+func apply(a, b string, ops []Op) string {
+	var out strings.Builder
+	for _, op := range ops {
+		switch op.Kind {
+		case Equal:
+			if a[op.AStart:op.AEnd] != b[op.BStart:op.BEnd] {
+				return "<mismatched equal run>"
+			}
+			out.WriteString(a[op.AStart:op.AEnd])
+		case Insert:
+			out.WriteString(b[op.BStart:op.BEnd])
+		}
+	}
+	return out.String()
+}
+
+func lcsLen(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range len(a) {
+		for j := range len(b) {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}